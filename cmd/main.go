@@ -1,107 +1,212 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"net/http"
 	"myapp/internal/api"  // Pastikan path impor sesuai dengan folder proyek kamu
 	"github.com/rs/cors"
 	"github.com/gorilla/mux"
 	"myapp/config"
+	"myapp/internal/auth"
+	"myapp/internal/db"
+	"myapp/internal/repository"
 )
 
-func main() {
-	config.InitS3()
-	// Membuat router
-	r := mux.NewRouter()
-
-	// Menangani route untuk /guru/{id}
-	r.HandleFunc("/guru/{id}", api.GetGuruByIDHandler).Methods("GET")
-	r.HandleFunc("/siswa/{id}", api.GetSiswaByIDHandler).Methods("GET")
-	r.HandleFunc("/kelas/{id}", api.GetKelasByIDHandler).Methods("GET")
-	r.HandleFunc("/matapelajaran/{id}", api.GetMataPelajaranByIDHandler).Methods("GET")
-
-	// Menghubungkan route dengan handler
-	r.HandleFunc("/guru", api.CreateGuruHandler).Methods("POST")
-	r.HandleFunc("/guru", api.GetGuruHandler).Methods("GET")
-	r.HandleFunc("/guru/{id}", api.UpdateGuruHandler).Methods("PUT")
-	r.HandleFunc("/guru/{id}", api.DeleteGuruHandler).Methods("DELETE")
-
-
-	r.HandleFunc("/siswa", api.GetSiswaHandler).Methods("GET")
-	r.HandleFunc("/siswa", api.CreateSiswaHandler).Methods("POST")
-	r.HandleFunc("/siswa/{id}", api.UpdateSiswaHandler).Methods("PUT")
-	r.HandleFunc("/siswa/{id}", api.DeleteSiswaHandler).Methods("DELETE")
-
-	r.HandleFunc("/kelas", api.GetKelasHandler).Methods("GET")
-	r.HandleFunc("/kelas", api.CreateKelasHandler).Methods("POST")
-	r.HandleFunc("/kelas/{id}", api.UpdateKelasHandler).Methods("PUT")
-	r.HandleFunc("/kelas/{id}", api.DeleteKelasHandler).Methods("DELETE")
-
-	r.HandleFunc("/matapelajaran", api.GetMataPelajaranHandler).Methods("GET")
-	r.HandleFunc("/matapelajaran", api.CreateMataPelajaranHandler).Methods("POST")
-	r.HandleFunc("/matapelajaran/{id}", api.UpdateMataPelajaranHandler).Methods("PUT")
-	r.HandleFunc("/matapelajaran/{id}", api.DeleteMataPelajaranHandler).Methods("DELETE")
-
-	r.HandleFunc("/matapelajaran/bykelas/{id}", api.GetMataPelajaranByKelasHandler).Methods("GET")
-
-	r.HandleFunc("/kelas/guru/{id_guru}", api.GetKelasByGuru).Methods("GET")
-
-	r.HandleFunc("/guru/user/{id_user}", api.GetGuruByUserIDHandler).Methods("GET")
-	r.HandleFunc("/siswa/user/{id_user}", api.GetSiswaByUserIDHandler).Methods("GET")
-
-	r.HandleFunc("/matapelajaran/siswa/{id_siswa}", api.GetMataPelajaranBySiswaIDHandler).Methods("GET")
-
-	r.HandleFunc("/kelass/{id_kelas}", api.GetKelasWithSubjects).Methods("GET")
-
-	r.HandleFunc("/siswaa/{id_kelas}", api.GetSiswaByKelas).Methods("GET")
-
-	r.HandleFunc("/mapel/simple-detail/{id_mapel}", api.GetSimpleSubjectDetailHandler).Methods("GET")
-
-	r.HandleFunc("/siswa/by-mapel/{id_mapel}", api.GetStudentsByMapelID).Methods("GET")
-
-	r.HandleFunc("/nilai-detail", api.GetPenilaianBySiswaAndMapelHandler).Methods("GET")
+// route - Satu endpoint beserta role yang boleh mengaksesnya
+type route struct {
+	method  string
+	path    string
+	handler http.HandlerFunc
+	roles   []int
+}
 
-	r.HandleFunc("/penilaian", api.CreatePenilaianHandler).Methods("POST")
-	r.HandleFunc("/penilaian", api.GetPenilaianHandler).Methods("GET")
-	r.HandleFunc("/penilaian/{id}", api.UpdatePenilaianHandler).Methods("PUT")
-	r.HandleFunc("/penilaian/{id}", api.DeletePenilaianHandler).Methods("DELETE")
+// Kelompok role yang sering dipakai di tabel route
+var (
+	adminOnly  = []int{auth.RoleAdmin}
+	adminGuru  = []int{auth.RoleAdmin, auth.RoleGuru}
+	adminSiswa = []int{auth.RoleAdmin, auth.RoleSiswa}
+	allRoles   = []int{auth.RoleAdmin, auth.RoleGuru, auth.RoleSiswa}
+)
 
-	r.HandleFunc("/user", api.CreateUserHandler).Methods("POST")
-	r.HandleFunc("/user", api.GetUserHandler).Methods("GET")
-	r.HandleFunc("/user/{id}", api.UpdateUserHandler).Methods("PUT")
-	r.HandleFunc("/user/{id}", api.DeleteUserHandler).Methods("DELETE")
-	r.HandleFunc("/user/{id}", api.GetUserByIDHandler).Methods("GET")
+// routes - Matriks lengkap route → role. Semua route di sini wajib login.
+func routes(h *api.Handler) []route {
+	return []route{
+		// Menangani route untuk /guru/{id}
+		{"GET", "/guru/{id}", h.GetGuruByIDHandler, adminGuru},
+		{"GET", "/siswa/{id}", h.GetSiswaByIDHandler, allRoles},
+		{"GET", "/kelas/{id}", h.GetKelasByIDHandler, allRoles},
+		{"GET", "/matapelajaran/{id}", h.GetMataPelajaranByIDHandler, allRoles},
+
+		// Menghubungkan route dengan handler
+		{"POST", "/guru", h.CreateGuruHandler, adminOnly},
+		{"GET", "/guru", h.GetGuruHandler, adminGuru},
+		{"PUT", "/guru/{id}", h.UpdateGuruHandler, adminOnly},
+		{"DELETE", "/guru/{id}", h.DeleteGuruHandler, adminOnly},
+
+		{"GET", "/siswa", h.GetSiswaHandler, adminGuru},
+		{"POST", "/siswa", h.CreateSiswaHandler, adminOnly},
+		{"POST", "/siswa/import", h.ImportSiswaHandler, adminOnly},
+		{"PUT", "/siswa/{id}", h.UpdateSiswaHandler, adminOnly},
+		{"DELETE", "/siswa/{id}", h.DeleteSiswaHandler, adminOnly},
+
+		{"GET", "/kelas", h.GetKelasHandler, adminGuru},
+		{"POST", "/kelas", h.CreateKelasHandler, adminOnly},
+		{"PUT", "/kelas/{id}", h.UpdateKelasHandler, adminOnly},
+		{"DELETE", "/kelas/{id}", h.DeleteKelasHandler, adminOnly},
+
+		{"GET", "/matapelajaran", h.GetMataPelajaranHandler, allRoles},
+		{"POST", "/matapelajaran", h.CreateMataPelajaranHandler, adminOnly},
+		{"PUT", "/matapelajaran/{id}", h.UpdateMataPelajaranHandler, adminOnly},
+		{"DELETE", "/matapelajaran/{id}", h.DeleteMataPelajaranHandler, adminOnly},
+
+		{"GET", "/matapelajaran/bykelas/{id}", h.GetMataPelajaranByKelasHandler, allRoles},
+
+		{"GET", "/matapelajaran/{id}/komponen", h.GetKomponenByMapelHandler, allRoles},
+		{"POST", "/matapelajaran/{id}/komponen", h.CreateKomponenHandler, adminGuru},
+		{"PUT", "/komponen/{id}", h.UpdateKomponenHandler, adminGuru},
+		{"DELETE", "/komponen/{id}", h.DeleteKomponenHandler, adminGuru},
+		{"POST", "/matapelajaran/{id}/import-nilai", h.ImportNilaiHandler, adminGuru},
+
+		{"GET", "/kelas/guru/{id_guru}", h.GetKelasByGuru, adminGuru},
+
+		{"GET", "/guru/user/{id_user}", h.GetGuruByUserIDHandler, adminGuru},
+		{"GET", "/siswa/user/{id_user}", h.GetSiswaByUserIDHandler, allRoles},
+
+		{"GET", "/matapelajaran/siswa/{id_siswa}", h.GetMataPelajaranBySiswaIDHandler, allRoles},
+
+		{"GET", "/kelass/{id_kelas}", h.GetKelasWithSubjects, allRoles},
+
+		{"GET", "/siswaa/{id_kelas}", h.GetSiswaByKelas, adminGuru},
+
+		{"GET", "/mapel/simple-detail/{id_mapel}", h.GetSimpleSubjectDetailHandler, allRoles},
+
+		{"GET", "/siswa/by-mapel/{id_mapel}", h.GetStudentsByMapelID, adminGuru},
+
+		{"GET", "/nilai-detail", h.GetPenilaianBySiswaAndMapelHandler, allRoles},
+
+		{"POST", "/penilaian", h.CreatePenilaianHandler, adminGuru},
+		{"POST", "/penilaian/bulk", h.CreatePenilaianBulkHandler, adminGuru},
+		{"GET", "/penilaian/{id}/remedial", h.GetRemedialByPenilaianHandler, adminGuru},
+		{"POST", "/penilaian/{id}/remedial", h.CreateRemedialHandler, adminGuru},
+		{"DELETE", "/remedial/{id}", h.DeleteRemedialHandler, adminGuru},
+		{"GET", "/matapelajaran/{id}/remedial", h.GetSiswaRemedialHandler, adminGuru},
+		{"GET", "/penilaian", h.GetPenilaianHandler, adminGuru},
+		{"PUT", "/penilaian/{id}", h.UpdatePenilaianHandler, adminGuru},
+		{"DELETE", "/penilaian/{id}", h.DeletePenilaianHandler, adminGuru},
+
+		{"POST", "/user", h.CreateUserHandler, adminOnly},
+		{"GET", "/user", h.GetUserHandler, adminOnly},
+		{"PUT", "/user/{id}", h.UpdateUserHandler, adminOnly},
+		{"DELETE", "/user/{id}", h.DeleteUserHandler, adminOnly},
+		{"GET", "/user/{id}", h.GetUserByIDHandler, adminOnly},
+
+		{"GET", "/nilai/user/{id_user}", h.GetNilaiByUserIDHandler, allRoles},
+		{"GET", "/rapor/{id_siswa}", h.GetRaporHandler, allRoles},
+		{"POST", "/kelas/{id}/rapor", h.ExportRaporKelasHandler, adminGuru},
+		{"GET", "/kelas/{id}/leger", h.GetLegerHandler, adminGuru},
+		{"GET", "/kelas/{id}/peringkat", h.GetPeringkatKelasHandler, adminGuru},
+		{"GET", "/jobs/{id}", h.GetJobHandler, adminGuru},
+		{"GET", "/jobs/{id}/download", h.DownloadJobHandler, adminGuru},
+		{"POST", "/nilai/recompute", h.RecomputeNilaiHandler, adminOnly},
+
+		{"PUT", "/siswa/tambah/{id_siswa}", h.UpdateSiswaClassHandler, adminOnly},
+		{"GET", "/siswa/{id}/riwayat-kelas", h.GetRiwayatKelasHandler, allRoles},
+		{"POST", "/kelas/{id}/kenaikan", h.KenaikanKelasHandler, adminOnly},
+		{"PUT", "/siswa/{id}/status", h.UpdateStatusSiswaHandler, adminOnly},
+		{"POST", "/kelas/{id}/kelulusan", h.KelulusanHandler, adminOnly},
+		{"GET", "/alumni", h.GetAlumniHandler, adminGuru},
+
+		{"POST", "/absensi", h.CreateAbsensiBulkHandler, adminGuru},
+		{"DELETE", "/absensi/{id}", h.DeleteAbsensiHandler, adminGuru},
+		{"GET", "/kelas/{id}/absensi", h.GetAbsensiKelasHandler, adminGuru},
+		{"GET", "/kelas/{id}/absensi/rekap", h.GetRekapAbsensiKelasHandler, adminGuru},
+		{"GET", "/siswa/{id}/absensi", h.GetAbsensiSiswaHandler, allRoles},
+
+		{"GET", "/tahun-ajaran", h.GetTahunAjaranHandler, allRoles},
+		{"POST", "/tahun-ajaran", h.CreateTahunAjaranHandler, adminOnly},
+		{"GET", "/semester", h.GetSemesterHandler, allRoles},
+		{"GET", "/semester/aktif", h.GetSemesterAktifHandler, allRoles},
+		{"PUT", "/semester/{id}", h.UpdateSemesterHandler, adminOnly},
+		{"PUT", "/semester/{id}/aktif", h.AktifkanSemesterHandler, adminOnly},
+
+		{"POST", "/upload-foto-guru", h.UploadFotoGuruHandler, adminGuru},
+		{"POST", "/upload-foto-siswa", h.UploadFotoSiswaHandler, adminSiswa},
+	}
+}
 
-	r.HandleFunc("/nilai/user/{id_user}", api.GetNilaiByUserIDHandler).Methods("GET")
+func main() {
+	configPath := flag.String("config", "", "path ke file konfigurasi JSON (opsional, default $CONFIG_FILE)")
+	flag.Parse()
+
+	// "myapp migrate ..." hanya mengelola skema database lalu keluar, cukup konfigurasi database
+	args := flag.Args()
+	load := config.Load
+	if len(args) > 0 {
+		if args[0] != "migrate" {
+			log.Fatalf("unknown command %q", args[0])
+		}
+		load = config.LoadDatabase
+	}
 
-	r.HandleFunc("/siswa/tambah/{id_siswa}", api.UpdateSiswaClassHandler).Methods("PUT")
+	cfg, err := load(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Starting in %s environment", cfg.Env)
+
+	// Satu connection pool untuk seluruh umur server
+	database, err := db.Open(cfg.Database.DSN(), db.PoolConfig{
+		MaxOpenConns:    cfg.Database.MaxOpenConns,
+		MaxIdleConns:    cfg.Database.MaxIdleConns,
+		ConnMaxLifetime: cfg.Database.ConnMaxLifetime.Duration,
+		ConnMaxIdleTime: cfg.Database.ConnMaxIdleTime.Duration,
+	})
+	if err != nil {
+		log.Fatal("Error opening database: ", err)
+	}
+	defer database.Close()
 
+	if len(args) > 0 {
+		if err := runMigrate(database, args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
+	config.InitS3(cfg.S3)
+	auth.InitJWT(cfg.JWT.Secret, cfg.JWT.TTL.Duration)
 
-	
-	r.HandleFunc("/upload-foto-guru", api.UploadFotoGuruHandler).Methods("POST")
-	r.HandleFunc("/upload-foto-siswa", api.UploadFotoSiswaHandler).Methods("POST")
+	h := api.NewHandler(repository.NewPostgres(database), cfg)
 
-	
+	// Membuat router
+	root := mux.NewRouter()
 
-	r.HandleFunc("/login", api.LoginHandler)
+	// Login tidak memerlukan token
+	root.HandleFunc("/login", h.LoginHandler)
 
+	// Semua route lain wajib membawa token di header Authorization
+	r := root.PathPrefix("/").Subrouter()
+	r.Use(auth.Middleware)
 
+	for _, rt := range routes(h) {
+		r.Handle(rt.path, auth.RequireRoles(rt.roles...)(rt.handler)).Methods(rt.method)
+	}
 
 	// Menambahkan CORS middleware
 	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:3000"}, // Ganti dengan URL frontend Anda
+		AllowedOrigins:   cfg.CORSOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"*"},
 		AllowCredentials: true,
 	})
 
 	// Menambahkan middleware CORS
-	handler := c.Handler(r)
+	handler := c.Handler(root)
 
-	// Menjalankan server di port 8080
-	log.Println("Server is running on port 8080...")
-	err := http.ListenAndServe(":8080", handler)
+	// Menjalankan server
+	log.Printf("Server is running on port %d...", cfg.Port)
+	err = http.ListenAndServe(cfg.Addr(), handler)
 	if err != nil {
 		log.Fatal("Error starting server: ", err)
 	}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"myapp/internal/db"
+)
+
+const migrateUsage = `usage: myapp [-config file] migrate <command>
+
+commands:
+  up [n]     terapkan n migrasi berikutnya (default: semua)
+  down [n]   batalkan n migrasi terakhir (default: 1)
+  status     tampilkan migrasi yang sudah dan belum diterapkan`
+
+// runMigrate - Subcommand "migrate": mengelola skema database dari migrasi yang di-embed ke binary
+func runMigrate(database *sql.DB, args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return fmt.Errorf("%s", migrateUsage)
+	}
+
+	n := 0
+	if len(args) == 2 {
+		var err error
+		if n, err = strconv.Atoi(args[1]); err != nil || n < 1 {
+			return fmt.Errorf("jumlah migrasi harus angka positif: %q", args[1])
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	switch args[0] {
+	case "up":
+		done, err := db.MigrateUp(ctx, database, n)
+		for _, m := range done {
+			fmt.Printf("applied  %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Println("skema sudah versi terbaru")
+		}
+		return err
+	case "down":
+		done, err := db.MigrateDown(ctx, database, n)
+		for _, m := range done {
+			fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Println("tidak ada migrasi untuk dibatalkan")
+		}
+		return err
+	case "status":
+		if len(args) != 1 {
+			return fmt.Errorf("%s", migrateUsage)
+		}
+		status, err := db.MigrationsStatus(ctx, database)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range status {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Local().Format(time.RFC3339)
+			}
+			fmt.Fprintf(tw, "%04d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("%s", migrateUsage)
+	}
+}
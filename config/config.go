@@ -0,0 +1,349 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment yang didukung oleh satu binary yang sama
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// Config - Seluruh konfigurasi aplikasi, dibaca sekali saat startup
+type Config struct {
+	Env         string         `json:"env"`
+	Port        int            `json:"port"`
+	CORSOrigins []string       `json:"cors_origins"`
+	Database    DatabaseConfig `json:"database"`
+	S3          S3Config       `json:"s3"`
+	JWT         JWTConfig      `json:"jwt"`
+	Sekolah     SekolahConfig  `json:"sekolah"`
+}
+
+// DatabaseConfig - Koneksi dan ukuran connection pool PostgreSQL
+type DatabaseConfig struct {
+	URL             string   `json:"url"`
+	Host            string   `json:"host"`
+	Port            int      `json:"port"`
+	User            string   `json:"user"`
+	Password        string   `json:"password"`
+	Name            string   `json:"name"`
+	SSLMode         string   `json:"sslmode"`
+	MaxOpenConns    int      `json:"max_open_conns"`
+	MaxIdleConns    int      `json:"max_idle_conns"`
+	ConnMaxLifetime Duration `json:"conn_max_lifetime"`
+	ConnMaxIdleTime Duration `json:"conn_max_idle_time"`
+}
+
+// S3Config - Lokasi penyimpanan foto guru dan siswa
+type S3Config struct {
+	Region string `json:"region"`
+	Bucket string `json:"bucket"`
+}
+
+// JWTConfig - Secret dan masa berlaku token akses
+type JWTConfig struct {
+	Secret string   `json:"secret"`
+	TTL    Duration `json:"ttl"`
+}
+
+// SekolahConfig - Identitas sekolah untuk kop dan blok tanda tangan rapor
+type SekolahConfig struct {
+	Nama          string `json:"nama"`
+	Alamat        string `json:"alamat"`
+	Kota          string `json:"kota"` // tempat di atas tanda tangan, contoh "Jakarta, 18 Oktober 2026"
+	Telepon       string `json:"telepon"`
+	KepalaSekolah string `json:"kepala_sekolah"`
+	NIPKepala     string `json:"nip_kepala_sekolah"`
+}
+
+// Duration - time.Duration yang ditulis sebagai string ("30m", "12h") di file konfigurasi
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"30m\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// DSN - Connection string untuk lib/pq. DATABASE_URL dipakai apa adanya jika diisi.
+func (c DatabaseConfig) DSN() string {
+	if c.URL != "" {
+		return c.URL
+	}
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.User, c.Password),
+		Host:     fmt.Sprintf("%s:%d", c.Host, c.Port),
+		Path:     "/" + c.Name,
+		RawQuery: url.Values{"sslmode": {c.SSLMode}}.Encode(),
+	}
+	return u.String()
+}
+
+// PublicURL - URL publik sebuah object di bucket S3
+func (c S3Config) PublicURL(key string) string {
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", c.Bucket, c.Region, key)
+}
+
+// Addr - Alamat listen untuk http.ListenAndServe
+func (c *Config) Addr() string {
+	return fmt.Sprintf(":%d", c.Port)
+}
+
+// Load - Membaca konfigurasi dengan urutan prioritas: default environment,
+// file JSON (path, atau CONFIG_FILE jika path kosong), lalu environment variable.
+func Load(path string) (*Config, error) {
+	return load(path, (*Config).Validate)
+}
+
+// LoadDatabase - Sama seperti Load tetapi hanya memvalidasi APP_ENV dan pengaturan database,
+// dipakai perintah migrate yang tidak menjalankan server
+func LoadDatabase(path string) (*Config, error) {
+	return load(path, (*Config).ValidateDatabase)
+}
+
+func load(path string, validate func(*Config) error) (*Config, error) {
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = EnvDevelopment
+	}
+	cfg := defaults(env)
+
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		if err := loadFile(cfg, path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+	if err := validate(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func defaults(env string) *Config {
+	cfg := &Config{
+		Env:         env,
+		Port:        8080,
+		CORSOrigins: []string{"http://localhost:3000"},
+		Database: DatabaseConfig{
+			Host:            "localhost",
+			Port:            5432,
+			User:            "postgres",
+			Name:            "lasharan",
+			SSLMode:         "require",
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: Duration{30 * time.Minute},
+			ConnMaxIdleTime: Duration{5 * time.Minute},
+		},
+		S3: S3Config{
+			Region: "ap-southeast-3", // Jakarta
+			Bucket: "lasharan-bucket",
+		},
+		JWT: JWTConfig{
+			TTL: Duration{12 * time.Hour},
+		},
+		Sekolah: SekolahConfig{
+			Nama: "Lasharan",
+		},
+	}
+	if env == EnvDevelopment {
+		cfg.Database.SSLMode = "disable"
+	}
+	return cfg
+}
+
+func loadFile(cfg *Config, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open config file: %w", err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func applyEnv(cfg *Config) error {
+	var errs []error
+	str := func(key string, dst *string) {
+		if v, ok := os.LookupEnv(key); ok {
+			*dst = v
+		}
+	}
+	num := func(key string, dst *int) {
+		if v, ok := os.LookupEnv(key); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				return
+			}
+			*dst = n
+		}
+	}
+	dur := func(key string, dst *Duration) {
+		if v, ok := os.LookupEnv(key); ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				return
+			}
+			dst.Duration = d
+		}
+	}
+
+	str("APP_ENV", &cfg.Env)
+	num("PORT", &cfg.Port)
+	if v, ok := os.LookupEnv("CORS_ALLOWED_ORIGINS"); ok {
+		cfg.CORSOrigins = splitList(v)
+	}
+
+	str("DATABASE_URL", &cfg.Database.URL)
+	str("DB_HOST", &cfg.Database.Host)
+	num("DB_PORT", &cfg.Database.Port)
+	str("DB_USER", &cfg.Database.User)
+	str("DB_PASSWORD", &cfg.Database.Password)
+	str("DB_NAME", &cfg.Database.Name)
+	str("DB_SSLMODE", &cfg.Database.SSLMode)
+	num("DB_MAX_OPEN_CONNS", &cfg.Database.MaxOpenConns)
+	num("DB_MAX_IDLE_CONNS", &cfg.Database.MaxIdleConns)
+	dur("DB_CONN_MAX_LIFETIME", &cfg.Database.ConnMaxLifetime)
+	dur("DB_CONN_MAX_IDLE_TIME", &cfg.Database.ConnMaxIdleTime)
+
+	str("S3_REGION", &cfg.S3.Region)
+	str("S3_BUCKET", &cfg.S3.Bucket)
+
+	str("JWT_SECRET", &cfg.JWT.Secret)
+	dur("JWT_TTL", &cfg.JWT.TTL)
+
+	str("SEKOLAH_NAMA", &cfg.Sekolah.Nama)
+	str("SEKOLAH_ALAMAT", &cfg.Sekolah.Alamat)
+	str("SEKOLAH_KOTA", &cfg.Sekolah.Kota)
+	str("SEKOLAH_TELEPON", &cfg.Sekolah.Telepon)
+	str("SEKOLAH_KEPALA", &cfg.Sekolah.KepalaSekolah)
+	str("SEKOLAH_NIP_KEPALA", &cfg.Sekolah.NIPKepala)
+
+	return errors.Join(errs...)
+}
+
+// Validate - Menolak konfigurasi yang tidak lengkap sebelum server dijalankan
+func (c *Config) Validate() error {
+	return c.validate(true)
+}
+
+// ValidateDatabase - Hanya memeriksa APP_ENV dan pengaturan database
+func (c *Config) ValidateDatabase() error {
+	return c.validate(false)
+}
+
+// validate - server false melewati pemeriksaan yang hanya dibutuhkan server HTTP (port, CORS, S3, sekolah, JWT)
+func (c *Config) validate(server bool) error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	switch c.Env {
+	case EnvDevelopment, EnvStaging, EnvProduction:
+	default:
+		fail("APP_ENV must be one of %s, %s, %s (got %q)", EnvDevelopment, EnvStaging, EnvProduction, c.Env)
+	}
+	if c.Database.URL == "" {
+		if c.Database.Host == "" {
+			fail("DB_HOST is required")
+		}
+		if c.Database.User == "" {
+			fail("DB_USER is required")
+		}
+		if c.Database.Name == "" {
+			fail("DB_NAME is required")
+		}
+		if c.Env != EnvDevelopment && c.Database.SSLMode == "disable" {
+			fail("DB_SSLMODE=disable is only allowed in %s", EnvDevelopment)
+		}
+	}
+	if c.Database.MaxOpenConns <= 0 {
+		fail("DB_MAX_OPEN_CONNS must be positive")
+	}
+	if c.Database.MaxIdleConns < 0 || c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		fail("DB_MAX_IDLE_CONNS must be between 0 and DB_MAX_OPEN_CONNS")
+	}
+
+	if server {
+		if c.Port <= 0 || c.Port > 65535 {
+			fail("PORT must be between 1 and 65535 (got %d)", c.Port)
+		}
+		if len(c.CORSOrigins) == 0 {
+			fail("CORS_ALLOWED_ORIGINS must contain at least one origin")
+		}
+
+		if c.S3.Region == "" {
+			fail("S3_REGION is required")
+		}
+		if c.S3.Bucket == "" {
+			fail("S3_BUCKET is required")
+		}
+
+		if strings.TrimSpace(c.Sekolah.Nama) == "" {
+			fail("SEKOLAH_NAMA is required (dipakai di kop rapor)")
+		}
+
+		if c.JWT.Secret == "" {
+			fail("JWT_SECRET is required")
+		} else if c.Env != EnvDevelopment && len(c.JWT.Secret) < 32 {
+			fail("JWT_SECRET must be at least 32 characters outside %s", EnvDevelopment)
+		}
+		if c.JWT.TTL.Duration <= 0 {
+			fail("JWT_TTL must be positive")
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func splitList(v string) []string {
+	var out []string
+	for _, part := range strings.Split(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,294 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// envKeys - Semua environment variable yang dibaca load
+var envKeys = []string{
+	"APP_ENV", "CONFIG_FILE", "PORT", "CORS_ALLOWED_ORIGINS",
+	"DATABASE_URL", "DB_HOST", "DB_PORT", "DB_USER", "DB_PASSWORD", "DB_NAME", "DB_SSLMODE",
+	"DB_MAX_OPEN_CONNS", "DB_MAX_IDLE_CONNS", "DB_CONN_MAX_LIFETIME", "DB_CONN_MAX_IDLE_TIME",
+	"S3_REGION", "S3_BUCKET", "JWT_SECRET", "JWT_TTL",
+	"SEKOLAH_NAMA", "SEKOLAH_ALAMAT", "SEKOLAH_KOTA", "SEKOLAH_TELEPON", "SEKOLAH_KEPALA", "SEKOLAH_NIP_KEPALA",
+}
+
+// clearEnv - Mengosongkan environment konfigurasi selama test, lalu mengisi env
+func clearEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+	for _, k := range envKeys {
+		t.Setenv(k, "")
+		os.Unsetenv(k)
+	}
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+}
+
+// writeFile - File konfigurasi JSON sementara
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const secret32 = "0123456789abcdef0123456789abcdef"
+
+func TestLoadDefaults(t *testing.T) {
+	clearEnv(t, map[string]string{"JWT_SECRET": "dev"})
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Env != EnvDevelopment || cfg.Port != 8080 || cfg.Database.Host != "localhost" || cfg.Database.Port != 5432 {
+		t.Errorf("default = %+v", cfg)
+	}
+	if cfg.Database.SSLMode != "disable" {
+		t.Errorf("sslmode development = %q, want disable", cfg.Database.SSLMode)
+	}
+	if cfg.JWT.TTL.Duration != 12*time.Hour || cfg.Database.ConnMaxLifetime.Duration != 30*time.Minute {
+		t.Errorf("durasi default = %v, %v", cfg.JWT.TTL, cfg.Database.ConnMaxLifetime)
+	}
+
+	clearEnv(t, map[string]string{"APP_ENV": EnvStaging, "JWT_SECRET": secret32})
+	cfg, err = Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Env != EnvStaging || cfg.Database.SSLMode != "require" {
+		t.Errorf("staging = env %q sslmode %q, want staging require", cfg.Env, cfg.Database.SSLMode)
+	}
+}
+
+// TestLoadPrecedence - default, lalu file, lalu environment variable
+func TestLoadPrecedence(t *testing.T) {
+	file := writeFile(t, `{
+		"port": 9000,
+		"cors_origins": ["https://file.example"],
+		"database": {"name": "dari_file", "user": "file_user", "conn_max_lifetime": "1h"},
+		"jwt": {"secret": "`+secret32+`", "ttl": "2h"},
+		"sekolah": {"nama": "SMA File", "kota": "Bandung"}
+	}`)
+
+	tests := []struct {
+		name  string
+		path  string
+		env   map[string]string
+		check func(t *testing.T, cfg *Config)
+	}{
+		{"file menimpa default", file, nil, func(t *testing.T, cfg *Config) {
+			if cfg.Port != 9000 || cfg.Database.Name != "dari_file" || cfg.Database.User != "file_user" {
+				t.Errorf("cfg = %+v, want nilai dari file", cfg)
+			}
+			if cfg.Database.Host != "localhost" || cfg.Database.MaxOpenConns != 25 {
+				t.Errorf("field yang tidak ada di file = %+v, want default", cfg.Database)
+			}
+			if cfg.Database.ConnMaxLifetime.Duration != time.Hour || cfg.JWT.TTL.Duration != 2*time.Hour {
+				t.Errorf("durasi = %v, %v, want 1h, 2h", cfg.Database.ConnMaxLifetime, cfg.JWT.TTL)
+			}
+			if cfg.Sekolah.Nama != "SMA File" || cfg.Sekolah.Kota != "Bandung" {
+				t.Errorf("sekolah = %+v", cfg.Sekolah)
+			}
+		}},
+		{"env menimpa file", file, map[string]string{
+			"PORT": "9100", "DB_NAME": "dari_env", "JWT_TTL": "30m", "CORS_ALLOWED_ORIGINS": "https://a.example, ,https://b.example",
+		}, func(t *testing.T, cfg *Config) {
+			if cfg.Port != 9100 || cfg.Database.Name != "dari_env" || cfg.JWT.TTL.Duration != 30*time.Minute {
+				t.Errorf("cfg = %+v, want nilai dari env", cfg)
+			}
+			if cfg.Database.User != "file_user" {
+				t.Errorf("DB user = %q, want tetap dari file", cfg.Database.User)
+			}
+			if want := []string{"https://a.example", "https://b.example"}; !slices.Equal(cfg.CORSOrigins, want) {
+				t.Errorf("cors = %v, want %v", cfg.CORSOrigins, want)
+			}
+		}},
+		{"env kosong tetap menimpa", file, map[string]string{"SEKOLAH_KOTA": ""}, func(t *testing.T, cfg *Config) {
+			if cfg.Sekolah.Kota != "" {
+				t.Errorf("kota = %q, want kosong", cfg.Sekolah.Kota)
+			}
+		}},
+		{"CONFIG_FILE jika path kosong", "", map[string]string{"CONFIG_FILE": file}, func(t *testing.T, cfg *Config) {
+			if cfg.Database.Name != "dari_file" {
+				t.Errorf("DB name = %q, want dari_file", cfg.Database.Name)
+			}
+		}},
+		{"APP_ENV menentukan default", "", map[string]string{"APP_ENV": EnvProduction, "JWT_SECRET": secret32}, func(t *testing.T, cfg *Config) {
+			if cfg.Env != EnvProduction || cfg.Database.SSLMode != "require" {
+				t.Errorf("env %q sslmode %q, want production require", cfg.Env, cfg.Database.SSLMode)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t, tt.env)
+			cfg, err := Load(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		wantErr string
+	}{
+		{"field tidak dikenal", `{"jwt": {"secret": "x"}, "portt": 1}`, nil, "unknown field"},
+		{"JSON rusak", `{"port": `, nil, "parse config file"},
+		{"durasi file bukan string", `{"jwt": {"ttl": 60}}`, nil, "duration must be a string"},
+		{"durasi file tidak valid", `{"jwt": {"ttl": "sejam"}}`, nil, "invalid duration"},
+		{"PORT bukan angka", "", map[string]string{"PORT": "delapan"}, "PORT"},
+		{"durasi env tidak valid", "", map[string]string{"JWT_TTL": "sejam", "DB_CONN_MAX_IDLE_TIME": "x"}, "DB_CONN_MAX_IDLE_TIME"},
+		{"file tidak ada", "", map[string]string{"CONFIG_FILE": "/tidak/ada/config.json"}, "open config file"},
+		{"APP_ENV tidak dikenal", "", map[string]string{"APP_ENV": "prod"}, "APP_ENV must be one of"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := map[string]string{"JWT_SECRET": secret32}
+			for k, v := range tt.env {
+				env[k] = v
+			}
+			clearEnv(t, env)
+			path := ""
+			if tt.file != "" {
+				path = writeFile(t, tt.file)
+			}
+			_, err := Load(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateJWTSecret(t *testing.T) {
+	tests := []struct {
+		env     string
+		secret  string
+		wantErr string
+	}{
+		{EnvDevelopment, "dev", ""},
+		{EnvDevelopment, "", "JWT_SECRET is required"},
+		{EnvStaging, secret32[:31], "at least 32 characters"},
+		{EnvStaging, secret32, ""},
+		{EnvProduction, secret32[:31], "at least 32 characters"},
+		{EnvProduction, secret32, ""},
+		{EnvProduction, "", "JWT_SECRET is required"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d karakter", tt.env, len(tt.secret)), func(t *testing.T) {
+			cfg := defaults(tt.env)
+			cfg.JWT.Secret = tt.secret
+			err := cfg.Validate()
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+			// Perintah migrate tidak butuh JWT secret
+			if err := cfg.ValidateDatabase(); err != nil {
+				t.Errorf("ValidateDatabase() error = %v", err)
+			}
+		})
+	}
+}
+
+// TestValidate - Validate memeriksa semuanya, ValidateDatabase hanya APP_ENV dan database
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		change     func(c *Config)
+		wantServer string // potongan error Validate, "" jika valid
+		wantDB     string // potongan error ValidateDatabase, "" jika valid
+	}{
+		{"valid", func(c *Config) {}, "", ""},
+		{"port 0", func(c *Config) { c.Port = 0 }, "PORT must be between", ""},
+		{"port terlalu besar", func(c *Config) { c.Port = 65536 }, "PORT must be between", ""},
+		{"tanpa CORS", func(c *Config) { c.CORSOrigins = nil }, "CORS_ALLOWED_ORIGINS", ""},
+		{"tanpa bucket", func(c *Config) { c.S3.Bucket = "" }, "S3_BUCKET is required", ""},
+		{"tanpa region", func(c *Config) { c.S3.Region = "" }, "S3_REGION is required", ""},
+		{"nama sekolah spasi", func(c *Config) { c.Sekolah.Nama = "  " }, "SEKOLAH_NAMA is required", ""},
+		{"JWT TTL 0", func(c *Config) { c.JWT.TTL = Duration{} }, "JWT_TTL must be positive", ""},
+		{"sslmode disable di production", func(c *Config) { c.Database.SSLMode = "disable" },
+			"DB_SSLMODE=disable", "DB_SSLMODE=disable"},
+		{"DATABASE_URL melewati field koneksi", func(c *Config) {
+			c.Database.URL = "postgres://localhost/lasharan"
+			c.Database.Host, c.Database.User, c.Database.Name, c.Database.SSLMode = "", "", "", "disable"
+		}, "", ""},
+		{"tanpa host", func(c *Config) { c.Database.Host = "" }, "DB_HOST is required", "DB_HOST is required"},
+		{"tanpa user", func(c *Config) { c.Database.User = "" }, "DB_USER is required", "DB_USER is required"},
+		{"tanpa nama database", func(c *Config) { c.Database.Name = "" }, "DB_NAME is required", "DB_NAME is required"},
+		{"max open 0", func(c *Config) { c.Database.MaxOpenConns = 0 }, "DB_MAX_OPEN_CONNS", "DB_MAX_OPEN_CONNS"},
+		{"idle melebihi open", func(c *Config) { c.Database.MaxIdleConns = 26 }, "DB_MAX_IDLE_CONNS", "DB_MAX_IDLE_CONNS"},
+		{"idle negatif", func(c *Config) { c.Database.MaxIdleConns = -1 }, "DB_MAX_IDLE_CONNS", "DB_MAX_IDLE_CONNS"},
+		{"env tidak dikenal", func(c *Config) { c.Env = "test" }, "APP_ENV must be one of", "APP_ENV must be one of"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaults(EnvProduction)
+			cfg.JWT.Secret = secret32
+			tt.change(cfg)
+			for _, v := range []struct {
+				name    string
+				err     error
+				wantErr string
+			}{
+				{"Validate", cfg.Validate(), tt.wantServer},
+				{"ValidateDatabase", cfg.ValidateDatabase(), tt.wantDB},
+			} {
+				if v.wantErr == "" && v.err != nil {
+					t.Errorf("%s() error = %v", v.name, v.err)
+				}
+				if v.wantErr != "" && (v.err == nil || !strings.Contains(v.err.Error(), v.wantErr)) {
+					t.Errorf("%s() error = %v, want %q", v.name, v.err, v.wantErr)
+				}
+			}
+		})
+	}
+}
+
+// TestLoadDatabase - LoadDatabase tidak menolak konfigurasi server yang belum lengkap
+func TestLoadDatabase(t *testing.T) {
+	clearEnv(t, map[string]string{"APP_ENV": EnvProduction, "S3_BUCKET": ""})
+	if _, err := Load(""); err == nil {
+		t.Error("Load() tanpa JWT_SECRET dan S3_BUCKET tidak error")
+	}
+	cfg, err := LoadDatabase("")
+	if err != nil {
+		t.Fatalf("LoadDatabase() error = %v", err)
+	}
+	if cfg.Database.SSLMode != "require" {
+		t.Errorf("sslmode = %q, want require", cfg.Database.SSLMode)
+	}
+
+	clearEnv(t, map[string]string{"APP_ENV": EnvProduction, "DB_SSLMODE": "disable"})
+	if _, err := LoadDatabase(""); err == nil || !strings.Contains(err.Error(), "DB_SSLMODE=disable") {
+		t.Errorf("LoadDatabase() error = %v, want DB_SSLMODE=disable", err)
+	}
+}
+
+func TestDSN(t *testing.T) {
+	c := DatabaseConfig{Host: "db", Port: 5433, User: "guru", Password: "p@ss word", Name: "lasharan", SSLMode: "require"}
+	if got, want := c.DSN(), "postgres://guru:p%40ss%20word@db:5433/lasharan?sslmode=require"; got != want {
+		t.Errorf("DSN() = %q, want %q", got, want)
+	}
+	c.URL = "postgres://lain/db"
+	if got := c.DSN(); got != c.URL {
+		t.Errorf("DSN() = %q, want DATABASE_URL apa adanya", got)
+	}
+}
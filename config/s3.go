@@ -10,9 +10,9 @@ import (
 
 var S3Client *s3.Client
 
-func InitS3() {
+func InitS3(s3cfg S3Config) {
 	cfg, err := config.LoadDefaultConfig(context.TODO(),
-		config.WithRegion("ap-southeast-3"), // Jakarta
+		config.WithRegion(s3cfg.Region),
 	)
 	if err != nil {
 		log.Fatalf("unable to load SDK config, %v", err)
@@ -0,0 +1,401 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"myapp/internal/models"
+	"myapp/internal/repository"
+)
+
+// CreateAbsensiBulkHandler - Mencatat absensi satu kelas pada satu tanggal: harian oleh wali kelas,
+// atau per jam pelajaran jika id_mapel diisi. Semua baris divalidasi dulu lalu disimpan dalam satu
+// transaksi; catatan yang sudah ada pada tanggal tersebut ditimpa.
+func (h *Handler) CreateAbsensiBulkHandler(w http.ResponseWriter, r *http.Request) {
+	var sheet models.AbsensiBulk
+	if err := json.NewDecoder(r.Body).Decode(&sheet); err != nil {
+		http.Error(w, "Gagal membaca data dari body", http.StatusBadRequest)
+		return
+	}
+	if err := sheet.ValidateTanggal(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(sheet.Absensi) == 0 && !sheet.SisanyaHadir {
+		http.Error(w, "Daftar absensi kosong", http.StatusBadRequest)
+		return
+	}
+
+	user := currentUser(r)
+	if !h.authorizeAbsensi(w, r, &sheet.IDKelas, sheet.IDMapel) {
+		return
+	}
+
+	// Step 1: Validasi setiap baris terhadap anggota kelas pada tanggal absensi menurut riwayat kelas,
+	// sehingga absensi tanggal yang sudah lewat tetap bisa diisi untuk siswa yang sesudahnya pindah
+	siswaList, err := h.Repo.Siswa.ListAnggotaKelasTanggal(r.Context(), sheet.IDKelas, sheet.Tanggal)
+	if err != nil {
+		dbError(w, err, "Gagal mengambil data siswa")
+		return
+	}
+	namaSiswa := make(map[int]string, len(siswaList))
+	for _, s := range siswaList {
+		namaSiswa[s.IDSiswa] = s.NamaSiswa
+	}
+
+	result := models.AbsensiBulkResponse{Absensi: []models.Absensi{}, Errors: []models.BarisError{}}
+	rowError := func(i int, row models.AbsensiSiswa, msg string) {
+		result.Errors = append(result.Errors, models.BarisError{Baris: i + 1, IDSiswa: row.IDSiswa, Error: msg})
+	}
+	seen := make(map[int]int, len(sheet.Absensi))
+	for i, row := range sheet.Absensi {
+		sheet.Absensi[i].Status = strings.ToLower(strings.TrimSpace(row.Status))
+		sheet.Absensi[i].Keterangan = strings.TrimSpace(row.Keterangan)
+		prev, dup := seen[row.IDSiswa]
+		if !dup {
+			seen[row.IDSiswa] = i
+		}
+		switch _, diKelas := namaSiswa[row.IDSiswa]; {
+		case !diKelas:
+			rowError(i, row, "Siswa tidak terdaftar di kelas ini pada tanggal tersebut")
+		case dup:
+			rowError(i, row, fmt.Sprintf("Siswa sudah ada di baris %d", prev+1))
+		case !models.ValidStatusAbsensi(sheet.Absensi[i].Status):
+			rowError(i, row, "status harus hadir, sakit, izin atau alpa")
+		}
+	}
+	if len(result.Errors) > 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, result)
+		return
+	}
+	if sheet.SisanyaHadir {
+		for _, s := range siswaList {
+			if _, ok := seen[s.IDSiswa]; !ok {
+				sheet.Absensi = append(sheet.Absensi, models.AbsensiSiswa{IDSiswa: s.IDSiswa, Status: models.AbsensiHadir})
+			}
+		}
+	}
+
+	// Step 2: Simpan urut id_siswa supaya dua absensi bersamaan untuk kelas yang sama tidak deadlock
+	sort.Slice(sheet.Absensi, func(a, b int) bool { return sheet.Absensi[a].IDSiswa < sheet.Absensi[b].IDSiswa })
+	err = h.Repo.InTx(r.Context(), func(repos repository.Repositories) error {
+		for _, row := range sheet.Absensi {
+			a := models.Absensi{
+				IDSiswa:    row.IDSiswa,
+				NamaSiswa:  namaSiswa[row.IDSiswa],
+				IDKelas:    sheet.IDKelas,
+				IDMapel:    sheet.IDMapel,
+				Tanggal:    sheet.Tanggal,
+				Status:     row.Status,
+				Keterangan: row.Keterangan,
+			}
+			id, err := repos.Absensi.Upsert(r.Context(), a, user.IDUser)
+			if err != nil {
+				return fmt.Errorf("simpan absensi siswa %d: %w", row.IDSiswa, err)
+			}
+			a.IDAbsensi = id
+			result.Absensi = append(result.Absensi, a)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Absensi bulk Error: %v\n", err)
+		dbError(w, err, "Gagal menyimpan absensi")
+		return
+	}
+
+	result.Tersimpan = len(result.Absensi)
+	writeJSON(w, http.StatusOK, result)
+}
+
+// DeleteAbsensiHandler - Menghapus satu catatan absensi
+func (h *Handler) DeleteAbsensiHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID absensi tidak valid")
+	if !ok {
+		return
+	}
+
+	absensi, err := h.Repo.Absensi.GetByID(r.Context(), id)
+	if err != nil {
+		repoError(w, err, "Absensi tidak ditemukan", "Gagal mengambil absensi")
+		return
+	}
+	if !h.authorizeAbsensi(w, r, &absensi.IDKelas, absensi.IDMapel) {
+		return
+	}
+
+	if err := h.Repo.Absensi.Delete(r.Context(), id); err != nil {
+		repoError(w, err, "Absensi tidak ditemukan", "Gagal menghapus absensi")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Absensi berhasil dihapus"})
+}
+
+// GetAbsensiKelasHandler - Daftar hadir satu kelas pada ?tanggal= (default hari ini): semua anggota kelas
+// pada tanggal itu menurut riwayat kelas, status kosong untuk siswa yang belum diabsen. ?id_mapel= untuk absensi per jam pelajaran.
+func (h *Handler) GetAbsensiKelasHandler(w http.ResponseWriter, r *http.Request) {
+	idKelas, ok := pathInt(w, r, "id", "ID kelas tidak valid")
+	if !ok {
+		return
+	}
+	idMapel, ok := mapelParam(w, r)
+	if !ok {
+		return
+	}
+	tanggal := time.Now().Format(models.TanggalLayout)
+	if v := r.URL.Query().Get("tanggal"); v != "" {
+		t, err := time.Parse(models.TanggalLayout, v)
+		if err != nil {
+			http.Error(w, "tanggal harus berformat YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		tanggal = t.Format(models.TanggalLayout)
+	}
+
+	if !h.authorizeAbsensi(w, r, &idKelas, idMapel) {
+		return
+	}
+
+	siswaList, err := h.Repo.Siswa.ListAnggotaKelasTanggal(r.Context(), idKelas, tanggal)
+	if err != nil {
+		dbError(w, err, "Gagal mengambil data siswa")
+		return
+	}
+	tercatat, err := h.Repo.Absensi.ListByKelas(r.Context(), idKelas, idMapel, tanggal)
+	if err != nil {
+		dbError(w, err, "Gagal mengambil absensi")
+		return
+	}
+
+	// Siswa yang bukan anggota kelas pada tanggal itu tapi punya catatan tetap ditampilkan
+	perSiswa := make(map[int]models.Absensi, len(tercatat))
+	for _, a := range tercatat {
+		perSiswa[a.IDSiswa] = a
+	}
+	daftar := make([]models.Absensi, 0, len(siswaList))
+	for _, s := range siswaList {
+		a, ok := perSiswa[s.IDSiswa]
+		if !ok {
+			a = models.Absensi{IDSiswa: s.IDSiswa, NamaSiswa: s.NamaSiswa, IDKelas: idKelas, IDMapel: idMapel, Tanggal: tanggal}
+		}
+		delete(perSiswa, s.IDSiswa)
+		daftar = append(daftar, a)
+	}
+	for _, a := range tercatat {
+		if _, ok := perSiswa[a.IDSiswa]; ok {
+			daftar = append(daftar, a)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, daftar)
+}
+
+// GetRekapAbsensiKelasHandler - Rekap absensi per siswa satu kelas dalam rentang ?dari=&sampai=
+// (default rentang semester, lihat rentangAbsensi). Harian, atau satu mapel dengan ?id_mapel=.
+func (h *Handler) GetRekapAbsensiKelasHandler(w http.ResponseWriter, r *http.Request) {
+	idKelas, ok := pathInt(w, r, "id", "ID kelas tidak valid")
+	if !ok {
+		return
+	}
+	idMapel, ok := mapelParam(w, r)
+	if !ok {
+		return
+	}
+	if !h.authorizeAbsensi(w, r, &idKelas, idMapel) {
+		return
+	}
+
+	kelas, err := h.Repo.Kelas.GetByID(r.Context(), idKelas)
+	if err != nil {
+		repoError(w, err, "Kelas tidak ditemukan", "Gagal mengambil data kelas")
+		return
+	}
+	dari, sampai, ok := rentangAbsensi(w, r, func() (models.Semester, bool) {
+		return h.semesterQuery(w, r, kelas.IDTahunAjaran)
+	})
+	if !ok {
+		return
+	}
+
+	siswa, err := h.Repo.Absensi.Rekap(r.Context(), idKelas, idMapel, dari, sampai)
+	if err != nil {
+		dbError(w, err, "Gagal menghitung rekap absensi")
+		return
+	}
+	rekap := models.RekapAbsensiKelas{IDKelas: idKelas, IDMapel: idMapel, Dari: dari, Sampai: sampai, Siswa: siswa}
+	for _, s := range siswa {
+		rekap.Total.Tambah(s.RekapAbsensi)
+	}
+	if rekap.Siswa == nil {
+		rekap.Siswa = []models.RekapAbsensiSiswa{}
+	}
+
+	writeJSON(w, http.StatusOK, rekap)
+}
+
+// GetAbsensiSiswaHandler - Absensi seorang siswa dalam rentang ?dari=&sampai= (default rentang semester
+// aktif/terakhir tahun ajaran kelasnya): rekap harian, rekap per mapel dan semua catatannya
+func (h *Handler) GetAbsensiSiswaHandler(w http.ResponseWriter, r *http.Request) {
+	idSiswa, ok := pathInt(w, r, "id", "ID siswa tidak valid")
+	if !ok {
+		return
+	}
+
+	allowed, err := h.canReadSiswa(r.Context(), currentUser(r), idSiswa)
+	if !authorize(w, allowed, err) {
+		return
+	}
+
+	siswa, err := h.Repo.Siswa.GetByID(r.Context(), idSiswa)
+	if err != nil {
+		repoError(w, err, "Siswa tidak ditemukan", "Gagal mengambil data siswa")
+		return
+	}
+	dari, sampai, ok := rentangAbsensi(w, r, func() (models.Semester, bool) {
+		idSemester, ok := semesterParam(w, r)
+		if !ok {
+			return models.Semester{}, false
+		}
+		semester, err := h.siswaSemester(r.Context(), siswa, idSemester)
+		if err != nil {
+			requestOrRepoError(w, err, "Kelas siswa tidak ditemukan", "Gagal mengambil semester")
+			return semester, false
+		}
+		return semester, true
+	})
+	if !ok {
+		return
+	}
+
+	absensi, err := h.Repo.Absensi.ListBySiswa(r.Context(), idSiswa, dari, sampai)
+	if err != nil {
+		dbError(w, err, "Gagal mengambil absensi")
+		return
+	}
+
+	rekap := models.RekapAbsensiPerSiswa{IDSiswa: idSiswa, Dari: dari, Sampai: sampai,
+		Mapel: []models.RekapAbsensiMapel{}, Absensi: []models.Absensi{}}
+	perMapel := map[int]int{}
+	for _, a := range absensi {
+		var satu models.RekapAbsensi
+		switch a.Status {
+		case models.AbsensiHadir:
+			satu.Hadir = 1
+		case models.AbsensiSakit:
+			satu.Sakit = 1
+		case models.AbsensiIzin:
+			satu.Izin = 1
+		case models.AbsensiAlpa:
+			satu.Alpa = 1
+		}
+		if a.IDMapel == nil {
+			rekap.Harian.Tambah(satu)
+		} else {
+			i, ok := perMapel[*a.IDMapel]
+			if !ok {
+				i = len(rekap.Mapel)
+				perMapel[*a.IDMapel] = i
+				rekap.Mapel = append(rekap.Mapel, models.RekapAbsensiMapel{IDMapel: *a.IDMapel, NamaMapel: a.NamaMapel})
+			}
+			rekap.Mapel[i].Tambah(satu)
+		}
+		rekap.Absensi = append(rekap.Absensi, a)
+	}
+
+	writeJSON(w, http.StatusOK, rekap)
+}
+
+// authorizeAbsensi - Absensi harian dikelola wali kelas, absensi per mapel oleh guru mapel tersebut.
+// id_kelas 0 diisi dari kelas mapel; mapel harus milik kelas tersebut. Menulis respons error dan false jika gagal.
+func (h *Handler) authorizeAbsensi(w http.ResponseWriter, r *http.Request, idKelas *int, idMapel *int) bool {
+	user := currentUser(r)
+	if idMapel == nil {
+		if *idKelas == 0 {
+			http.Error(w, "id_kelas wajib diisi", http.StatusBadRequest)
+			return false
+		}
+		allowed, err := h.canManageKelas(r.Context(), user, *idKelas)
+		if !authorize(w, allowed, err) {
+			return false
+		}
+		if _, err := h.Repo.Kelas.GetByID(r.Context(), *idKelas); err != nil {
+			repoError(w, err, "Kelas tidak ditemukan", "Gagal mengambil data kelas")
+			return false
+		}
+		return true
+	}
+
+	allowed, err := h.canManageMapel(r.Context(), user, *idMapel)
+	if !authorize(w, allowed, err) {
+		return false
+	}
+	mapel, err := h.Repo.MataPelajaran.GetByID(r.Context(), *idMapel)
+	if err != nil {
+		repoError(w, err, "Mata pelajaran tidak ditemukan", "Gagal mengambil mata pelajaran")
+		return false
+	}
+	if *idKelas == 0 {
+		*idKelas = mapel.IDKelas
+	}
+	if mapel.IDKelas != *idKelas {
+		http.Error(w, "Mata pelajaran bukan bagian dari kelas ini", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// rentangAbsensi - ?dari= dan ?sampai= (YYYY-MM-DD). Yang tidak diisi diambil dari tanggal mulai/selesai
+// semester hasil fn. Menulis respons error dan false jika gagal.
+func rentangAbsensi(w http.ResponseWriter, r *http.Request, fn func() (models.Semester, bool)) (string, string, bool) {
+	q := r.URL.Query()
+	dari, sampai := q.Get("dari"), q.Get("sampai")
+	if dari == "" || sampai == "" {
+		semester, ok := fn()
+		if !ok {
+			return "", "", false
+		}
+		if dari == "" {
+			dari = semester.TanggalMulai
+		}
+		if sampai == "" {
+			sampai = semester.TanggalSelesai
+		}
+	}
+	for _, p := range []struct {
+		name  string
+		value *string
+	}{{"dari", &dari}, {"sampai", &sampai}} {
+		t, err := time.Parse(models.TanggalLayout, *p.value)
+		if err != nil {
+			http.Error(w, p.name+" harus berformat YYYY-MM-DD", http.StatusBadRequest)
+			return "", "", false
+		}
+		*p.value = t.Format(models.TanggalLayout)
+	}
+	if sampai < dari {
+		http.Error(w, "sampai harus setelah dari", http.StatusBadRequest)
+		return "", "", false
+	}
+	return dari, sampai, true
+}
+
+// mapelParam - ?id_mapel=, nil jika tidak diisi
+func mapelParam(w http.ResponseWriter, r *http.Request) (*int, bool) {
+	v := r.URL.Query().Get("id_mapel")
+	if v == "" {
+		return nil, true
+	}
+	id, err := strconv.Atoi(v)
+	if err != nil {
+		http.Error(w, "ID mapel tidak valid", http.StatusBadRequest)
+		return nil, false
+	}
+	return &id, true
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"testing"
+
+	"myapp/internal/auth"
+	"myapp/internal/models"
+)
+
+// TestAbsensiAnggotaKelas - Absensi mengikuti kelas siswa pada tanggal absensi, bukan kelas saat ini
+func TestAbsensiAnggotaKelas(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+
+	idUser, err := e.repo.User.Create(ctx, models.User{Username: "citra", Password: "x", IDRole: auth.RoleSiswa})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Citra di kelas A sejak awal semester, pindah ke kelas B mulai 2025-09-01
+	citra := e.addSiswa(t, idUser, e.idKelasA, "Citra", "0011223366")
+	sw, err := e.repo.Siswa.GetByID(ctx, citra)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pindahKelas(ctx, e.repo, sw, e.idKelasB, models.RiwayatPindah, "2025-09-01"); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("absensi bulk", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			idKelas int
+			tanggal string
+			want    int
+		}{
+			{"kelas lama sebelum pindah", e.idKelasA, "2025-08-29", http.StatusOK},
+			{"kelas lama hari pindah", e.idKelasA, "2025-09-01", http.StatusUnprocessableEntity},
+			{"kelas baru hari pindah", e.idKelasB, "2025-09-01", http.StatusOK},
+			{"kelas baru sebelum pindah", e.idKelasB, "2025-08-29", http.StatusUnprocessableEntity},
+			{"sebelum masuk sekolah", e.idKelasA, "2025-07-11", http.StatusUnprocessableEntity},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := e.do(t, e.h.CreateAbsensiBulkHandler, e.admin, http.MethodPost, "/absensi", nil, models.AbsensiBulk{
+					IDKelas: tt.idKelas, Tanggal: tt.tanggal,
+					Absensi: []models.AbsensiSiswa{{IDSiswa: citra, Status: models.AbsensiSakit}},
+				})
+				expectStatus(t, w, tt.want)
+			})
+		}
+	})
+
+	t.Run("daftar hadir", func(t *testing.T) {
+		tests := []struct {
+			tanggal string
+			want    []int
+		}{
+			{"2025-08-29", []int{e.idSiswaA, citra}},
+			{"2025-09-01", []int{e.idSiswaA}},
+			{"2025-07-11", []int{}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.tanggal, func(t *testing.T) {
+				w := e.do(t, e.h.GetAbsensiKelasHandler, e.admin, http.MethodGet, "/kelas/x/absensi?tanggal="+tt.tanggal,
+					map[string]string{"id": strconv.Itoa(e.idKelasA)}, nil)
+				expectStatus(t, w, http.StatusOK)
+				var daftar []models.Absensi
+				if err := json.NewDecoder(w.Body).Decode(&daftar); err != nil {
+					t.Fatal(err)
+				}
+				got := []int{}
+				for _, a := range daftar {
+					got = append(got, a.IDSiswa)
+				}
+				if !slices.Equal(got, tt.want) {
+					t.Errorf("siswa = %v, want %v", got, tt.want)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"myapp/internal/auth"
+	"myapp/internal/repository"
+)
+
+// currentUser - User yang sudah diverifikasi oleh auth.Middleware
+func currentUser(r *http.Request) *auth.Claims {
+	claims, _ := auth.UserFromContext(r.Context())
+	return claims
+}
+
+// canReadSiswa - Siswa hanya boleh membaca datanya sendiri, admin dan guru boleh membaca semua siswa
+func (h *Handler) canReadSiswa(ctx context.Context, user *auth.Claims, idSiswa int) (bool, error) {
+	if user.HasRole(auth.RoleAdmin, auth.RoleGuru) {
+		return true, nil
+	}
+	if !user.HasRole(auth.RoleSiswa) {
+		return false, nil
+	}
+	return h.Repo.Siswa.IsOwnedBy(ctx, idSiswa, user.IDUser)
+}
+
+// canReadUserData - Siswa hanya boleh membaca data milik id_user-nya sendiri
+func canReadUserData(user *auth.Claims, idUser int) bool {
+	if user.HasRole(auth.RoleAdmin, auth.RoleGuru) {
+		return true
+	}
+	return user != nil && user.IDUser == idUser
+}
+
+// canManageMapel - Guru hanya boleh mengelola penilaian mapel di kelas yang dia ajar (kelas.id_guru)
+func (h *Handler) canManageMapel(ctx context.Context, user *auth.Claims, idMapel int) (bool, error) {
+	if user.IsAdmin() {
+		return true, nil
+	}
+	if !user.HasRole(auth.RoleGuru) {
+		return false, nil
+	}
+	return h.Repo.MataPelajaran.IsTaughtBy(ctx, idMapel, user.IDUser)
+}
+
+// canManageKelas - Admin, atau guru yang menjadi wali kelas tersebut (kelas.id_guru)
+func (h *Handler) canManageKelas(ctx context.Context, user *auth.Claims, idKelas int) (bool, error) {
+	if user.IsAdmin() {
+		return true, nil
+	}
+	if !user.HasRole(auth.RoleGuru) {
+		return false, nil
+	}
+	return h.Repo.Kelas.IsTaughtBy(ctx, idKelas, user.IDUser)
+}
+
+// canManagePenilaian - Sama seperti canManageMapel, dicari dari id_penilaian
+func (h *Handler) canManagePenilaian(ctx context.Context, user *auth.Claims, idPenilaian int) (bool, error) {
+	if user.IsAdmin() {
+		return true, nil
+	}
+
+	idMapel, err := h.Repo.Nilai.MapelOfPenilaian(ctx, idPenilaian)
+	if errors.Is(err, repository.ErrNotFound) {
+		// Biarkan handler yang mengembalikan 404
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return h.canManageMapel(ctx, user, idMapel)
+}
+
+// ownsGuru - Guru hanya boleh mengubah baris guru yang id_user-nya miliknya sendiri
+func (h *Handler) ownsGuru(ctx context.Context, user *auth.Claims, idGuru int) (bool, error) {
+	if user.IsAdmin() {
+		return true, nil
+	}
+
+	guru, err := h.Repo.Guru.GetByID(ctx, idGuru)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil && guru.IDUser == user.IDUser, err
+}
+
+// ownsSiswa - Siswa hanya boleh mengubah baris siswa yang id_user-nya miliknya sendiri
+func (h *Handler) ownsSiswa(ctx context.Context, user *auth.Claims, idSiswa int) (bool, error) {
+	if user.IsAdmin() {
+		return true, nil
+	}
+	return h.Repo.Siswa.IsOwnedBy(ctx, idSiswa, user.IDUser)
+}
+
+// authorize - Menulis respons 403/500 sesuai hasil pemeriksaan akses, true jika boleh lanjut
+func authorize(w http.ResponseWriter, allowed bool, err error) bool {
+	if err != nil {
+		log.Println("Authorization check error:", err)
+		dbError(w, err, "Gagal memeriksa hak akses")
+		return false
+	}
+	if !allowed {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"myapp/internal/auth"
+)
+
+func TestAccess(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+
+	idNilai, err := e.repo.Nilai.FindOrCreate(ctx, e.idSiswaA, e.idMapelA, e.idSemester)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idPenilaian, err := e.repo.Nilai.CreatePenilaian(ctx, idNilai, "UTS", 80, 0.3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const tidakAda = 999999
+
+	type check func(user *auth.Claims) (bool, error)
+	tests := []struct {
+		name  string
+		check check
+		user  *auth.Claims
+		want  bool
+	}{
+		{"canReadSiswa admin", func(u *auth.Claims) (bool, error) { return e.h.canReadSiswa(ctx, u, e.idSiswaA) }, e.admin, true},
+		{"canReadSiswa guru kelas lain", func(u *auth.Claims) (bool, error) { return e.h.canReadSiswa(ctx, u, e.idSiswaA) }, e.guruB, true},
+		{"canReadSiswa siswa sendiri", func(u *auth.Claims) (bool, error) { return e.h.canReadSiswa(ctx, u, e.idSiswaA) }, e.siswaA, true},
+		{"canReadSiswa siswa lain", func(u *auth.Claims) (bool, error) { return e.h.canReadSiswa(ctx, u, e.idSiswaA) }, e.siswaB, false},
+		{"canReadSiswa role tidak dikenal", func(u *auth.Claims) (bool, error) { return e.h.canReadSiswa(ctx, u, e.idSiswaA) },
+			&auth.Claims{IDUser: e.siswaA.IDUser, IDRole: 9}, false},
+
+		{"canReadUserData guru", func(u *auth.Claims) (bool, error) { return canReadUserData(u, e.siswaA.IDUser), nil }, e.guruB, true},
+		{"canReadUserData user sendiri", func(u *auth.Claims) (bool, error) { return canReadUserData(u, e.siswaA.IDUser), nil }, e.siswaA, true},
+		{"canReadUserData user lain", func(u *auth.Claims) (bool, error) { return canReadUserData(u, e.siswaA.IDUser), nil }, e.siswaB, false},
+
+		{"canManageMapel admin", func(u *auth.Claims) (bool, error) { return e.h.canManageMapel(ctx, u, e.idMapelA) }, e.admin, true},
+		{"canManageMapel wali kelas", func(u *auth.Claims) (bool, error) { return e.h.canManageMapel(ctx, u, e.idMapelA) }, e.guruA, true},
+		{"canManageMapel guru kelas lain", func(u *auth.Claims) (bool, error) { return e.h.canManageMapel(ctx, u, e.idMapelA) }, e.guruB, false},
+		{"canManageMapel siswa", func(u *auth.Claims) (bool, error) { return e.h.canManageMapel(ctx, u, e.idMapelA) }, e.siswaA, false},
+		{"canManageMapel mapel tidak ada", func(u *auth.Claims) (bool, error) { return e.h.canManageMapel(ctx, u, tidakAda) }, e.guruA, false},
+
+		{"canManageKelas admin", func(u *auth.Claims) (bool, error) { return e.h.canManageKelas(ctx, u, e.idKelasA) }, e.admin, true},
+		{"canManageKelas wali kelas", func(u *auth.Claims) (bool, error) { return e.h.canManageKelas(ctx, u, e.idKelasA) }, e.guruA, true},
+		{"canManageKelas guru kelas lain", func(u *auth.Claims) (bool, error) { return e.h.canManageKelas(ctx, u, e.idKelasA) }, e.guruB, false},
+		{"canManageKelas siswa", func(u *auth.Claims) (bool, error) { return e.h.canManageKelas(ctx, u, e.idKelasA) }, e.siswaA, false},
+
+		{"canManagePenilaian admin", func(u *auth.Claims) (bool, error) { return e.h.canManagePenilaian(ctx, u, idPenilaian) }, e.admin, true},
+		{"canManagePenilaian wali kelas", func(u *auth.Claims) (bool, error) { return e.h.canManagePenilaian(ctx, u, idPenilaian) }, e.guruA, true},
+		{"canManagePenilaian guru kelas lain", func(u *auth.Claims) (bool, error) { return e.h.canManagePenilaian(ctx, u, idPenilaian) }, e.guruB, false},
+		{"canManagePenilaian siswa", func(u *auth.Claims) (bool, error) { return e.h.canManagePenilaian(ctx, u, idPenilaian) }, e.siswaA, false},
+		// Penilaian yang tidak ada diloloskan supaya handler yang menjawab 404
+		{"canManagePenilaian tidak ada", func(u *auth.Claims) (bool, error) { return e.h.canManagePenilaian(ctx, u, tidakAda) }, e.guruB, true},
+
+		{"ownsGuru admin", func(u *auth.Claims) (bool, error) { return e.h.ownsGuru(ctx, u, e.idGuruA) }, e.admin, true},
+		{"ownsGuru diri sendiri", func(u *auth.Claims) (bool, error) { return e.h.ownsGuru(ctx, u, e.idGuruA) }, e.guruA, true},
+		{"ownsGuru guru lain", func(u *auth.Claims) (bool, error) { return e.h.ownsGuru(ctx, u, e.idGuruA) }, e.guruB, false},
+		{"ownsGuru siswa", func(u *auth.Claims) (bool, error) { return e.h.ownsGuru(ctx, u, e.idGuruA) }, e.siswaA, false},
+		{"ownsGuru tidak ada", func(u *auth.Claims) (bool, error) { return e.h.ownsGuru(ctx, u, tidakAda) }, e.guruA, false},
+
+		{"ownsSiswa admin", func(u *auth.Claims) (bool, error) { return e.h.ownsSiswa(ctx, u, e.idSiswaA) }, e.admin, true},
+		{"ownsSiswa diri sendiri", func(u *auth.Claims) (bool, error) { return e.h.ownsSiswa(ctx, u, e.idSiswaA) }, e.siswaA, true},
+		{"ownsSiswa siswa lain", func(u *auth.Claims) (bool, error) { return e.h.ownsSiswa(ctx, u, e.idSiswaA) }, e.siswaB, false},
+		{"ownsSiswa wali kelas", func(u *auth.Claims) (bool, error) { return e.h.ownsSiswa(ctx, u, e.idSiswaA) }, e.guruA, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.check(tt.user)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
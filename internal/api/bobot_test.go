@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"myapp/internal/models"
+)
+
+func TestCheckBobot(t *testing.T) {
+	tests := []struct {
+		name     string
+		ada      []float64 // bobot penilaian yang sudah ada
+		except   int       // indeks penilaian yang sedang diubah, -1 untuk penilaian baru
+		bobot    float64
+		wantSisa float64 // -1 jika muat
+	}{
+		{"nilai kosong", nil, -1, 1, -1},
+		{"pas 100%", []float64{0.3, 0.4}, -1, 0.3, -1},
+		{"tiga pertiga", []float64{0.3333, 0.3333}, -1, 0.3334, -1},
+		{"lebih 0.01%", []float64{0.5}, -1, 0.5001, 0.5},
+		{"sudah penuh", []float64{0.6, 0.4}, -1, 0.0001, 0},
+		{"ubah penilaian sendiri tidak dihitung dua kali", []float64{0.3, 0.7}, 1, 0.7, -1},
+		{"ubah penilaian sendiri melebihi", []float64{0.3, 0.7}, 1, 0.75, 0.7},
+		{"float64 tidak menggeser batas", []float64{0.1, 0.2}, -1, 0.7, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEnv(t)
+			ctx := context.Background()
+			idNilai, err := e.repo.Nilai.FindOrCreate(ctx, e.idSiswaA, e.idMapelA, e.idSemester)
+			if err != nil {
+				t.Fatal(err)
+			}
+			except := 0
+			for i, b := range tt.ada {
+				id, err := e.repo.Nilai.CreatePenilaian(ctx, idNilai, "Penilaian", 80, b, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if i == tt.except {
+					except = id
+				}
+			}
+
+			err = checkBobot(ctx, e.repo, idNilai, except, tt.bobot)
+			var exceeded *bobotExceededError
+			switch {
+			case tt.wantSisa < 0 && err != nil:
+				t.Errorf("checkBobot() = %v, want nil", err)
+			case tt.wantSisa >= 0 && !errors.As(err, &exceeded):
+				t.Errorf("checkBobot() = %v, want bobotExceededError", err)
+			case tt.wantSisa >= 0 && models.BobotPoints(exceeded.sisa) != models.BobotPoints(tt.wantSisa):
+				t.Errorf("sisa = %v, want %v", exceeded.sisa, tt.wantSisa)
+			}
+		})
+	}
+}
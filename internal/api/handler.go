@@ -1,127 +1,135 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
-	"myapp/internal/db"
-	"myapp/internal/models"
-	"github.com/gorilla/mux"
-    "database/sql"
-	"fmt"
+	"path/filepath"
+	"slices"
 	"strconv"
-	"strings"
 	"time"
-	"path/filepath"
-	"io"
-	"myapp/config"
-	"context"
+
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/gorilla/mux"
+
+	"myapp/config"
+	"myapp/internal/auth"
+	"myapp/internal/db"
+	"myapp/internal/jobs"
+	"myapp/internal/models"
+	"myapp/internal/repository"
 )
 
-// GetGuruHandler - Mendapatkan semua data guru
-func GetGuruHandler(w http.ResponseWriter, r *http.Request) {
-	database, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Error connecting to the database", http.StatusInternalServerError)
+// Handler - Menyimpan dependensi bersama (repository, konfigurasi, job latar belakang) untuk semua handler
+type Handler struct {
+	Repo   repository.Repositories
+	Config *config.Config
+	Jobs   *jobs.Store
+}
+
+// Batas job latar belakang: hasil disimpan 1 jam, satu job maksimal 15 menit, 2 job berjalan bersamaan
+const (
+	jobTTL         = time.Hour
+	jobTimeout     = 15 * time.Minute
+	jobMaxParallel = 2
+)
+
+// NewHandler - Membuat Handler dengan repository yang dibuat saat startup
+func NewHandler(repos repository.Repositories, cfg *config.Config) *Handler {
+	return &Handler{Repo: repos, Config: cfg, Jobs: jobs.NewStore(jobTTL, jobTimeout, jobMaxParallel)}
+}
+
+// dbError - Mengembalikan 503 jika database sedang tidak bisa dihubungi, selain itu 500 dengan pesan msg
+func dbError(w http.ResponseWriter, err error, msg string) {
+	if db.IsUnavailable(err) {
+		log.Println("Database unavailable:", err)
+		http.Error(w, "Database sedang tidak tersedia, coba lagi nanti", http.StatusServiceUnavailable)
 		return
 	}
-	defer database.Close()
+	http.Error(w, msg, http.StatusInternalServerError)
+}
 
-	rows, err := database.Query("SELECT id_guru, id_user, id_mapel, nama_guru, mata_pelajaran, nip, alamat, email, no_telp, COALESCE(foto, '') FROM guru")
-	if err != nil {
-		http.Error(w, "Error querying database", http.StatusInternalServerError)
+// repoError - 404 dengan notFoundMsg untuk repository.ErrNotFound, 409 untuk repository.ErrConflict,
+// selain itu sama seperti dbError
+func repoError(w http.ResponseWriter, err error, notFoundMsg, msg string) {
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, notFoundMsg, http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, repository.ErrConflict) {
+		http.Error(w, "Data bentrok dengan data yang sudah ada", http.StatusConflict)
 		return
 	}
-	defer rows.Close()
+	log.Println("Database error:", err)
+	dbError(w, err, msg)
+}
 
-	var gurus []models.Guru
-	for rows.Next() {
-		var guru models.Guru
-		if err := rows.Scan(&guru.IDGuru, &guru.IDUser, &guru.IDMapel, &guru.NamaGuru, &guru.MataPelajaran, &guru.NIP, &guru.Alamat, &guru.Email, &guru.NoTelp, &guru.Foto); err != nil {
-			log.Println("Error scanning row:", err)
-			continue
-		}
-		gurus = append(gurus, guru)
+// pathInt - Membaca parameter URL berupa angka, menulis 400 dengan pesan msg jika tidak valid
+func pathInt(w http.ResponseWriter, r *http.Request, name, msg string) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)[name])
+	if err != nil {
+		http.Error(w, msg, http.StatusBadRequest)
+		return 0, false
 	}
+	return id, true
+}
+
+// writeJSON - Mengirim v sebagai JSON dengan status code tertentu
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
 
-	if err := rows.Err(); err != nil {
-		http.Error(w, "Error processing rows", http.StatusInternalServerError)
+// GetGuruHandler - Mendapatkan semua data guru
+func (h *Handler) GetGuruHandler(w http.ResponseWriter, r *http.Request) {
+	gurus, err := h.Repo.Guru.List(r.Context())
+	if err != nil {
+		dbError(w, err, "Error querying database")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(gurus)
+	writeJSON(w, http.StatusOK, gurus)
 }
 
 // CreateGuruHandler - Menambahkan data guru baru
- func CreateGuruHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreateGuruHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	database, err := db.ConnectToDB()
-	if err != nil {
-		log.Println("DB connect error:", err)
-		http.Error(w, "Database error", http.StatusInternalServerError)
-		return
-	}
-	defer database.Close()
-
 	var guru models.Guru
-	err = json.NewDecoder(r.Body).Decode(&guru)
-	if err != nil {
+	if err := json.NewDecoder(r.Body).Decode(&guru); err != nil {
 		log.Println("JSON decode error:", err)
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	query := `
-		INSERT INTO guru (id_user, id_mapel, nama_guru, mata_pelajaran, nip, alamat, email, no_telp)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id_guru
-	`
-
-	var idGuru int
-	err = database.QueryRow(query,
-		guru.IDUser,
-		guru.IDMapel,
-		guru.NamaGuru,
-		guru.MataPelajaran,
-		guru.NIP,
-		guru.Alamat,
-		guru.Email,
-		guru.NoTelp,
-	).Scan(&idGuru)
-
+	idGuru, err := h.Repo.Guru.Create(r.Context(), guru)
 	if err != nil {
 		log.Println("Insert error:", err)
-		http.Error(w, "Gagal menyimpan guru", http.StatusInternalServerError)
+		dbError(w, err, "Gagal menyimpan guru")
 		return
 	}
 
-	response := map[string]interface{}{
-		"message":  "Guru berhasil ditambahkan",
-		"id_guru":  idGuru,
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Guru berhasil ditambahkan",
+		"id_guru": idGuru,
+	})
 }
 
-
 // UpdateGuruHandler - Mengupdate data guru berdasarkan ID
-func UpdateGuruHandler(w http.ResponseWriter, r *http.Request) {
-	database, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Error connecting to the database", http.StatusInternalServerError)
+func (h *Handler) UpdateGuruHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID guru tidak valid")
+	if !ok {
 		return
 	}
-	defer database.Close()
-
-	id := mux.Vars(r)["id"]
 
 	var guru models.Guru
 	if err := json.NewDecoder(r.Body).Decode(&guru); err != nil {
@@ -129,50 +137,25 @@ func UpdateGuruHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = database.Exec(
-		"UPDATE guru SET id_user=$1, id_mapel=$2, nama_guru=$3, mata_pelajaran=$4, nip=$5, alamat=$6, email=$7, no_telp=$8, foto=$9 WHERE id_guru=$10",
-		guru.IDUser, guru.IDMapel, guru.NamaGuru, guru.MataPelajaran, guru.NIP, guru.Alamat, guru.Email, guru.NoTelp, guru.Foto, id,
-	)
-	if err != nil {
-		http.Error(w, "Error updating data in the database", http.StatusInternalServerError)
+	if err := h.Repo.Guru.Update(r.Context(), id, guru); err != nil {
+		repoError(w, err, "Guru not found", "Error updating data in the database")
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(guru)
+	guru.IDGuru = id
+	writeJSON(w, http.StatusOK, guru)
 }
 
 // DeleteGuruHandler - Menghapus data guru berdasarkan ID
-func DeleteGuruHandler(w http.ResponseWriter, r *http.Request) {
-	database, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Error connecting to the database", http.StatusInternalServerError)
+func (h *Handler) DeleteGuruHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID guru tidak valid")
+	if !ok {
 		return
 	}
-	defer database.Close()
-
-	id := mux.Vars(r)["id"]
 	log.Println("Deleting guru with ID:", id) // Log ID yang akan dihapus
 
-	// Menghapus guru dari database berdasarkan id_guru
-	result, err := database.Exec("DELETE FROM guru WHERE id_guru=$1", id)
-	if err != nil {
-		log.Println("Error deleting from database:", err)
-		http.Error(w, "Error deleting data from the database", http.StatusInternalServerError)
-		return
-	}
-
-	// Mengecek apakah baris data benar-benar terhapus
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		log.Println("Error checking rows affected:", err)
-		http.Error(w, "Error checking affected rows", http.StatusInternalServerError)
-		return
-	}
-
-	if rowsAffected == 0 {
-		log.Println("No guru found with ID:", id)
-		http.Error(w, "Guru not found", http.StatusNotFound)
+	if err := h.Repo.Guru.Delete(r.Context(), id); err != nil {
+		repoError(w, err, "Guru not found", "Error deleting data from the database")
 		return
 	}
 
@@ -182,154 +165,79 @@ func DeleteGuruHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetGuruByIDHandler - Mendapatkan data guru berdasarkan ID
-func GetGuruByIDHandler(w http.ResponseWriter, r *http.Request) {
-	// Mengambil ID dari URL parameter
-	id := mux.Vars(r)["id"]
-
-	// Membuka koneksi ke database
-	database, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Error connecting to the database", http.StatusInternalServerError)
+func (h *Handler) GetGuruByIDHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID guru tidak valid")
+	if !ok {
 		return
 	}
-	defer database.Close()
 
-	// Query untuk mendapatkan data guru berdasarkan ID
-	var guru models.Guru
-	err = database.QueryRow("SELECT id_guru, id_user, id_mapel, nama_guru, mata_pelajaran, nip, alamat, email, no_telp, COALESCE(foto, '') FROM guru WHERE id_guru=$1", id).
-		Scan(&guru.IDGuru, &guru.IDUser, &guru.IDMapel, &guru.NamaGuru, &guru.MataPelajaran, &guru.NIP, &guru.Alamat, &guru.Email, &guru.NoTelp, &guru.Foto)
+	guru, err := h.Repo.Guru.GetByID(r.Context(), id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			http.Error(w, "Guru not found", http.StatusNotFound)
-		} else {
-			http.Error(w, "Error querying database", http.StatusInternalServerError)
-		}
+		repoError(w, err, "Guru not found", "Error querying database")
 		return
 	}
 
-	// Mengirimkan data guru dalam format JSON
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(guru)
+	writeJSON(w, http.StatusOK, guru)
 }
 
-// GetSiswaHandler - Mendapatkan semua data guru
-func GetSiswaHandler(w http.ResponseWriter, r *http.Request) {
-	database, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Error connecting to the database", http.StatusInternalServerError)
+// GetSiswaHandler - Mendapatkan data siswa aktif (?status= untuk status lain, ?status=semua untuk semua)
+func (h *Handler) GetSiswaHandler(w http.ResponseWriter, r *http.Request) {
+	status, ok := statusFilter(w, r)
+	if !ok {
 		return
 	}
-	defer database.Close()
 
-	rows, err := database.Query("SELECT id_siswa, id_user, id_kelas, nama_siswa, alamat, tanggal_lahir, nisn, COALESCE(foto, '') FROM siswa")
+	siswas, err := h.Repo.Siswa.List(r.Context(), status)
 	if err != nil {
-		http.Error(w, "Error querying database", http.StatusInternalServerError)
-		return
-	}
-	defer rows.Close()
-
-	var siswas []models.Siswa
-	for rows.Next() {
-		var siswa models.Siswa
-		if err := rows.Scan(&siswa.IDSiswa, &siswa.IDUser, &siswa.IDKelas, &siswa.NamaSiswa, &siswa.Alamat, &siswa.TanggalLahir, &siswa.NISN, &siswa.Foto); err != nil {
-			log.Println("Error scanning row:", err)
-			continue
-		}
-		siswas = append(siswas, siswa)
-	}
-
-	if err := rows.Err(); err != nil {
-		http.Error(w, "Error processing rows", http.StatusInternalServerError)
+		dbError(w, err, "Error querying database")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(siswas)
+	writeJSON(w, http.StatusOK, siswas)
 }
 
 // CreateSiswaHandler - Menambahkan data siswa baru
-func CreateSiswaHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreateSiswaHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	database, err := db.ConnectToDB()
-	if err != nil {
-		log.Println("DB connect error:", err)
-		http.Error(w, "Database error", http.StatusInternalServerError)
-		return
-	}
-	defer database.Close()
-
 	var siswa models.Siswa
-	err = json.NewDecoder(r.Body).Decode(&siswa)
-	if err != nil {
+	if err := json.NewDecoder(r.Body).Decode(&siswa); err != nil {
 		log.Println("JSON decode error:", err)
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
 	var idSiswa int
-
-	if siswa.IDKelas != nil && *siswa.IDKelas != 0  {
-		// Jika id_kelas ada
-		query := `
-			INSERT INTO siswa (id_user, id_kelas, nama_siswa, alamat, tanggal_lahir, nisn)
-			VALUES ($1, $2, $3, $4, $5, $6)
-			RETURNING id_siswa
-		`
-		err = database.QueryRow(query,
-			siswa.IDUser,
-			siswa.IDKelas,
-			siswa.NamaSiswa,
-			siswa.Alamat,
-			siswa.TanggalLahir,
-			siswa.NISN,
-		).Scan(&idSiswa)
-	} else {
-		// Jika id_kelas tidak diisi
-		query := `
-			INSERT INTO siswa (id_user, nama_siswa, alamat, tanggal_lahir, nisn)
-			VALUES ($1, $2, $3, $4, $5)
-			RETURNING id_siswa
-		`
-		err = database.QueryRow(query,
-			siswa.IDUser,
-			siswa.NamaSiswa,
-			siswa.Alamat,
-			siswa.TanggalLahir,
-			siswa.NISN,
-		).Scan(&idSiswa)
-	}
-
+	err := h.Repo.InTx(r.Context(), func(repos repository.Repositories) error {
+		var err error
+		if idSiswa, err = repos.Siswa.Create(r.Context(), siswa); err != nil {
+			return err
+		}
+		return catatKelas(r.Context(), repos, idSiswa, nil, siswa.IDKelas, models.RiwayatAktif, "")
+	})
 	if err != nil {
 		log.Println("Insert error:", err)
-		http.Error(w, "Gagal menyimpan siswa", http.StatusInternalServerError)
+		repoError(w, err, "Siswa not found", "Gagal menyimpan siswa")
 		return
 	}
 
-	log.Printf("Siswa berhasil ditambahkan: %+v dengan ID: %d\n", siswa, idSiswa)
+	log.Println("Siswa berhasil ditambahkan dengan ID:", idSiswa)
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"message":  "Siswa berhasil ditambahkan",
 		"id_siswa": idSiswa,
 	})
 }
 
-
-
 // UpdateSiswaHandler - Mengupdate data siswa berdasarkan ID
-func UpdateSiswaHandler(w http.ResponseWriter, r *http.Request) {
-	database, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Error connecting to the database", http.StatusInternalServerError)
+func (h *Handler) UpdateSiswaHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID siswa tidak valid")
+	if !ok {
 		return
 	}
-	defer database.Close()
-
-	id := mux.Vars(r)["id"]
 
 	var siswa models.Siswa
 	if err := json.NewDecoder(r.Body).Decode(&siswa); err != nil {
@@ -337,152 +245,119 @@ func UpdateSiswaHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = database.Exec(
-		"UPDATE siswa SET id_user=$1, id_kelas=$2, nama_siswa=$3, alamat=$4, tanggal_lahir=$5, nisn=$6, foto=$7 WHERE id_siswa=$8",
-		siswa.IDUser, siswa.IDKelas, siswa.NamaSiswa, siswa.Alamat, siswa.TanggalLahir, siswa.NISN, siswa.Foto, id,
-	)
+	// Perubahan id_kelas dicatat sebagai perpindahan kelas di riwayat
+	err := h.Repo.InTx(r.Context(), func(repos repository.Repositories) error {
+		existing, err := repos.Siswa.GetByID(r.Context(), id)
+		if err != nil {
+			return err
+		}
+		if existing.Status != models.SiswaAktif && !kelasSama(existing.IDKelas, siswa.IDKelas) {
+			return requestError(errSiswaNonaktif)
+		}
+		if err := repos.Siswa.Update(r.Context(), id, siswa); err != nil {
+			return err
+		}
+		return catatKelas(r.Context(), repos, id, existing.IDKelas, siswa.IDKelas, models.RiwayatPindah, "")
+	})
 	if err != nil {
-		http.Error(w, "Error updating data in the database", http.StatusInternalServerError)
+		requestOrRepoError(w, err, "Siswa not found", "Error updating data in the database")
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(siswa)
+	siswa.IDSiswa = id
+	writeJSON(w, http.StatusOK, siswa)
 }
 
-// DeleteSiswaHandler - Menghapus data siswa berdasarkan ID
-func DeleteSiswaHandler(w http.ResponseWriter, r *http.Request) {
-	database, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Error connecting to the database", http.StatusInternalServerError)
+// DeleteSiswaHandler - Menghapus data siswa yang belum punya riwayat (misalnya salah input).
+// Siswa yang sudah punya nilai, absensi atau riwayat kelas ditolak dengan 409; siswa yang lulus,
+// pindah atau keluar diubah statusnya lewat PUT /siswa/{id}/status supaya datanya tetap ada.
+func (h *Handler) DeleteSiswaHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID siswa tidak valid")
+	if !ok {
 		return
 	}
-	defer database.Close()
-
-	id := mux.Vars(r)["id"]
 	log.Println("Deleting siswa with ID:", id) // Log ID yang akan dihapus
 
-	// Menghapus guru dari database berdasarkan id_guru
-	result, err := database.Exec("DELETE FROM siswa WHERE id_siswa=$1", id)
+	history, err := h.Repo.Siswa.HasHistory(r.Context(), id)
 	if err != nil {
-		log.Println("Error deleting from database:", err)
-		http.Error(w, "Error deleting data from the database", http.StatusInternalServerError)
+		dbError(w, err, "Error deleting data from the database")
 		return
 	}
-
-	// Mengecek apakah baris data benar-benar terhapus
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		log.Println("Error checking rows affected:", err)
-		http.Error(w, "Error checking affected rows", http.StatusInternalServerError)
+	if history {
+		http.Error(w, "Siswa sudah punya nilai, absensi atau riwayat kelas dan tidak bisa dihapus, "+
+			"ubah statusnya lewat PUT /siswa/{id}/status", http.StatusConflict)
 		return
 	}
 
-	if rowsAffected == 0 {
-		log.Println("No siswa found with ID:", id)
-		http.Error(w, "Siswa not found", http.StatusNotFound)
+	if err := h.Repo.Siswa.Delete(r.Context(), id); err != nil {
+		repoError(w, err, "Siswa not found", "Error deleting data from the database")
 		return
 	}
 
 	log.Println("Siswa successfully deleted with ID:", id)
 	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Guru berhasil dihapus"))
+	w.Write([]byte("Siswa berhasil dihapus"))
 }
 
-// GetSiswayIDHandler - Mendapatkan data siswa berdasarkan ID
-func GetSiswaByIDHandler(w http.ResponseWriter, r *http.Request) {
-	// Mengambil ID dari URL parameter
-	id := mux.Vars(r)["id"]
+// GetSiswaByIDHandler - Mendapatkan data siswa berdasarkan ID
+func (h *Handler) GetSiswaByIDHandler(w http.ResponseWriter, r *http.Request) {
+	idSiswa, ok := pathInt(w, r, "id", "ID siswa tidak valid")
+	if !ok {
+		return
+	}
 
-	// Membuka koneksi ke database
-	database, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Error connecting to the database", http.StatusInternalServerError)
+	allowed, err := h.canReadSiswa(r.Context(), currentUser(r), idSiswa)
+	if !authorize(w, allowed, err) {
 		return
 	}
-	defer database.Close()
 
-	// Query untuk mendapatkan data siswa berdasarkan ID
-	var siswa models.Siswa
-	err = database.QueryRow("SELECT id_siswa,id_user, id_kelas, nama_siswa, alamat, tanggal_lahir, nisn, COALESCE(foto, '') FROM siswa WHERE id_siswa=$1", id).
-		Scan(&siswa.IDSiswa, &siswa.IDUser, &siswa.IDKelas, &siswa.NamaSiswa, &siswa.Alamat, &siswa.TanggalLahir, &siswa.NISN, &siswa.Foto)
+	siswa, err := h.Repo.Siswa.GetByID(r.Context(), idSiswa)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			http.Error(w, "Siswa not found", http.StatusNotFound)
-		} else {
-			http.Error(w, "Error querying database", http.StatusInternalServerError)
-		}
+		repoError(w, err, "Siswa not found", "Error querying database")
 		return
 	}
 
-	// Mengirimkan data guru dalam format JSON
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(siswa)
+	writeJSON(w, http.StatusOK, siswa)
 }
 
-// GetKelasHandler - Mendapatkan semua data kelas
-func GetKelasHandler(w http.ResponseWriter, r *http.Request) {
-	database, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Error connecting to the database", http.StatusInternalServerError)
+// GetKelasHandler - Mendapatkan data kelas tahun ajaran aktif (?id_tahun_ajaran= untuk tahun lain,
+// ?id_tahun_ajaran=semua untuk semua kelas)
+func (h *Handler) GetKelasHandler(w http.ResponseWriter, r *http.Request) {
+	idTahunAjaran, ok := h.tahunAjaranFilter(w, r)
+	if !ok {
 		return
 	}
-	defer database.Close()
 
-	rows, err := database.Query("SELECT id_kelas, id_guru, nama_kelas, tahun_ajaran FROM kelas")
+	kelass, err := h.Repo.Kelas.List(r.Context(), idTahunAjaran)
 	if err != nil {
-		http.Error(w, "Error querying database", http.StatusInternalServerError)
-		return
-	}
-	defer rows.Close()
-
-	var kelass []models.Kelas
-	for rows.Next() {
-		var kelas models.Kelas
-		if err := rows.Scan(&kelas.IDKelas, &kelas.IDGuru, &kelas.NamaKelas, &kelas.TahunAjaran); err != nil {
-			log.Println("Error scanning row:", err)
-			continue
-		}
-		kelass = append(kelass, kelas)
-	}
-
-	if err := rows.Err(); err != nil {
-		http.Error(w, "Error processing rows", http.StatusInternalServerError)
+		dbError(w, err, "Error querying database")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(kelass)
+	writeJSON(w, http.StatusOK, kelass)
 }
 
 // CreateKelasHandler - Menambahkan data kelas baru
-func CreateKelasHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreateKelasHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	database, err := db.ConnectToDB()
-	if err != nil {
-		log.Println("DB connect error:", err)
-		http.Error(w, "Database error", http.StatusInternalServerError)
-		return
-	}
-	defer database.Close()
-
 	var kelas models.Kelas
-	err = json.NewDecoder(r.Body).Decode(&kelas)
-	if err != nil {
+	if err := json.NewDecoder(r.Body).Decode(&kelas); err != nil {
 		log.Println("JSON decode error:", err)
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	if err := h.resolveTahunAjaran(r.Context(), &kelas); err != nil {
+		requestOrRepoError(w, err, "Tahun ajaran tidak ditemukan", "Gagal mengambil tahun ajaran")
+		return
+	}
 
-	query := "INSERT INTO kelas (id_guru, nama_kelas, tahun_ajaran) VALUES ($1, $2, $3)"
-	_, err = database.Exec(query, kelas.IDGuru, kelas.NamaKelas, kelas.TahunAjaran)
-	if err != nil {
+	if _, err := h.Repo.Kelas.Create(r.Context(), kelas); err != nil {
 		log.Println("Insert error:", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		dbError(w, err, "Gagal menyimpan kelas")
 		return
 	}
 
@@ -492,66 +367,51 @@ func CreateKelasHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // UpdateKelasHandler - Mengupdate data kelas berdasarkan ID
-func UpdateKelasHandler(w http.ResponseWriter, r *http.Request) {
-	database, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Error connecting to the database", http.StatusInternalServerError)
+func (h *Handler) UpdateKelasHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID kelas tidak valid")
+	if !ok {
 		return
 	}
-	defer database.Close()
 
-	id := mux.Vars(r)["id"]
-
-	var kelas models.Kelas
+	// Field yang tidak dikirim tetap memakai nilai yang tersimpan
+	kelas, err := h.Repo.Kelas.GetByID(r.Context(), id)
+	if err != nil {
+		repoError(w, err, "Kelas not found", "Error querying database")
+		return
+	}
+	idTahunAjaran, tahunAjaran := kelas.IDTahunAjaran, kelas.TahunAjaran
 	if err := json.NewDecoder(r.Body).Decode(&kelas); err != nil {
 		http.Error(w, "Error parsing request body", http.StatusBadRequest)
 		return
 	}
+	// Hanya nama tahun_ajaran yang dikirim: cari id-nya dari nama tersebut
+	if kelas.IDTahunAjaran == idTahunAjaran && kelas.TahunAjaran != tahunAjaran {
+		kelas.IDTahunAjaran = 0
+	}
+	if err := h.resolveTahunAjaran(r.Context(), &kelas); err != nil {
+		requestOrRepoError(w, err, "Tahun ajaran tidak ditemukan", "Gagal mengambil tahun ajaran")
+		return
+	}
 
-	_, err = database.Exec(
-		"UPDATE kelas SET id_guru=$1, nama_kelas=$2, tahun_ajaran=$3 WHERE id_kelas=$4",
-		kelas.IDGuru, kelas.NamaKelas, kelas.TahunAjaran, id,
-	)
-	if err != nil {
-		http.Error(w, "Error updating data in the database", http.StatusInternalServerError)
+	if err := h.Repo.Kelas.Update(r.Context(), id, kelas); err != nil {
+		repoError(w, err, "Kelas not found", "Error updating data in the database")
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(kelas)
+	kelas.IDKelas = id
+	writeJSON(w, http.StatusOK, kelas)
 }
 
 // DeleteKelasHandler - Menghapus data kelas berdasarkan ID
-func DeleteKelasHandler(w http.ResponseWriter, r *http.Request) {
-	database, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Error connecting to the database", http.StatusInternalServerError)
+func (h *Handler) DeleteKelasHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID kelas tidak valid")
+	if !ok {
 		return
 	}
-	defer database.Close()
-
-	id := mux.Vars(r)["id"]
 	log.Println("Deleting kelas with ID:", id) // Log ID yang akan dihapus
 
-	// Menghapus kelas dari database berdasarkan id_guru
-	result, err := database.Exec("DELETE FROM kelas WHERE id_kelas=$1", id)
-	if err != nil {
-		log.Println("Error deleting from database:", err)
-		http.Error(w, "Error deleting data from the database", http.StatusInternalServerError)
-		return
-	}
-
-	// Mengecek apakah baris data benar-benar terhapus
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		log.Println("Error checking rows affected:", err)
-		http.Error(w, "Error checking affected rows", http.StatusInternalServerError)
-		return
-	}
-
-	if rowsAffected == 0 {
-		log.Println("No kelas found with ID:", id)
-		http.Error(w, "Kelas not found", http.StatusNotFound)
+	if err := h.Repo.Kelas.Delete(r.Context(), id); err != nil {
+		repoError(w, err, "Kelas not found", "Error deleting data from the database")
 		return
 	}
 
@@ -561,185 +421,145 @@ func DeleteKelasHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetKelasByIDHandler - Mendapatkan data kelas berdasarkan ID
-func GetKelasByIDHandler(w http.ResponseWriter, r *http.Request) {
-	// Mengambil ID dari URL parameter
-	id := mux.Vars(r)["id"]
-
-	// Membuka koneksi ke database
-	database, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Error connecting to the database", http.StatusInternalServerError)
+func (h *Handler) GetKelasByIDHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID kelas tidak valid")
+	if !ok {
 		return
 	}
-	defer database.Close()
 
-	// Query untuk mendapatkan data guru berdasarkan ID
-	var kelas models.Kelas
-	err = database.QueryRow("SELECT id_kelas, id_guru, nama_kelas, tahun_ajaran FROM kelas WHERE id_kelas=$1", id).
-		Scan(&kelas.IDKelas, &kelas.IDGuru, &kelas.NamaKelas, &kelas.TahunAjaran)
+	kelas, err := h.Repo.Kelas.GetByID(r.Context(), id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			http.Error(w, "Guru not found", http.StatusNotFound)
-		} else {
-			http.Error(w, "Error querying database", http.StatusInternalServerError)
-		}
+		repoError(w, err, "Kelas not found", "Error querying database")
 		return
 	}
 
-	// Mengirimkan data guru dalam format JSON
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(kelas)
+	writeJSON(w, http.StatusOK, kelas)
 }
 
-// GetMataPelajaranHandler - Mendapatkan semua data mata pelajaran
-func GetMataPelajaranHandler(w http.ResponseWriter, r *http.Request) {
-	database, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Error connecting to the database", http.StatusInternalServerError)
+// GetMataPelajaranHandler - Mendapatkan data mata pelajaran kelas-kelas tahun ajaran aktif
+// (?id_tahun_ajaran= untuk tahun lain, ?id_tahun_ajaran=semua untuk semua mapel)
+func (h *Handler) GetMataPelajaranHandler(w http.ResponseWriter, r *http.Request) {
+	idTahunAjaran, ok := h.tahunAjaranFilter(w, r)
+	if !ok {
 		return
 	}
-	defer database.Close()
 
-	rows, err := database.Query("SELECT id_mapel, id_kelas, nama_mata_pelajaran FROM mata_pelajaran")
+	mataPelajaran, err := h.Repo.MataPelajaran.List(r.Context(), idTahunAjaran)
 	if err != nil {
-		http.Error(w, "Error querying database", http.StatusInternalServerError)
-		return
-	}
-	defer rows.Close()
-
-	var mataPelajaran []models.MataPelajaran
-	for rows.Next() {
-		var mp models.MataPelajaran
-		if err := rows.Scan(&mp.IDMapel, &mp.IDKelas, &mp.NamaMataPelajaran); err != nil {
-			log.Println("Error scanning row:", err)
-			continue
-		}
-		mataPelajaran = append(mataPelajaran, mp)
-	}
-
-	if err := rows.Err(); err != nil {
-		http.Error(w, "Error processing rows", http.StatusInternalServerError)
+		dbError(w, err, "Error querying database")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(mataPelajaran)
+	writeJSON(w, http.StatusOK, mataPelajaran)
 }
 
-
 // CreateMataPelajaranHandler - Menambahkan data mata pelajaran baru
-func CreateMataPelajaranHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) CreateMataPelajaranHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	database, err := db.ConnectToDB()
-	if err != nil {
-		log.Println("DB connect error:", err)
-		http.Error(w, "Database error", http.StatusInternalServerError)
-		return
+	// kkm dan skala_predikat boleh tidak diisi, memakai nilai bawaan
+	mataPelajaran := models.MataPelajaran{
+		KKM:           models.DefaultKKM,
+		SkalaPredikat: append(models.SkalaPredikat(nil), models.DefaultSkalaPredikat...),
 	}
-	defer database.Close()
-
-	var mataPelajaran models.MataPelajaran
-	err = json.NewDecoder(r.Body).Decode(&mataPelajaran)
-	if err != nil {
+	if err := json.NewDecoder(r.Body).Decode(&mataPelajaran); err != nil {
 		log.Println("JSON decode error:", err)
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	if !validateMataPelajaran(w, &mataPelajaran) {
+		return
+	}
 
 	// INSERT dan kembalikan ID
-	err = database.QueryRow(
-		"INSERT INTO mata_pelajaran (id_kelas, nama_mata_pelajaran) VALUES ($1, $2) RETURNING id_mapel",
-		mataPelajaran.IDKelas, mataPelajaran.NamaMataPelajaran,
-	).Scan(&mataPelajaran.IDMapel)
+	id, err := h.Repo.MataPelajaran.Create(r.Context(), mataPelajaran)
 	if err != nil {
 		log.Println("Insert error:", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		dbError(w, err, "Gagal menyimpan mata pelajaran")
 		return
 	}
+	mataPelajaran.IDMapel = id
 
 	// response = objek lengkap
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(mataPelajaran)
+	writeJSON(w, http.StatusCreated, mataPelajaran)
 }
 
-
 // UpdateMataPelajaranHandler - Mengupdate data mata pelajaran berdasarkan ID
-func UpdateMataPelajaranHandler(w http.ResponseWriter, r *http.Request) {
-	database, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Error connecting to the database", http.StatusInternalServerError)
+func (h *Handler) UpdateMataPelajaranHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "Invalid ID")
+	if !ok {
 		return
 	}
-	defer database.Close()
-
-	id := mux.Vars(r)["id"]
 
-	var mataPelajaran models.MataPelajaran
+	// Field yang tidak dikirim (misalnya kkm dari klien lama) tetap memakai nilai yang tersimpan
+	mataPelajaran, err := h.Repo.MataPelajaran.GetByID(r.Context(), id)
+	if err != nil {
+		repoError(w, err, "Mata Pelajaran not found", "Error querying database")
+		return
+	}
+	existing := mataPelajaran
 	if err := json.NewDecoder(r.Body).Decode(&mataPelajaran); err != nil {
 		http.Error(w, "Error parsing request body", http.StatusBadRequest)
 		return
 	}
-
-
-	// ✅ Tambahkan baris ini untuk memastikan ID-nya ikut dikembalikan
-	idInt, err := strconv.Atoi(id)
-	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+	mataPelajaran.IDMapel = id
+	if !validateMataPelajaran(w, &mataPelajaran) {
 		return
 	}
-	mataPelajaran.IDMapel = idInt
 
-	_, err = database.Exec(
-		"UPDATE mata_pelajaran SET id_kelas=$1, nama_mata_pelajaran=$2 WHERE id_mapel=$3",
-		 mataPelajaran.IDKelas, mataPelajaran.NamaMataPelajaran, id,
-	)
+	// KKM dan aturan remedial ikut menentukan total_nilai siswa yang punya remedial
+	err = h.Repo.InTx(r.Context(), func(repos repository.Repositories) error {
+		if err := repos.MataPelajaran.Update(r.Context(), id, mataPelajaran); err != nil {
+			return err
+		}
+		if mataPelajaran.KKM != existing.KKM || mataPelajaran.AturanRemedial != existing.AturanRemedial {
+			return repos.Nilai.RecomputeByMapel(r.Context(), id)
+		}
+		return nil
+	})
 	if err != nil {
-		http.Error(w, "Error updating data in the database", http.StatusInternalServerError)
+		repoError(w, err, "Mata Pelajaran not found", "Error updating data in the database")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(mataPelajaran)
-
+	writeJSON(w, http.StatusOK, mataPelajaran)
 }
 
-// DeleteMataPelajaranHandler - Menghapus data mata pelajaran berdasarkan ID
-func DeleteMataPelajaranHandler(w http.ResponseWriter, r *http.Request) {
-	database, err := db.ConnectToDB()
+// validateMataPelajaran - Memeriksa KKM dan aturan remedial serta mengurutkan skala predikat;
+// menulis 400 jika tidak valid
+func validateMataPelajaran(w http.ResponseWriter, mp *models.MataPelajaran) bool {
+	if err := models.ValidateKKM(mp.KKM); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	aturan, err := models.ValidateAturanRemedial(mp.AturanRemedial)
 	if err != nil {
-		http.Error(w, "Error connecting to the database", http.StatusInternalServerError)
-		return
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
 	}
-	defer database.Close()
-
-	id := mux.Vars(r)["id"]
-	log.Println("Deleting mata pelajaran with ID:", id) // Log ID yang akan dihapus
-
-	// Menghapus mata pelajaran dari database berdasarkan id_mapel
-	result, err := database.Exec("DELETE FROM mata_pelajaran WHERE id_mapel=$1", id)
+	mp.AturanRemedial = aturan
+	skala, err := mp.SkalaPredikat.Normalize()
 	if err != nil {
-		log.Println("Error deleting from database:", err)
-		http.Error(w, "Error deleting data from the database", http.StatusInternalServerError)
-		return
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
 	}
+	mp.SkalaPredikat = skala
+	return true
+}
 
-	// Mengecek apakah baris data benar-benar terhapus
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		log.Println("Error checking rows affected:", err)
-		http.Error(w, "Error checking affected rows", http.StatusInternalServerError)
+// DeleteMataPelajaranHandler - Menghapus data mata pelajaran berdasarkan ID
+func (h *Handler) DeleteMataPelajaranHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID mapel tidak valid")
+	if !ok {
 		return
 	}
+	log.Println("Deleting mata pelajaran with ID:", id) // Log ID yang akan dihapus
 
-	if rowsAffected == 0 {
-		log.Println("No mata pelajaran found with ID:", id)
-		http.Error(w, "Mata Pelajaran not found", http.StatusNotFound)
+	if err := h.Repo.MataPelajaran.Delete(r.Context(), id); err != nil {
+		repoError(w, err, "Mata Pelajaran not found", "Error deleting data from the database")
 		return
 	}
 
@@ -749,592 +569,371 @@ func DeleteMataPelajaranHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetMataPelajaranByIDHandler - Mendapatkan data mata pelajaran berdasarkan ID
-func GetMataPelajaranByIDHandler(w http.ResponseWriter, r *http.Request) {
-	// Mengambil ID dari URL parameter
-	id := mux.Vars(r)["id"]
-
-	// Membuka koneksi ke database
-	database, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Error connecting to the database", http.StatusInternalServerError)
+func (h *Handler) GetMataPelajaranByIDHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID mapel tidak valid")
+	if !ok {
 		return
 	}
-	defer database.Close()
 
-	// Query untuk mendapatkan data mata pelajaran berdasarkan ID
-	var mataPelajaran models.MataPelajaran
-	err = database.QueryRow("SELECT id_mapel, id_kelas,nama_mata_pelajaran FROM mata_pelajaran WHERE id_mapel=$1", id).
-		Scan(&mataPelajaran.IDMapel,&mataPelajaran.IDKelas, &mataPelajaran.NamaMataPelajaran)
+	mataPelajaran, err := h.Repo.MataPelajaran.GetByID(r.Context(), id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			http.Error(w, "Mata Pelajaran not found", http.StatusNotFound)
-		} else {
-			http.Error(w, "Error querying database", http.StatusInternalServerError)
-		}
+		repoError(w, err, "Mata Pelajaran not found", "Error querying database")
 		return
 	}
 
-	// Mengirimkan data mata pelajaran dalam format JSON
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(mataPelajaran)
+	writeJSON(w, http.StatusOK, mataPelajaran)
 }
 
-// Handler
-func GetMataPelajaranByKelasHandler(w http.ResponseWriter, r *http.Request) {
-    vars := mux.Vars(r)
-    idKelas := vars["id"]
-
-    dbConn, err := db.ConnectToDB()
-    if err != nil {
-        http.Error(w, "Database connection error", http.StatusInternalServerError)
-        return
-    }
-    defer dbConn.Close()
-
-    rows, err := dbConn.Query("SELECT id_mapel, id_kelas, nama_mata_pelajaran FROM mata_pelajaran WHERE id_kelas = $1", idKelas)
-    if err != nil {
-        http.Error(w, "Query error", http.StatusInternalServerError)
-        return
-    }
-    defer rows.Close()
-
-    var results []models.MataPelajaran
-    for rows.Next() {
-        var mp models.MataPelajaran
-        if err := rows.Scan(&mp.IDMapel, &mp.IDKelas, &mp.NamaMataPelajaran); err != nil {
-            log.Println("Scan error:", err)
-            continue
-        }
-        results = append(results, mp)
-    }
-
-    w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(results)
-}
+// GetMataPelajaranByKelasHandler - Mata pelajaran yang diajarkan di satu kelas
+func (h *Handler) GetMataPelajaranByKelasHandler(w http.ResponseWriter, r *http.Request) {
+	idKelas, ok := pathInt(w, r, "id", "ID kelas tidak valid")
+	if !ok {
+		return
+	}
 
+	results, err := h.Repo.MataPelajaran.ListByKelas(r.Context(), idKelas)
+	if err != nil {
+		dbError(w, err, "Query error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, results)
+}
 
-func LoginHandler(w http.ResponseWriter, r *http.Request) {
+// LoginHandler - Memeriksa username/password dan mengembalikan token akses
+func (h *Handler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	var creds models.User
-	err := json.NewDecoder(r.Body).Decode(&creds)
-	if err != nil {
+	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
 		http.Error(w, "Invalid input", http.StatusBadRequest)
 		return
 	}
 
-	fmt.Println("Login attempt:", creds.Username, creds.Password) // ✅ Log input login
+	log.Println("Login attempt:", creds.Username)
 
-	conn, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Database error", http.StatusInternalServerError)
+	user, err := h.Repo.User.GetByUsername(r.Context(), creds.Username)
+	if errors.Is(err, repository.ErrNotFound) {
+		// Pesan sama dengan password salah supaya username tidak bisa ditebak dari respons
+		auth.BurnPasswordCheck(creds.Password)
+		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
 		return
-	}
-	defer conn.Close()
-
-	var user models.User
-	query := `SELECT id_user, id_role, username, password FROM "user" WHERE username=$1`
-	err = conn.QueryRow(query, creds.Username).Scan(&user.IDUser, &user.IDRole, &user.Username, &user.Password)
-	if err != nil {
-		http.Error(w, "User not found", http.StatusUnauthorized)
+	} else if err != nil {
+		dbError(w, err, "Database error")
 		return
 	}
 
-	if creds.Password != user.Password {
+	ok, needsRehash := auth.VerifyPassword(user.Password, creds.Password)
+	if !ok {
 		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
 		return
 	}
 
-	response := map[string]interface{}{
-		"id_user": user.IDUser,
-		"id_role": user.IDRole,
-		"token":   "dummy-token", // atau JWT jika diperlukan
+	// Migrasi password lama (plaintext / cost rendah) ke hash terbaru
+	if needsRehash {
+		if hash, err := auth.HashPassword(creds.Password); err != nil {
+			log.Println("Rehash error:", err)
+		} else if err := h.Repo.User.UpdatePassword(r.Context(), user.IDUser, hash); err != nil {
+			log.Println("Rehash update error:", err)
+		}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
-}
-
-
-// Handler
-func GetKelasByGuru(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	idGuruStr := vars["id_guru"]
-	log.Println("id_guru dari URL:", idGuruStr)
-
-	idGuru, err := strconv.Atoi(idGuruStr)
+	token, expiresAt, err := auth.GenerateToken(user.IDUser, user.IDRole)
 	if err != nil {
-		http.Error(w, "id_guru harus berupa angka", http.StatusBadRequest)
+		log.Println("Token generation error:", err)
+		http.Error(w, "Gagal membuat token", http.StatusInternalServerError)
 		return
 	}
 
-	dbConn, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Gagal konek database", http.StatusInternalServerError)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id_user":    user.IDUser,
+		"id_role":    user.IDRole,
+		"token":      token,
+		"expires_at": expiresAt.Format(time.RFC3339),
+	})
+}
+
+// GetKelasByGuru - Kelas yang diajar seorang guru beserta jumlah siswanya
+func (h *Handler) GetKelasByGuru(w http.ResponseWriter, r *http.Request) {
+	idGuru, ok := pathInt(w, r, "id_guru", "id_guru harus berupa angka")
+	if !ok {
 		return
 	}
-	defer dbConn.Close()
 
-	query := `
-		SELECT 
-			k.id_kelas, k.id_guru, k.nama_kelas, k.tahun_ajaran,
-			(SELECT COUNT(*) FROM siswa s WHERE s.id_kelas = k.id_kelas) AS jumlah_siswa
-		FROM kelas k
-		WHERE k.id_guru = $1
-	`
+	idTahunAjaran, ok := h.tahunAjaranFilter(w, r)
+	if !ok {
+		return
+	}
 
-	rows, err := dbConn.Query(query, idGuru)
+	kelasList, err := h.Repo.Kelas.ListByGuru(r.Context(), idGuru, idTahunAjaran)
 	if err != nil {
 		log.Println("Query error:", err)
-		http.Error(w, "Query error", http.StatusInternalServerError)
+		dbError(w, err, "Query error")
 		return
 	}
-	defer rows.Close()
-
-	var kelasList []models.Kelas
-	for rows.Next() {
-		var k models.Kelas
-		err := rows.Scan(&k.IDKelas, &k.IDGuru, &k.NamaKelas, &k.TahunAjaran, &k.JumlahSiswa)
-		if err != nil {
-			log.Println("Scan error:", err)
-			continue
-		}
-		kelasList = append(kelasList, k)
-	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(kelasList)
+	writeJSON(w, http.StatusOK, kelasList)
 }
 
+// Handler untuk mendapatkan id_guru berdasarkan id_user
+func (h *Handler) GetGuruByUserIDHandler(w http.ResponseWriter, r *http.Request) {
+	idUser, ok := pathInt(w, r, "id_user", "id_user harus berupa angka")
+	if !ok {
+		return
+	}
 
+	guru, err := h.Repo.Guru.GetByUserID(r.Context(), idUser)
+	if err != nil {
+		repoError(w, err, "Guru tidak ditemukan", "Query error")
+		return
+	}
 
-// Handler untuk mendapatkan id_guru berdasarkan id_user
-func GetGuruByUserIDHandler(w http.ResponseWriter, r *http.Request) {
-    vars := mux.Vars(r)
-    idUserStr := vars["id_user"]
-
-    // Konversi id_user dari string ke integer
-    idUser, err := strconv.Atoi(idUserStr)
-    if err != nil {
-        http.Error(w, "id_user harus berupa angka", http.StatusBadRequest)
-        return
-    }
-
-    dbConn, err := db.ConnectToDB()
-    if err != nil {
-        http.Error(w, "Gagal konek database", http.StatusInternalServerError)
-        return
-    }
-    defer dbConn.Close()
-
-    // Query untuk mengambil id_guru berdasarkan id_user
-    var idGuru int
-    err = dbConn.QueryRow("SELECT id_guru FROM guru WHERE id_user = $1", idUser).Scan(&idGuru)
-    if err != nil {
-        if err == sql.ErrNoRows {
-            http.Error(w, "Guru tidak ditemukan", http.StatusNotFound)
-        } else {
-            http.Error(w, "Query error", http.StatusInternalServerError)
-        }
-        return
-    }
-
-    // Kirim id_guru sebagai response
-    w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(map[string]int{"id_guru": idGuru})
+	// Kirim id_guru sebagai response
+	writeJSON(w, http.StatusOK, map[string]int{"id_guru": guru.IDGuru})
 }
 
 // Handler untuk mendapatkan id_siswa berdasarkan id_user
-func GetSiswaByUserIDHandler(w http.ResponseWriter, r *http.Request) {
-    vars := mux.Vars(r)
-    idUserStr := vars["id_user"]
-
-    // Konversi id_user dari string ke integer
-    idUser, err := strconv.Atoi(idUserStr)
-    if err != nil {
-        http.Error(w, "id_user harus berupa angka", http.StatusBadRequest)
-        return
-    }
-
-    dbConn, err := db.ConnectToDB()
-    if err != nil {
-        http.Error(w, "Gagal konek database", http.StatusInternalServerError)
-        return
-    }
-    defer dbConn.Close()
-
-    // Query untuk mengambil id_siswa berdasarkan id_user
-    var idSiswa int
-    err = dbConn.QueryRow("SELECT id_siswa FROM siswa WHERE id_user = $1", idUser).Scan(&idSiswa)
-    if err != nil {
-        if err == sql.ErrNoRows {
-            http.Error(w, "Siswa tidak ditemukan", http.StatusNotFound)
-        } else {
-            http.Error(w, "Query error", http.StatusInternalServerError)
-        }
-        return
-    }
-
-    // Kirim id_siswa sebagai response
-    w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(map[string]int{"id_siswa": idSiswa})
-}
+func (h *Handler) GetSiswaByUserIDHandler(w http.ResponseWriter, r *http.Request) {
+	idUser, ok := pathInt(w, r, "id_user", "id_user harus berupa angka")
+	if !ok {
+		return
+	}
 
-func GetKelasWithSubjects(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	idKelasStr := vars["id_kelas"]
+	if !authorize(w, canReadUserData(currentUser(r), idUser), nil) {
+		return
+	}
 
-	idKelas, err := strconv.Atoi(idKelasStr)
+	siswa, err := h.Repo.Siswa.GetByUserID(r.Context(), idUser)
 	if err != nil {
-		http.Error(w, "ID kelas tidak valid", http.StatusBadRequest)
+		repoError(w, err, "Siswa tidak ditemukan", "Query error")
 		return
 	}
 
-	dbConn, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Gagal konek database", http.StatusInternalServerError)
+	// Kirim id_siswa sebagai response
+	writeJSON(w, http.StatusOK, map[string]int{"id_siswa": siswa.IDSiswa})
+}
+
+// GetKelasWithSubjects - Data kelas beserta daftar mata pelajaran dan jumlah siswanya
+func (h *Handler) GetKelasWithSubjects(w http.ResponseWriter, r *http.Request) {
+	idKelas, ok := pathInt(w, r, "id_kelas", "ID kelas tidak valid")
+	if !ok {
 		return
 	}
-	defer dbConn.Close()
 
-	// Ambil data kelas
-	var kelas models.Kelas
-	err = dbConn.QueryRow(`
-		SELECT id_kelas, id_guru, nama_kelas, tahun_ajaran
-		FROM kelas
-		WHERE id_kelas = $1
-	`, idKelas).Scan(&kelas.IDKelas, &kelas.IDGuru, &kelas.NamaKelas, &kelas.TahunAjaran)
+	// Ambil data kelas (jumlah_siswa ikut dihitung oleh repository)
+	kelas, err := h.Repo.Kelas.GetByID(r.Context(), idKelas)
 	if err != nil {
-		http.Error(w, "Kelas tidak ditemukan", http.StatusNotFound)
+		repoError(w, err, "Kelas tidak ditemukan", "Gagal mengambil kelas")
 		return
 	}
 
 	// Ambil daftar mata pelajaran dari kelas ini
-	rows, err := dbConn.Query(`
-		SELECT id_mapel, id_kelas, nama_mata_pelajaran
-		FROM mata_pelajaran
-		WHERE id_kelas = $1
-	`, idKelas)
+	mataPelajaranList, err := h.Repo.MataPelajaran.ListByKelas(r.Context(), idKelas)
 	if err != nil {
-		http.Error(w, "Gagal mengambil mata pelajaran", http.StatusInternalServerError)
+		dbError(w, err, "Gagal mengambil mata pelajaran")
 		return
 	}
-	defer rows.Close()
-
-	var mataPelajaranList []models.MataPelajaran
-	for rows.Next() {
-		var mp models.MataPelajaran
-		if err := rows.Scan(&mp.IDMapel, &mp.IDKelas, &mp.NamaMataPelajaran); err == nil {
-			mataPelajaranList = append(mataPelajaranList, mp)
-		}
-	}
-
-	 // Ambil jumlah siswa di kelas ini
-	 var jumlahSiswa int
-	 err = dbConn.QueryRow(`SELECT COUNT(*) FROM siswa WHERE id_kelas = $1`, idKelas).Scan(&jumlahSiswa)
-	 if err != nil {
-		 log.Println("Error menghitung jumlah siswa:", err)
-		 jumlahSiswa = 0 // fallback
-	 }
 
 	// Gabungkan respons
-	response := map[string]interface{}{
-		"id_kelas":         kelas.IDKelas,
-		"id_guru":          kelas.IDGuru,
-		"nama_kelas":       kelas.NamaKelas,
-		"tahun_ajaran":     kelas.TahunAjaran,
-		"mata_pelajaran":   mataPelajaranList,
-		"jumlah_siswa":   jumlahSiswa,
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
-}
-
-
-func GetSiswaByKelas(w http.ResponseWriter, r *http.Request) {
-    vars := mux.Vars(r)
-    idKelasStr := vars["id_kelas"]
-    idKelas, err := strconv.Atoi(idKelasStr)
-    if err != nil {
-        http.Error(w, "ID kelas tidak valid", http.StatusBadRequest)
-        return
-    }
-
-    dbConn, err := db.ConnectToDB()
-    if err != nil {
-        http.Error(w, "Gagal konek database", http.StatusInternalServerError)
-        return
-    }
-    defer dbConn.Close()
-
-    rows, err := dbConn.Query(`
-        SELECT id_siswa, id_kelas, id_user, nama_siswa, alamat, tanggal_lahir, nisn 
-        FROM siswa 
-        WHERE id_kelas = $1`, idKelas)
-    if err != nil {
-        http.Error(w, "Gagal mengambil data siswa", http.StatusInternalServerError)
-        return
-    }
-    defer rows.Close()
-
-    var siswaList []models.Siswa
-    for rows.Next() {
-        var siswa models.Siswa
-        if err := rows.Scan(&siswa.IDSiswa, &siswa.IDKelas, &siswa.IDUser, &siswa.NamaSiswa, &siswa.Alamat, &siswa.TanggalLahir, &siswa.NISN); err != nil {
-            log.Println("Scan error:", err)
-            continue
-        }
-        siswaList = append(siswaList, siswa)
-    }
-
-    w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(siswaList)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id_kelas":       kelas.IDKelas,
+		"id_guru":        kelas.IDGuru,
+		"nama_kelas":     kelas.NamaKelas,
+		"tahun_ajaran":   kelas.TahunAjaran,
+		"mata_pelajaran": mataPelajaranList,
+		"jumlah_siswa":   kelas.JumlahSiswa,
+	})
 }
 
-func GetMataPelajaranBySiswaIDHandler(w http.ResponseWriter, r *http.Request) {
-    vars := mux.Vars(r)
-    idSiswa := vars["id_siswa"]
-
-    dbConn, err := db.ConnectToDB()
-    if err != nil {
-        http.Error(w, "Database connection error", http.StatusInternalServerError)
-        return
-    }
-    defer dbConn.Close()
-
-    query := `
-        SELECT mp.id_mapel, mp.id_kelas, mp.nama_mata_pelajaran
-        FROM siswa s
-        JOIN mata_pelajaran mp ON s.id_kelas = mp.id_kelas
-        WHERE s.id_siswa = $1
-    `
-    rows, err := dbConn.Query(query, idSiswa)
-    if err != nil {
-        http.Error(w, "Query error", http.StatusInternalServerError)
-        return
-    }
-    defer rows.Close()
-
-    var results []models.MataPelajaran
-    for rows.Next() {
-        var mp models.MataPelajaran
-        if err := rows.Scan(&mp.IDMapel, &mp.IDKelas, &mp.NamaMataPelajaran); err != nil {
-            log.Println("Scan error:", err)
-            continue
-        }
-        results = append(results, mp)
-    }
-
-    w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(results)
-}
+// GetSiswaByKelas - Daftar siswa dalam satu kelas
+func (h *Handler) GetSiswaByKelas(w http.ResponseWriter, r *http.Request) {
+	idKelas, ok := pathInt(w, r, "id_kelas", "ID kelas tidak valid")
+	if !ok {
+		return
+	}
 
-func GetSimpleSubjectDetailHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	idMapelStr := vars["id_mapel"]
-	idMapel, err := strconv.Atoi(idMapelStr)
-	if err != nil {
-		http.Error(w, "ID mapel tidak valid", http.StatusBadRequest)
+	status, ok := statusFilter(w, r)
+	if !ok {
 		return
 	}
 
-	dbConn, err := db.ConnectToDB()
+	siswaList, err := h.Repo.Siswa.ListByKelas(r.Context(), idKelas, status)
 	if err != nil {
-		http.Error(w, "Gagal koneksi ke database", http.StatusInternalServerError)
+		dbError(w, err, "Gagal mengambil data siswa")
 		return
 	}
-	defer dbConn.Close()
 
-	query := `
-		SELECT mp.nama_mata_pelajaran, g.nama_guru, k.tahun_ajaran, k.id_kelas
-		FROM mata_pelajaran mp
-		JOIN kelas k ON mp.id_kelas = k.id_kelas
-		JOIN guru g ON k.id_guru = g.id_guru
-		WHERE mp.id_mapel = $1
-	`
-
-	var (
-		namaMapel, namaGuru, tahunAjaran string
-		idKelas                          int
-	)
+	writeJSON(w, http.StatusOK, siswaList)
+}
 
-	err = dbConn.QueryRow(query, idMapel).Scan(&namaMapel, &namaGuru, &tahunAjaran, &idKelas)
-	if err != nil {
-		http.Error(w, "Data tidak ditemukan", http.StatusNotFound)
+// GetMataPelajaranBySiswaIDHandler - Mata pelajaran di kelas seorang siswa
+func (h *Handler) GetMataPelajaranBySiswaIDHandler(w http.ResponseWriter, r *http.Request) {
+	idSiswa, ok := pathInt(w, r, "id_siswa", "ID siswa tidak valid")
+	if !ok {
 		return
 	}
 
-	var jumlahSiswa int
-	err = dbConn.QueryRow(`SELECT COUNT(*) FROM siswa WHERE id_kelas = $1`, idKelas).Scan(&jumlahSiswa)
-	if err != nil {
-		jumlahSiswa = 0
+	allowed, err := h.canReadSiswa(r.Context(), currentUser(r), idSiswa)
+	if !authorize(w, allowed, err) {
+		return
 	}
 
-	// Tambahkan id_mapel ke dalam response
-	response := map[string]interface{}{
-		"id_mapel":            idMapel,
-		"nama_mata_pelajaran": namaMapel,
-		"nama_guru":           namaGuru,
-		"tahun_ajaran":        tahunAjaran,
-		"jumlah_siswa":        jumlahSiswa,
+	results, err := h.Repo.MataPelajaran.ListBySiswa(r.Context(), idSiswa)
+	if err != nil {
+		dbError(w, err, "Query error")
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	writeJSON(w, http.StatusOK, results)
 }
 
-func GetStudentsByMapelID(w http.ResponseWriter, r *http.Request) {
-	// Ambil id_mapel dari query parameter
-	vars := mux.Vars(r)
-	idMapelStr := vars["id_mapel"]
-
-	idMapel, err := strconv.Atoi(idMapelStr)
-	if err != nil {
-		http.Error(w, "ID mapel tidak valid", http.StatusBadRequest)
+// GetSimpleSubjectDetailHandler - Ringkasan mapel: nama, guru, tahun ajaran dan jumlah siswa
+func (h *Handler) GetSimpleSubjectDetailHandler(w http.ResponseWriter, r *http.Request) {
+	idMapel, ok := pathInt(w, r, "id_mapel", "ID mapel tidak valid")
+	if !ok {
 		return
 	}
 
-	// Koneksi ke database
-	dbConn, err := db.ConnectToDB()
+	detail, err := h.Repo.MataPelajaran.GetDetail(r.Context(), idMapel)
 	if err != nil {
-		http.Error(w, "Gagal koneksi ke database", http.StatusInternalServerError)
+		repoError(w, err, "Data tidak ditemukan", "Gagal mengambil data mapel")
 		return
 	}
-	defer dbConn.Close()
 
-	// Ambil id_kelas dari tabel mata_pelajaran
-	var idKelas int
-	err = dbConn.QueryRow(`SELECT id_kelas FROM mata_pelajaran WHERE id_mapel = $1`, idMapel).Scan(&idKelas)
-	if err != nil {
-		http.Error(w, "Mapel tidak ditemukan", http.StatusNotFound)
+	writeJSON(w, http.StatusOK, detail)
+}
+
+// GetStudentsByMapelID - Daftar siswa di kelas tempat mapel diajarkan
+func (h *Handler) GetStudentsByMapelID(w http.ResponseWriter, r *http.Request) {
+	idMapel, ok := pathInt(w, r, "id_mapel", "ID mapel tidak valid")
+	if !ok {
 		return
 	}
 
-	// Ambil data siswa berdasarkan id_kelas
-	rows, err := dbConn.Query(`
-		SELECT id_siswa, id_kelas, id_user, nama_siswa, alamat, tanggal_lahir, nisn, COALESCE(foto, '') 
-		FROM siswa 
-		WHERE id_kelas = $1
-	`, idKelas)
-	if err != nil {
-		http.Error(w, "Gagal mengambil data siswa", http.StatusInternalServerError)
+	if _, err := h.Repo.MataPelajaran.GetByID(r.Context(), idMapel); err != nil {
+		repoError(w, err, "Mapel tidak ditemukan", "Gagal mengambil data mapel")
 		return
 	}
-	defer rows.Close()
 
-	var siswaList []models.Siswa
+	status, ok := statusFilter(w, r)
+	if !ok {
+		return
+	}
 
-	for rows.Next() {
-		var s models.Siswa
-		err := rows.Scan(&s.IDSiswa, &s.IDKelas, &s.IDUser, &s.NamaSiswa, &s.Alamat, &s.TanggalLahir, &s.NISN, &s.Foto)
-		if err != nil {
-			http.Error(w, "Gagal membaca data siswa", http.StatusInternalServerError)
-			return
-		}
-		siswaList = append(siswaList, s)
+	siswaList, err := h.Repo.Siswa.ListByMapel(r.Context(), idMapel, status)
+	if err != nil {
+		dbError(w, err, "Gagal mengambil data siswa")
+		return
 	}
 
-	// Encode hasil ke JSON
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(siswaList)
+	writeJSON(w, http.StatusOK, siswaList)
 }
 
+// GetPenilaianBySiswaAndMapelHandler - Komponen penilaian dan total nilai seorang siswa pada satu mapel
+func (h *Handler) GetPenilaianBySiswaAndMapelHandler(w http.ResponseWriter, r *http.Request) {
+	idSiswaStr := r.URL.Query().Get("id_siswa")
+	idMapelStr := r.URL.Query().Get("id_mapel")
 
-func GetPenilaianBySiswaAndMapelHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	idSiswa := r.URL.Query().Get("id_siswa")
-	idMapel := r.URL.Query().Get("id_mapel")
-
-	if idSiswa == "" || idMapel == "" {
+	if idSiswaStr == "" || idMapelStr == "" {
 		http.Error(w, "Missing id_siswa or id_mapel", http.StatusBadRequest)
 		return
 	}
 
-	dbConn, err := db.ConnectToDB()
+	idSiswa, err := strconv.Atoi(idSiswaStr)
 	if err != nil {
-		http.Error(w, "Gagal koneksi ke database", http.StatusInternalServerError)
+		http.Error(w, "ID siswa tidak valid", http.StatusBadRequest)
+		return
+	}
+	idMapel, err := strconv.Atoi(idMapelStr)
+	if err != nil {
+		http.Error(w, "ID mapel tidak valid", http.StatusBadRequest)
 		return
 	}
-	defer dbConn.Close()
-
-	var idNilai int
-	var totalNilai string
 
-	err = dbConn.QueryRow(`
-		SELECT id_nilai, total_nilai 
-		FROM nilai 
-		WHERE id_siswa = $1 AND id_mapel = $2
-	`, idSiswa, idMapel).Scan(&idNilai, &totalNilai)
+	allowed, err := h.canReadSiswa(r.Context(), currentUser(r), idSiswa)
+	if !authorize(w, allowed, err) {
+		return
+	}
 
+	mapel, err := h.Repo.MataPelajaran.GetByID(r.Context(), idMapel)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			json.NewEncoder(w).Encode(models.PenilaianResponse{
-				PenilaianList: []models.Penilaian{},
-				TotalNilai:    "0",
-			})
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		repoError(w, err, "Mata pelajaran tidak ditemukan", "Gagal mengambil mata pelajaran")
 		return
 	}
-
-	rows, err := dbConn.Query(`
-		SELECT id_penilaian, nama_nilai, nilai, bobot 
-		FROM penilaian 
-		WHERE id_nilai = $1
-	`, idNilai)
+	kelas, err := h.Repo.Kelas.GetByID(r.Context(), mapel.IDKelas)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		repoError(w, err, "Kelas tidak ditemukan", "Gagal mengambil kelas")
+		return
+	}
+	semester, ok := h.semesterQuery(w, r, kelas.IDTahunAjaran)
+	if !ok {
 		return
 	}
-	defer rows.Close()
-
-	var penilaianList []models.Penilaian
-	for rows.Next() {
-		var idPenilaian int
-		var namaNilai string
-		var nilai int
-		var bobot float64
 
-		if err := rows.Scan(&idPenilaian, &namaNilai, &nilai, &bobot); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
+	// Semua komponen mapel ikut ditampilkan, termasuk yang belum dinilai
+	komponen, err := h.Repo.Komponen.ListNilaiSiswa(r.Context(), idMapel, idSiswa, semester.IDSemester)
+	if err != nil {
+		dbError(w, err, "Gagal mengambil komponen penilaian")
+		return
+	}
+	if komponen == nil {
+		komponen = []models.KomponenNilai{}
+	}
 
-		penilaianList = append(penilaianList, models.Penilaian{
-			IDPenilaian: idPenilaian,
-			IDNilai:     idNilai, // ✅ tambahkan IDNilai dari hasil query sebelumnya
-			NamaNilai:   namaNilai,
-			Nilai:       nilai,
-			Bobot:       fmt.Sprintf("%.2f%%", bobot*100),
-			Range:       "0 - 100",
+	nilai, err := h.Repo.Nilai.GetBySiswaAndMapel(r.Context(), idSiswa, idMapel, semester.IDSemester)
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusOK, models.PenilaianResponse{
+			PenilaianList: []models.Penilaian{},
+			TotalNilai:    "0",
+			SisaBobot:     models.FormatBobot(models.BobotMax),
+			Komponen:      komponen,
+			KKM:           mapel.KKM,
 		})
+		return
+	} else if err != nil {
+		dbError(w, err, "Gagal mengambil nilai")
+		return
 	}
 
-	response := models.PenilaianResponse{
-		PenilaianList: penilaianList,
-		TotalNilai:    totalNilai,
+	penilaianList, err := h.Repo.Nilai.ListPenilaianByNilai(r.Context(), nilai.IDNilai)
+	if err != nil {
+		dbError(w, err, "Gagal mengambil penilaian")
+		return
+	}
+	rangeKomponen := make(map[int]string, len(komponen))
+	for _, k := range komponen {
+		rangeKomponen[k.IDKomponen] = k.Range()
+	}
+	for i, p := range penilaianList {
+		penilaianList[i].Range = models.NilaiRange
+		if p.IDKomponen != nil {
+			penilaianList[i].Range = rangeKomponen[*p.IDKomponen]
+		}
 	}
 
-	json.NewEncoder(w).Encode(response)
-}
-
-
-
-func CreatePenilaianHandler(w http.ResponseWriter, r *http.Request) {
-	dbConn, err := db.ConnectToDB()
+	terpakai, err := h.Repo.Nilai.SumBobot(r.Context(), nilai.IDNilai, 0)
 	if err != nil {
-		http.Error(w, "Gagal koneksi ke database", http.StatusInternalServerError)
-		log.Printf("DB Connection Error: %v\n", err)
+		dbError(w, err, "Gagal menghitung bobot")
 		return
 	}
-	defer dbConn.Close()
 
+	predikat, lulus := mapel.Ketuntasan(nilai.TotalNilai)
+	writeJSON(w, http.StatusOK, models.PenilaianResponse{
+		PenilaianList: penilaianList,
+		TotalNilai:    strconv.FormatFloat(nilai.TotalNilai, 'f', -1, 64),
+		SisaBobot:     models.FormatBobot(sisaBobot(terpakai)),
+		Komponen:      komponen,
+		KKM:           mapel.KKM,
+		Predikat:      predikat,
+		Lulus:         lulus,
+	})
+}
+
+// CreatePenilaianHandler - Menambahkan komponen penilaian untuk seorang siswa
+func (h *Handler) CreatePenilaianHandler(w http.ResponseWriter, r *http.Request) {
 	var penilaian models.Penilaian
 	if err := json.NewDecoder(r.Body).Decode(&penilaian); err != nil {
 		http.Error(w, "Gagal membaca data dari body", http.StatusBadRequest)
@@ -1342,79 +941,112 @@ func CreatePenilaianHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Step 1: Cari atau buat id_nilai
-	var idNilai int
-	err = dbConn.QueryRow(`
-		SELECT id_nilai FROM nilai WHERE id_mapel = $1 AND id_siswa = $2
-	`, penilaian.IDMapel, penilaian.IDSiswa).Scan(&idNilai)
+	allowed, err := h.canManageMapel(r.Context(), currentUser(r), penilaian.IDMapel)
+	if !authorize(w, allowed, err) {
+		return
+	}
 
-	if err == sql.ErrNoRows {
-		err = dbConn.QueryRow(`
-			INSERT INTO nilai (id_mapel, id_siswa, total_nilai)
-			VALUES ($1, $2, 0) RETURNING id_nilai
-		`, penilaian.IDMapel, penilaian.IDSiswa).Scan(&idNilai)
+	// Step 1: Siswa harus anggota kelas mapel pada semester penilaian menurut riwayat kelas (bukan kelas
+	// dan status saat ini), sama dengan CreatePenilaianBulkHandler.
+	// id_semester kosong berarti semester aktif (atau terakhir) tahun ajaran kelas mapel ini.
+	if _, err := h.Repo.Siswa.GetByID(r.Context(), penilaian.IDSiswa); err != nil {
+		repoError(w, err, "Siswa tidak ditemukan", "Gagal mengambil data siswa")
+		return
+	}
+	var idSemester *int
+	if penilaian.IDSemester != 0 {
+		idSemester = &penilaian.IDSemester
+	}
+	semester, err := h.mapelSemester(r.Context(), penilaian.IDMapel, idSemester)
+	if err != nil {
+		requestOrRepoError(w, err, "Mata pelajaran tidak ditemukan", "Gagal mengambil semester")
+		return
+	}
+	anggota, err := h.anggotaMapel(r.Context(), penilaian.IDMapel, semester.IDSemester)
+	if err != nil {
+		repoError(w, err, "Mata pelajaran tidak ditemukan", "Gagal mengambil data siswa")
+		return
+	}
+	if !slices.ContainsFunc(anggota, func(s models.Siswa) bool { return s.IDSiswa == penilaian.IDSiswa }) {
+		http.Error(w, "Siswa tidak terdaftar di kelas mata pelajaran ini pada semester tersebut", http.StatusBadRequest)
+		return
+	}
 
+	// Step 2: Nilai untuk komponen mapel memakai nama, bobot dan nilai_maks dari komponennya
+	nilaiMaks := models.NilaiMax
+	if penilaian.IDKomponen != nil {
+		komponen, err := h.Repo.Komponen.GetByID(r.Context(), *penilaian.IDKomponen)
 		if err != nil {
-			http.Error(w, "Gagal membuat entri nilai", http.StatusInternalServerError)
-			log.Printf("Insert nilai Error: %v\n", err)
+			repoError(w, err, "Komponen tidak ditemukan", "Gagal mengambil komponen penilaian")
 			return
 		}
-	} else if err != nil {
-		http.Error(w, "Gagal mengambil id_nilai", http.StatusInternalServerError)
-		log.Printf("QueryRow id_nilai Error: %v\n", err)
-		return
+		if komponen.IDMapel != penilaian.IDMapel {
+			http.Error(w, "Komponen bukan milik mata pelajaran ini", http.StatusBadRequest)
+			return
+		}
+		penilaian.NamaNilai = komponen.NamaKomponen
+		penilaian.Bobot = komponen.Bobot
+		nilaiMaks = komponen.NilaiMaks
 	}
 
-	// Step 2: Konversi bobot dari string ke float
-	bobotFloat, err := strconv.ParseFloat(strings.TrimSuffix(penilaian.Bobot, "%"), 64)
+	// Konversi bobot dari string ke desimal (contoh: 30% jadi 0.3)
+	bobotFloat, err := models.ParseBobot(penilaian.Bobot)
 	if err != nil {
 		http.Error(w, "Format bobot tidak valid", http.StatusBadRequest)
 		log.Printf("Bobot Parse Error: %v\n", err)
 		return
 	}
-	bobotFloat = bobotFloat / 100 // ubah ke desimal (contoh: 30% jadi 0.3)
-
-	// Step 3: Tambah ke tabel penilaian
-	var idPenilaian int
-	err = dbConn.QueryRow(`
-		INSERT INTO penilaian (id_nilai, nama_nilai, nilai, bobot)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id_penilaian
-	`, idNilai, penilaian.NamaNilai, penilaian.Nilai, bobotFloat).Scan(&idPenilaian)
+	if !validatePenilaian(w, penilaian.Nilai, nilaiMaks, bobotFloat) {
+		return
+	}
 
+	// Step 3: Upsert id_nilai, tambah penilaian dan hitung ulang total_nilai dalam satu transaksi.
+	// Gagal di tengah jalan berarti tidak ada yang tersimpan, termasuk baris nilai baru.
+	var idNilai, idPenilaian int
+	err = h.Repo.InTx(r.Context(), func(repos repository.Repositories) error {
+		var err error
+		if idNilai, err = repos.Nilai.FindOrCreate(r.Context(), penilaian.IDSiswa, penilaian.IDMapel, semester.IDSemester); err != nil {
+			return fmt.Errorf("find or create nilai: %w", err)
+		}
+		if err := checkBobot(r.Context(), repos, idNilai, 0, bobotFloat); err != nil {
+			return err
+		}
+		if idPenilaian, err = repos.Nilai.CreatePenilaian(r.Context(), idNilai, penilaian.NamaNilai, penilaian.Nilai, bobotFloat, penilaian.IDKomponen); err != nil {
+			return fmt.Errorf("insert penilaian: %w", err)
+		}
+		return repos.Nilai.RecomputeTotal(r.Context(), idNilai)
+	})
 	if err != nil {
-		http.Error(w, "Gagal menyimpan penilaian", http.StatusInternalServerError)
-		log.Printf("Insert penilaian Error: %v\n", err)
+		log.Printf("Create penilaian Error: %v\n", err)
+		penilaianError(w, err, "Nilai tidak ditemukan", "Gagal menyimpan penilaian")
 		return
 	}
 
 	// Step 4: Siapkan respon
-	response := models.Penilaian{
+	writeJSON(w, http.StatusCreated, models.Penilaian{
 		IDPenilaian: idPenilaian,
 		IDNilai:     idNilai,
 		IDMapel:     penilaian.IDMapel,
 		IDSiswa:     penilaian.IDSiswa,
+		IDSemester:  semester.IDSemester,
 		NamaNilai:   penilaian.NamaNilai,
 		Nilai:       penilaian.Nilai,
-		Bobot:       fmt.Sprintf("%.2f%%", bobotFloat*100),
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(response)
+		Bobot:       models.FormatBobot(bobotFloat),
+		IDKomponen:  penilaian.IDKomponen,
+	})
 }
 
-
-
-func UpdatePenilaianHandler(w http.ResponseWriter, r *http.Request) {
-	database, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Error connecting to the database", http.StatusInternalServerError)
+// UpdatePenilaianHandler - Mengubah nama, nilai dan bobot sebuah komponen penilaian
+func (h *Handler) UpdatePenilaianHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID penilaian tidak valid") // id_penilaian
+	if !ok {
 		return
 	}
-	defer database.Close()
 
-	id := mux.Vars(r)["id"] // id_penilaian
+	allowed, err := h.canManagePenilaian(r.Context(), currentUser(r), id)
+	if !authorize(w, allowed, err) {
+		return
+	}
 
 	var penilaian models.Penilaian
 	if err := json.NewDecoder(r.Body).Decode(&penilaian); err != nil {
@@ -1422,60 +1054,72 @@ func UpdatePenilaianHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Konversi string bobot (misal: "20.00%") ke float64
-	bobotFloat, err := strconv.ParseFloat(strings.TrimSuffix(penilaian.Bobot, "%"), 64)
+	// Penilaian komponen hanya boleh diubah nilainya, nama dan bobot mengikuti komponen
+	existing, err := h.Repo.Nilai.GetPenilaian(r.Context(), id)
+	if err != nil {
+		repoError(w, err, "Penilaian not found", "Error querying database")
+		return
+	}
+	nilaiMaks := models.NilaiMax
+	if existing.IDKomponen != nil {
+		komponen, err := h.Repo.Komponen.GetByID(r.Context(), *existing.IDKomponen)
+		if err != nil {
+			repoError(w, err, "Komponen tidak ditemukan", "Gagal mengambil komponen penilaian")
+			return
+		}
+		penilaian.NamaNilai = komponen.NamaKomponen
+		penilaian.Bobot = komponen.Bobot
+		nilaiMaks = komponen.NilaiMaks
+	}
+
+	// Konversi string bobot (misal: "20.00%") ke desimal: 20% → 0.2
+	bobotFloat, err := models.ParseBobot(penilaian.Bobot)
 	if err != nil {
 		http.Error(w, "Format bobot salah", http.StatusBadRequest)
 		return
 	}
-	bobotFloat = bobotFloat / 100 // Ubah jadi bentuk desimal: 20% → 0.2
+	if !validatePenilaian(w, penilaian.Nilai, nilaiMaks, bobotFloat) {
+		return
+	}
 
-	_, err = database.Exec(`
-		UPDATE penilaian 
-		SET nama_nilai=$1, nilai=$2, bobot=$3 
-		WHERE id_penilaian=$4`,
-		penilaian.NamaNilai, penilaian.Nilai, bobotFloat, id,
-	)
+	err = h.Repo.InTx(r.Context(), func(repos repository.Repositories) error {
+		return changePenilaian(r.Context(), repos, id, func(p models.Penilaian) error {
+			if err := checkBobot(r.Context(), repos, p.IDNilai, id, bobotFloat); err != nil {
+				return err
+			}
+			return repos.Nilai.UpdatePenilaian(r.Context(), id, penilaian.NamaNilai, penilaian.Nilai, bobotFloat)
+		})
+	})
 	if err != nil {
-		http.Error(w, "Error updating data in the database", http.StatusInternalServerError)
+		penilaianError(w, err, "Penilaian not found", "Error updating data in the database")
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
+	writeJSON(w, http.StatusOK, map[string]string{
 		"message": "Penilaian berhasil diperbarui",
 	})
 }
 
-
-func DeletePenilaianHandler(w http.ResponseWriter, r *http.Request) {
-	database, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Error connecting to the database", http.StatusInternalServerError)
+// DeletePenilaianHandler - Menghapus sebuah komponen penilaian
+func (h *Handler) DeletePenilaianHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID penilaian tidak valid")
+	if !ok {
 		return
 	}
-	defer database.Close()
-
-	id := mux.Vars(r)["id"]
 	log.Println("Deleting penilaian with ID:", id)
 
-	result, err := database.Exec("DELETE FROM penilaian WHERE id_penilaian=$1", id)
-	if err != nil {
-		log.Println("Error deleting from database:", err)
-		http.Error(w, "Error deleting data from the database", http.StatusInternalServerError)
+	allowed, err := h.canManagePenilaian(r.Context(), currentUser(r), id)
+	if !authorize(w, allowed, err) {
 		return
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	err = h.Repo.InTx(r.Context(), func(repos repository.Repositories) error {
+		return changePenilaian(r.Context(), repos, id, func(models.Penilaian) error {
+			return repos.Nilai.DeletePenilaian(r.Context(), id)
+		})
+	})
 	if err != nil {
-		log.Println("Error checking rows affected:", err)
-		http.Error(w, "Error checking affected rows", http.StatusInternalServerError)
-		return
-	}
-
-	if rowsAffected == 0 {
-		log.Println("No penilaian found with ID:", id)
-		http.Error(w, "Penilaian not found", http.StatusNotFound)
+		repoError(w, err, "Penilaian not found", "Error deleting data from the database")
 		return
 	}
 
@@ -1484,119 +1128,170 @@ func DeletePenilaianHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("Penilaian berhasil dihapus"))
 }
 
-// GetPenilaianHandler - Mendapatkan semua data guru
-func GetPenilaianHandler(w http.ResponseWriter, r *http.Request) {
-	database, err := db.ConnectToDB()
+// changePenilaian - Menjalankan change pada penilaian idPenilaian (dengan data lamanya) lalu
+// menghitung ulang total_nilai induknya. Harus dipanggil di dalam transaksi (repos dari InTx).
+func changePenilaian(ctx context.Context, repos repository.Repositories, idPenilaian int, change func(models.Penilaian) error) error {
+	p, err := repos.Nilai.GetPenilaian(ctx, idPenilaian)
 	if err != nil {
-		http.Error(w, "Error connecting to the database", http.StatusInternalServerError)
-		return
+		return err
+	}
+	if err := repos.Nilai.Lock(ctx, p.IDNilai); err != nil {
+		return err
+	}
+	if err := change(p); err != nil {
+		return err
 	}
-	defer database.Close()
+	return repos.Nilai.RecomputeTotal(ctx, p.IDNilai)
+}
+
+// bobotExceededError - Total bobot penilaian di bawah satu nilai akan melebihi models.BobotMax
+type bobotExceededError struct {
+	sisa float64
+}
+
+func (e *bobotExceededError) Error() string {
+	return fmt.Sprintf("Total bobot melebihi %s, sisa bobot yang tersedia %s",
+		models.FormatBobot(models.BobotMax), models.FormatBobot(e.sisa))
+}
 
-	rows, err := database.Query("SELECT id_penilaian, id_nilai, nama_nilai, nilai, bobot FROM penilaian")
+// sisaBobot - Bobot yang belum dipakai jika penilaian yang ada sudah memakai terpakai
+func sisaBobot(terpakai float64) float64 {
+	return max(models.BobotMax-terpakai, 0)
+}
+
+// checkBobot - Memastikan bobot baru masih muat di bawah id_nilai. exceptPenilaian adalah
+// penilaian yang sedang diubah (0 untuk penilaian baru). Baris nilai harus sudah terkunci.
+func checkBobot(ctx context.Context, repos repository.Repositories, idNilai, exceptPenilaian int, bobot float64) error {
+	terpakai, err := repos.Nilai.SumBobot(ctx, idNilai, exceptPenilaian)
 	if err != nil {
-		http.Error(w, "Error querying database", http.StatusInternalServerError)
-		return
+		return err
 	}
-	defer rows.Close()
+	if models.BobotPoints(terpakai)+models.BobotPoints(bobot) > models.BobotPoints(models.BobotMax) {
+		return &bobotExceededError{sisa: sisaBobot(terpakai)}
+	}
+	return nil
+}
 
-	var penilaians []models.Penilaian
-	for rows.Next() {
-		var penilaian models.Penilaian
-		if err := rows.Scan(&penilaian.IDPenilaian, &penilaian.IDNilai, &penilaian.NamaNilai, &penilaian.Nilai, &penilaian.Bobot); err != nil {
-			log.Println("Error scanning row:", err)
-			continue
-		}
-		penilaians = append(penilaians, penilaian)
+// validatePenilaian - Menulis 400 jika nilai (0 - nilaiMaks) atau bobot di luar rentang yang diizinkan
+func validatePenilaian(w http.ResponseWriter, nilai, nilaiMaks int, bobot float64) bool {
+	if err := models.ValidateNilaiMaks(nilai, nilaiMaks); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	if err := models.ValidateBobot(bobot); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
 	}
+	return true
+}
+
+// requestError - Data request tidak valid yang baru ketahuan di dalam transaksi
+type requestError string
+
+func (e requestError) Error() string { return string(e) }
 
-	if err := rows.Err(); err != nil {
-		http.Error(w, "Error processing rows", http.StatusInternalServerError)
+// requestOrRepoError - 400 untuk requestError, selain itu sama seperti repoError
+func requestOrRepoError(w http.ResponseWriter, err error, notFoundMsg, msg string) {
+	var invalid requestError
+	if errors.As(err, &invalid) {
+		http.Error(w, invalid.Error(), http.StatusBadRequest)
 		return
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(penilaians)
+	repoError(w, err, notFoundMsg, msg)
 }
 
-func GetUserHandler(w http.ResponseWriter, r *http.Request) {
-	database, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Error connecting to the database", http.StatusInternalServerError)
+// penilaianError - Seperti requestOrRepoError, ditambah 400 untuk total bobot yang melebihi 100%
+func penilaianError(w http.ResponseWriter, err error, notFoundMsg, msg string) {
+	var exceeded *bobotExceededError
+	if errors.As(err, &exceeded) {
+		http.Error(w, exceeded.Error(), http.StatusBadRequest)
 		return
 	}
-	defer database.Close()
+	requestOrRepoError(w, err, notFoundMsg, msg)
+}
 
-	rows, err := database.Query(`SELECT id_user, username, password, id_role, tanggal_registrasi FROM "user"`)
+// RecomputeNilaiHandler - Menghitung ulang semua total_nilai dari penilaian (perbaikan data lama)
+func (h *Handler) RecomputeNilaiHandler(w http.ResponseWriter, r *http.Request) {
+	updated, err := h.Repo.Nilai.RecomputeAll(r.Context())
 	if err != nil {
-		http.Error(w, "Error querying database", http.StatusInternalServerError)
+		log.Println("Recompute nilai error:", err)
+		dbError(w, err, "Gagal menghitung ulang nilai")
 		return
 	}
-	defer rows.Close()
 
-	var users []models.User
-	for rows.Next() {
-		var user models.User
-		if err := rows.Scan(&user.IDUser, &user.Username, &user.Password, &user.IDRole, &user.TanggalRegistrasi); err != nil {
-			log.Println("Error scanning row:", err)
-			continue
-		}
-		users = append(users, user)
+	log.Println("Total nilai dihitung ulang, baris berubah:", updated)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Total nilai berhasil dihitung ulang",
+		"updated": updated,
+	})
+}
+
+// GetPenilaianHandler - Mendapatkan data penilaian semester aktif (?id_semester= untuk semester lain,
+// ?id_semester=semua untuk semua semester)
+func (h *Handler) GetPenilaianHandler(w http.ResponseWriter, r *http.Request) {
+	idSemester, ok := h.semesterFilter(w, r)
+	if !ok {
+		return
 	}
 
-	if err := rows.Err(); err != nil {
-		http.Error(w, "Error processing rows", http.StatusInternalServerError)
+	penilaians, err := h.Repo.Nilai.ListPenilaian(r.Context(), idSemester)
+	if err != nil {
+		dbError(w, err, "Error querying database")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(users)
+	writeJSON(w, http.StatusOK, penilaians)
 }
 
-func CreateUserHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+// GetUserHandler - Mendapatkan semua user (tanpa password)
+func (h *Handler) GetUserHandler(w http.ResponseWriter, r *http.Request) {
+	users, err := h.Repo.User.List(r.Context())
+	if err != nil {
+		dbError(w, err, "Error querying database")
 		return
 	}
 
-	database, err := db.ConnectToDB()
-	if err != nil {
-		log.Println("DB connect error:", err)
-		http.Error(w, "Database error", http.StatusInternalServerError)
+	writeJSON(w, http.StatusOK, users)
+}
+
+// CreateUserHandler - Menambahkan user baru dengan password yang sudah di-hash
+func (h *Handler) CreateUserHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	defer database.Close()
 
 	var user models.User
-	err = json.NewDecoder(r.Body).Decode(&user)
-	if err != nil {
+	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
 		log.Println("JSON decode error:", err)
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	query := `INSERT INTO "user" (username, password, id_role, tanggal_registrasi) VALUES ($1, $2, $3, $4)`
-	_, err = database.Exec(query, user.Username, user.Password, user.IDRole, user.TanggalRegistrasi)
+	hash, err := auth.HashPassword(user.Password)
 	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	user.Password = hash
+
+	if _, err := h.Repo.User.Create(r.Context(), user); err != nil {
 		log.Println("Insert error:", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		repoError(w, err, "User not found", "Gagal menyimpan user")
 		return
 	}
 
-	log.Println("User berhasil ditambahkan:", user)
+	log.Println("User berhasil ditambahkan:", user.Username)
 	w.WriteHeader(http.StatusCreated)
 	w.Write([]byte("User berhasil ditambahkan"))
 }
 
-func UpdateUserHandler(w http.ResponseWriter, r *http.Request) {
-	database, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Error connecting to the database", http.StatusInternalServerError)
+// UpdateUserHandler - Mengubah data user, password hanya diganti jika diisi
+func (h *Handler) UpdateUserHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID user tidak valid")
+	if !ok {
 		return
 	}
-	defer database.Close()
-
-	id := mux.Vars(r)["id"]
 
 	var user models.User
 	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
@@ -1604,49 +1299,45 @@ func UpdateUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = database.Exec(
-		`UPDATE "user" SET username=$1, password=$2, id_role=$3, tanggal_registrasi=$4 WHERE id_user=$5`,
-		user.Username, user.Password, user.IDRole, user.TanggalRegistrasi, id,
-	)
-	if err != nil {
-		http.Error(w, "Error updating data in the database", http.StatusInternalServerError)
-		return
+	// Password kosong berarti password lama tidak diubah
+	var hash string
+	if user.Password != "" {
+		var err error
+		if hash, err = auth.HashPassword(user.Password); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(user)
-}
-
-func DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
-	database, err := db.ConnectToDB()
+	err := h.Repo.InTx(r.Context(), func(repos repository.Repositories) error {
+		if err := repos.User.Update(r.Context(), id, user); err != nil {
+			return err
+		}
+		if hash == "" {
+			return nil
+		}
+		return repos.User.UpdatePassword(r.Context(), id, hash)
+	})
 	if err != nil {
-		http.Error(w, "Error connecting to the database", http.StatusInternalServerError)
+		repoError(w, err, "User not found", "Error updating data in the database")
 		return
 	}
-	defer database.Close()
 
-	id := mux.Vars(r)["id"]
-	log.Println("Deleting user with ID:", id) // Log ID yang akan dihapus
-
-	// Menghapus user dari database berdasarkan id_user
-	result, err := database.Exec(`DELETE FROM "user" WHERE id_user=$1`, id)
-	if err != nil {
-		log.Println("Error deleting from database:", err)
-		http.Error(w, "Error deleting data from the database", http.StatusInternalServerError)
-		return
-	}
+	user.IDUser = id
+	user.Password = ""
+	writeJSON(w, http.StatusOK, user)
+}
 
-	// Mengecek apakah baris data benar-benar terhapus
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		log.Println("Error checking rows affected:", err)
-		http.Error(w, "Error checking affected rows", http.StatusInternalServerError)
+// DeleteUserHandler - Menghapus user berdasarkan ID
+func (h *Handler) DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID user tidak valid")
+	if !ok {
 		return
 	}
+	log.Println("Deleting user with ID:", id) // Log ID yang akan dihapus
 
-	if rowsAffected == 0 {
-		log.Println("No user found with ID:", id)
-		http.Error(w, "User not found", http.StatusNotFound)
+	if err := h.Repo.User.Delete(r.Context(), id); err != nil {
+		repoError(w, err, "User not found", "Error deleting data from the database")
 		return
 	}
 
@@ -1655,85 +1346,55 @@ func DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("User berhasil dihapus"))
 }
 
-func GetUserByIDHandler(w http.ResponseWriter, r *http.Request) {
-	// Mengambil ID dari URL parameter
-	id := mux.Vars(r)["id"]
-
-	// Membuka koneksi ke database
-	database, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Error connecting to the database", http.StatusInternalServerError)
+// GetUserByIDHandler - Mendapatkan user berdasarkan ID (tanpa password)
+func (h *Handler) GetUserByIDHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID user tidak valid")
+	if !ok {
 		return
 	}
-	defer database.Close()
 
-	// Query untuk mendapatkan data user berdasarkan ID
-	var user models.User
-	err = database.QueryRow(`SELECT id_user, username, password, id_role, tanggal_registrasi FROM "user" WHERE id_user=$1`, id).
-		Scan(&user.IDUser, &user.Username, &user.Password, &user.IDRole, &user.TanggalRegistrasi)
+	user, err := h.Repo.User.GetByID(r.Context(), id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			http.Error(w, "User not found", http.StatusNotFound)
-		} else {
-			http.Error(w, "Error querying database", http.StatusInternalServerError)
-		}
+		repoError(w, err, "User not found", "Error querying database")
 		return
 	}
 
-	// Mengirimkan data user dalam format JSON
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(user)
+	writeJSON(w, http.StatusOK, user)
 }
 
 const (
-	bucketName = "lasharan-bucket"        // Ganti dengan nama bucket S3 kamu
-	s3GuruPath = "guru/"                   // Folder di S3 untuk menyimpan foto guru
-	s3SiswaPath = "siswa/"                   // Folder di S3 untuk menyimpan foto guru
+	s3GuruPath  = "guru/"  // Folder di S3 untuk menyimpan foto guru
+	s3SiswaPath = "siswa/" // Folder di S3 untuk menyimpan foto siswa
 )
 
-func UploadFotoGuruHandler(w http.ResponseWriter, r *http.Request) {
-	log.Println("Start upload foto guru")
-
-	// 1. Parse multipart form
-	err := r.ParseMultipartForm(10 << 20) // 10MB
-	if err != nil {
-		http.Error(w, "Gagal parsing form", http.StatusBadRequest)
-		return
-	}
-
-	// 2. Ambil file foto
+// uploadFoto - Upload file form "foto" ke S3 dengan nama <prefix>_<id>_<unix>.<ext>
+// di folder dir, mengembalikan URL publiknya. false jika respons error sudah ditulis.
+func (h *Handler) uploadFoto(w http.ResponseWriter, r *http.Request, dir, prefix string, id int) (string, bool) {
 	file, handler, err := r.FormFile("foto")
 	if err != nil {
 		http.Error(w, "File tidak ditemukan", http.StatusBadRequest)
-		return
+		return "", false
 	}
 	defer file.Close()
 
-	// 3. Ambil ID guru
-	id := r.FormValue("id_guru")
-	if id == "" {
-		http.Error(w, "id_guru diperlukan", http.StatusBadRequest)
-		return
-	}
-
-	// 4. Generate nama file dan path di S3
+	// Generate nama file dan path di S3
 	fileExt := filepath.Ext(handler.Filename)
-	fileName := fmt.Sprintf("guru_%s_%d%s", id, time.Now().Unix(), fileExt)
-	s3Key := s3GuruPath + fileName
+	fileName := fmt.Sprintf("%s_%d_%d%s", prefix, id, time.Now().Unix(), fileExt)
+	s3Key := dir + fileName
 
-	// 5. Deteksi content-type
+	// Deteksi content-type
 	buffer := make([]byte, 512)
-	_, err = file.Read(buffer)
-	if err != nil {
+	n, err := file.Read(buffer)
+	if err != nil && err != io.EOF {
 		http.Error(w, "Gagal membaca file", http.StatusInternalServerError)
-		return
+		return "", false
 	}
-	contentType := http.DetectContentType(buffer)
+	contentType := http.DetectContentType(buffer[:n])
 	file.Seek(0, io.SeekStart) // Reset posisi
 
-	// 6. Upload ke S3
+	// Upload ke S3
 	_, err = config.S3Client.PutObject(context.TODO(), &s3.PutObjectInput{
-		Bucket:      aws.String(bucketName),
+		Bucket:      aws.String(h.Config.S3.Bucket),
 		Key:         aws.String(s3Key),
 		Body:        file,
 		ContentType: aws.String(contentType),
@@ -1741,176 +1402,139 @@ func UploadFotoGuruHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Println("Upload ke S3 gagal:", err)
 		http.Error(w, "Gagal upload ke S3", http.StatusInternalServerError)
-		return
+		return "", false
 	}
 
-	// 7. Simpan URL ke database
-	s3URL := fmt.Sprintf("https://%s.s3.ap-southeast-3.amazonaws.com/%s", bucketName, s3Key)
+	return h.Config.S3.PublicURL(s3Key), true
+}
 
-	database, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Gagal koneksi ke database", http.StatusInternalServerError)
+// UploadFotoGuruHandler - Upload foto guru ke S3 dan menyimpan URL-nya
+func (h *Handler) UploadFotoGuruHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Start upload foto guru")
+
+	// 1. Parse multipart form
+	if err := r.ParseMultipartForm(10 << 20); err != nil { // 10MB
+		http.Error(w, "Gagal parsing form", http.StatusBadRequest)
 		return
 	}
-	defer database.Close()
 
-	_, err = database.Exec("UPDATE guru SET foto = $1 WHERE id_guru = $2", s3URL, id)
+	// 2. Ambil ID guru
+	id, err := strconv.Atoi(r.FormValue("id_guru"))
 	if err != nil {
-		http.Error(w, "Gagal update database", http.StatusInternalServerError)
+		http.Error(w, "id_guru diperlukan", http.StatusBadRequest)
 		return
 	}
 
-	// 8. Kirim respons sukses
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
+	allowed, err := h.ownsGuru(r.Context(), currentUser(r), id)
+	if !authorize(w, allowed, err) {
+		return
+	}
+
+	// 3. Upload ke S3
+	s3URL, ok := h.uploadFoto(w, r, s3GuruPath, "guru", id)
+	if !ok {
+		return
+	}
+
+	// 4. Simpan URL ke database
+	if err := h.Repo.Guru.UpdateFoto(r.Context(), id, s3URL); err != nil {
+		repoError(w, err, "Guru not found", "Gagal update database")
+		return
+	}
+
+	// 5. Kirim respons sukses
+	writeJSON(w, http.StatusOK, map[string]string{
 		"message": "Foto guru berhasil diupload",
 		"url":     s3URL,
 	})
 }
 
-func UploadFotoSiswaHandler(w http.ResponseWriter, r *http.Request) {
+// UploadFotoSiswaHandler - Upload foto siswa ke S3 dan menyimpan URL-nya
+func (h *Handler) UploadFotoSiswaHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Start upload foto siswa")
 
 	// 1. Parse multipart form
-	err := r.ParseMultipartForm(10 << 20) // 10MB
-	if err != nil {
+	if err := r.ParseMultipartForm(10 << 20); err != nil { // 10MB
 		http.Error(w, "Gagal parsing form", http.StatusBadRequest)
 		return
 	}
 
-	// 2. Ambil file foto
-	file, handler, err := r.FormFile("foto")
+	// 2. Ambil ID siswa
+	id, err := strconv.Atoi(r.FormValue("id_siswa"))
 	if err != nil {
-		http.Error(w, "File tidak ditemukan", http.StatusBadRequest)
-		return
-	}
-	defer file.Close()
-
-	// 3. Ambil ID siswa
-	id := r.FormValue("id_siswa")
-	if id == "" {
 		http.Error(w, "id_siswa diperlukan", http.StatusBadRequest)
 		return
 	}
 
-	// 4. Generate nama file dan path di S3
-	fileExt := filepath.Ext(handler.Filename)
-	fileName := fmt.Sprintf("siswa_%s_%d%s", id, time.Now().Unix(), fileExt)
-	s3Key := s3SiswaPath + fileName
-
-	// 5. Deteksi content-type
-	buffer := make([]byte, 512)
-	_, err = file.Read(buffer)
-	if err != nil {
-		http.Error(w, "Gagal membaca file", http.StatusInternalServerError)
-		return
-	}
-	contentType := http.DetectContentType(buffer)
-	file.Seek(0, io.SeekStart) // Reset posisi
-
-	// 6. Upload ke S3
-	_, err = config.S3Client.PutObject(context.TODO(), &s3.PutObjectInput{
-		Bucket:      aws.String(bucketName),
-		Key:         aws.String(s3Key),
-		Body:        file,
-		ContentType: aws.String(contentType),
-	})
-	if err != nil {
-		log.Println("Upload ke S3 gagal:", err)
-		http.Error(w, "Gagal upload ke S3", http.StatusInternalServerError)
+	allowed, err := h.ownsSiswa(r.Context(), currentUser(r), id)
+	if !authorize(w, allowed, err) {
 		return
 	}
 
-	// 7. Simpan URL ke database
-	s3URL := fmt.Sprintf("https://%s.s3.ap-southeast-3.amazonaws.com/%s", bucketName, s3Key)
-
-	database, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Gagal koneksi ke database", http.StatusInternalServerError)
+	// 3. Upload ke S3
+	s3URL, ok := h.uploadFoto(w, r, s3SiswaPath, "siswa", id)
+	if !ok {
 		return
 	}
-	defer database.Close()
 
-	_, err = database.Exec("UPDATE siswa SET foto = $1 WHERE id_siswa = $2", s3URL, id)
-	if err != nil {
-		http.Error(w, "Gagal update database", http.StatusInternalServerError)
+	// 4. Simpan URL ke database
+	if err := h.Repo.Siswa.UpdateFoto(r.Context(), id, s3URL); err != nil {
+		repoError(w, err, "Siswa not found", "Gagal update database")
 		return
 	}
 
-	// 8. Kirim respons sukses
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
+	// 5. Kirim respons sukses
+	writeJSON(w, http.StatusOK, map[string]string{
 		"message": "Foto siswa berhasil diupload",
 		"url":     s3URL,
 	})
 }
 
-func GetNilaiByUserIDHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	idUserStr := vars["id_user"]
-	idUser, err := strconv.Atoi(idUserStr)
-	if err != nil {
-		http.Error(w, "ID user tidak valid", http.StatusBadRequest)
+// GetNilaiByUserIDHandler - Total nilai per mapel milik siswa dengan id_user tersebut pada satu semester
+// (?id_semester=, default semester aktif/terakhir tahun ajaran kelas siswa)
+func (h *Handler) GetNilaiByUserIDHandler(w http.ResponseWriter, r *http.Request) {
+	idUser, ok := pathInt(w, r, "id_user", "ID user tidak valid")
+	if !ok {
 		return
 	}
 
-	dbConn, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Gagal koneksi ke database", http.StatusInternalServerError)
+	if !authorize(w, canReadUserData(currentUser(r), idUser), nil) {
 		return
 	}
-	defer dbConn.Close()
 
-	var idSiswa int
-	err = dbConn.QueryRow("SELECT id_siswa FROM siswa WHERE id_user = $1", idUser).Scan(&idSiswa)
+	siswa, err := h.Repo.Siswa.GetByUserID(r.Context(), idUser)
 	if err != nil {
-		http.Error(w, "Gagal menemukan siswa dari user", http.StatusNotFound)
+		repoError(w, err, "Gagal menemukan siswa dari user", "Gagal mengambil data siswa")
 		return
 	}
-
-	query := `
-		SELECT n.id_nilai, n.total_nilai, m.nama_mata_pelajaran
-		FROM nilai n
-		JOIN mata_pelajaran m ON n.id_mapel = m.id_mapel
-		WHERE n.id_siswa = $1
-	`
-	rows, err := dbConn.Query(query, idSiswa)
+	idSemester, ok := semesterParam(w, r)
+	if !ok {
+		return
+	}
+	semester, err := h.siswaSemester(r.Context(), siswa, idSemester)
 	if err != nil {
-		http.Error(w, "Query gagal (nilai)", http.StatusInternalServerError)
+		requestOrRepoError(w, err, "Kelas siswa tidak ditemukan", "Gagal mengambil semester")
 		return
 	}
-	defer rows.Close()
 
-	type NilaiDetail struct {
-		ID    int     `json:"id_nilai"`
-		Nilai float64 `json:"nilai"`
-		Mapel string  `json:"mapel"`
+	nilaiList, err := h.Repo.Nilai.ListBySiswa(r.Context(), siswa.IDSiswa, semester.IDSemester)
+	if err != nil {
+		dbError(w, err, "Query gagal (nilai)")
+		return
 	}
-
-	var nilaiList []NilaiDetail
-	for rows.Next() {
-		var nd NilaiDetail
-		err := rows.Scan(&nd.ID, &nd.Nilai, &nd.Mapel)
-		if err != nil {
-			http.Error(w, "Gagal membaca data nilai", http.StatusInternalServerError)
-			return
-		}
-		nilaiList = append(nilaiList, nd)
+	for i, n := range nilaiList {
+		nilaiList[i].Predikat = n.Skala.Predikat(n.Nilai)
+		nilaiList[i].Lulus = n.Nilai >= n.KKM
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(nilaiList)
+	writeJSON(w, http.StatusOK, nilaiList)
 }
 
-func UpdateSiswaClassHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	vars := mux.Vars(r)
-	idSiswaStr := vars["id_siswa"]
-
-	idSiswa, err := strconv.Atoi(idSiswaStr)
-	if err != nil {
-		http.Error(w, "ID siswa tidak valid", http.StatusBadRequest)
+// UpdateSiswaClassHandler - Memasukkan siswa ke sebuah kelas. Kelas sebelumnya tetap tercatat
+// di riwayat kelas dengan status pindah.
+func (h *Handler) UpdateSiswaClassHandler(w http.ResponseWriter, r *http.Request) {
+	idSiswa, ok := pathInt(w, r, "id_siswa", "ID siswa tidak valid")
+	if !ok {
 		return
 	}
 
@@ -1924,47 +1548,22 @@ func UpdateSiswaClassHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	dbConn, err := db.ConnectToDB()
-	if err != nil {
-		http.Error(w, "Gagal koneksi ke database", http.StatusInternalServerError)
-		return
-	}
-	defer dbConn.Close()
-
-	// Eksekusi query update
-	query := `UPDATE siswa SET id_kelas = $1 WHERE id_siswa = $2`
-	_, err = dbConn.Exec(query, payload.IdKelas, idSiswa)
+	err := h.Repo.InTx(r.Context(), func(repos repository.Repositories) error {
+		siswa, err := repos.Siswa.GetByID(r.Context(), idSiswa)
+		if err != nil {
+			return err
+		}
+		if siswa.Status != models.SiswaAktif {
+			return requestError(errSiswaNonaktif)
+		}
+		return pindahKelas(r.Context(), repos, siswa, payload.IdKelas, models.RiwayatPindah, "")
+	})
 	if err != nil {
-		http.Error(w, "Gagal memasukkan siswa ke kelas", http.StatusInternalServerError)
+		requestOrRepoError(w, err, "Siswa tidak ditemukan", "Gagal memasukkan siswa ke kelas")
 		return
 	}
 
-	json.NewEncoder(w).Encode(map[string]string{
+	writeJSON(w, http.StatusOK, map[string]string{
 		"message": "Siswa berhasil dimasukkan ke kelas",
 	})
 }
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
-
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"myapp/config"
+	"myapp/internal/auth"
+	"myapp/internal/models"
+	"myapp/internal/repository"
+	"myapp/internal/repository/fake"
+)
+
+// testEnv - Handler di atas repository fake dengan data dasar: satu tahun ajaran dengan semester 1 aktif,
+// dua guru yang masing-masing wali satu kelas, satu mapel per kelas, dan satu siswa aktif per kelas
+type testEnv struct {
+	h     *Handler
+	store *fake.Store
+	repo  repository.Repositories
+
+	admin, guruA, guruB, siswaA, siswaB *auth.Claims
+
+	idSemester      int
+	idGuruA         int
+	idGuruB         int
+	idKelasA        int
+	idKelasB        int
+	idMapelA        int
+	idMapelB        int
+	idSiswaA        int
+	idSiswaB        int
+	tanggalSemester string
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	ctx := context.Background()
+	store := fake.New()
+	repo := store.Repositories()
+	e := &testEnv{h: NewHandler(repo, &config.Config{}), store: store, repo: repo, tanggalSemester: "2025-07-14"}
+
+	must := func(id int, err error) int {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	user := func(username string, role int) *auth.Claims {
+		id := must(repo.User.Create(ctx, models.User{Username: username, Password: "x", IDRole: role}))
+		return &auth.Claims{IDUser: id, IDRole: role}
+	}
+
+	e.admin = user("admin", auth.RoleAdmin)
+	e.guruA, e.guruB = user("guru-a", auth.RoleGuru), user("guru-b", auth.RoleGuru)
+	e.siswaA, e.siswaB = user("siswa-a", auth.RoleSiswa), user("siswa-b", auth.RoleSiswa)
+
+	idTahun := must(repo.Periode.CreateTahunAjaran(ctx, models.TahunAjaran{Nama: "2025/2026"}))
+	e.idSemester = must(repo.Periode.CreateSemester(ctx, models.Semester{
+		IDTahunAjaran: idTahun, Semester: 1, TanggalMulai: "2025-07-14", TanggalSelesai: "2025-12-20",
+	}))
+	must(0, repo.Periode.Activate(ctx, e.idSemester))
+
+	e.idGuruA = must(repo.Guru.Create(ctx, models.Guru{IDUser: e.guruA.IDUser, NamaGuru: "Guru A"}))
+	e.idGuruB = must(repo.Guru.Create(ctx, models.Guru{IDUser: e.guruB.IDUser, NamaGuru: "Guru B"}))
+	e.idKelasA = must(repo.Kelas.Create(ctx, models.Kelas{IDGuru: e.idGuruA, NamaKelas: "X IPA 1", IDTahunAjaran: idTahun}))
+	e.idKelasB = must(repo.Kelas.Create(ctx, models.Kelas{IDGuru: e.idGuruB, NamaKelas: "X IPA 2", IDTahunAjaran: idTahun}))
+	e.idMapelA = must(repo.MataPelajaran.Create(ctx, models.MataPelajaran{
+		IDKelas: e.idKelasA, NamaMataPelajaran: "Matematika", KKM: 75, AturanRemedial: models.AturanRemedialBatasKKM,
+	}))
+	e.idMapelB = must(repo.MataPelajaran.Create(ctx, models.MataPelajaran{
+		IDKelas: e.idKelasB, NamaMataPelajaran: "Fisika", KKM: 75, AturanRemedial: models.AturanRemedialBatasKKM,
+	}))
+	e.idSiswaA = e.addSiswa(t, e.siswaA.IDUser, e.idKelasA, "Ani", "0011223344")
+	e.idSiswaB = e.addSiswa(t, e.siswaB.IDUser, e.idKelasB, "Budi", "0011223355")
+	return e
+}
+
+// addSiswa - Siswa aktif baru di idKelas sejak awal semester, seperti CreateSiswaHandler
+func (e *testEnv) addSiswa(t *testing.T, idUser, idKelas int, nama, nisn string) int {
+	t.Helper()
+	ctx := context.Background()
+	id, err := e.repo.Siswa.Create(ctx, models.Siswa{IDUser: idUser, IDKelas: &idKelas, NamaSiswa: nama, NISN: nisn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.repo.RiwayatKelas.Masuk(ctx, id, idKelas, e.tanggalSemester); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// do - Memanggil handler langsung dengan user yang sudah terautentikasi, variabel path mux dan body JSON
+func (e *testEnv) do(t *testing.T, handler http.HandlerFunc, user *auth.Claims, method, target string, vars map[string]string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(b)
+	}
+	r := httptest.NewRequest(method, target, reader)
+	if user != nil {
+		r = r.WithContext(auth.WithUser(r.Context(), user))
+	}
+	if vars != nil {
+		r = mux.SetURLVars(r, vars)
+	}
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w
+}
+
+// expectStatus - Menghentikan test jika status respons bukan want, isi respons ikut ditampilkan
+func expectStatus(t *testing.T, w *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if w.Code != want {
+		t.Fatalf("status = %d, want %d (body: %s)", w.Code, want, w.Body.String())
+	}
+}
+
+func TestErrorMapping(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(http.ResponseWriter, error)
+		err   error
+		want  int
+	}{
+		{"dbError database mati", func(w http.ResponseWriter, err error) { dbError(w, err, "gagal") }, driver.ErrBadConn, http.StatusServiceUnavailable},
+		{"dbError io.EOF", func(w http.ResponseWriter, err error) { dbError(w, err, "gagal") }, io.EOF, http.StatusServiceUnavailable},
+		{"dbError error lain", func(w http.ResponseWriter, err error) { dbError(w, err, "gagal") }, errors.New("syntax error"), http.StatusInternalServerError},
+
+		{"repoError not found", func(w http.ResponseWriter, err error) { repoError(w, err, "tidak ada", "gagal") }, repository.ErrNotFound, http.StatusNotFound},
+		{"repoError not found dibungkus", func(w http.ResponseWriter, err error) { repoError(w, err, "tidak ada", "gagal") },
+			fmt.Errorf("get siswa: %w", repository.ErrNotFound), http.StatusNotFound},
+		{"repoError conflict", func(w http.ResponseWriter, err error) { repoError(w, err, "tidak ada", "gagal") },
+			fmt.Errorf("%w: siswa_nisn_key", repository.ErrConflict), http.StatusConflict},
+		{"repoError database mati", func(w http.ResponseWriter, err error) { repoError(w, err, "tidak ada", "gagal") },
+			fmt.Errorf("query: %w", driver.ErrBadConn), http.StatusServiceUnavailable},
+		{"repoError error lain", func(w http.ResponseWriter, err error) { repoError(w, err, "tidak ada", "gagal") }, errors.New("boom"), http.StatusInternalServerError},
+
+		{"requestOrRepoError request", func(w http.ResponseWriter, err error) { requestOrRepoError(w, err, "tidak ada", "gagal") },
+			requestError("id_semester tidak valid"), http.StatusBadRequest},
+		{"requestOrRepoError not found", func(w http.ResponseWriter, err error) { requestOrRepoError(w, err, "tidak ada", "gagal") },
+			repository.ErrNotFound, http.StatusNotFound},
+		{"requestOrRepoError bobot bukan 400", func(w http.ResponseWriter, err error) { requestOrRepoError(w, err, "tidak ada", "gagal") },
+			&bobotExceededError{sisa: 0.1}, http.StatusInternalServerError},
+
+		{"penilaianError bobot", func(w http.ResponseWriter, err error) { penilaianError(w, err, "tidak ada", "gagal") },
+			&bobotExceededError{sisa: 0.1}, http.StatusBadRequest},
+		{"penilaianError request", func(w http.ResponseWriter, err error) { penilaianError(w, err, "tidak ada", "gagal") },
+			requestError("tidak valid"), http.StatusBadRequest},
+		{"penilaianError conflict", func(w http.ResponseWriter, err error) { penilaianError(w, err, "tidak ada", "gagal") },
+			repository.ErrConflict, http.StatusConflict},
+		{"penilaianError database mati", func(w http.ResponseWriter, err error) { penilaianError(w, err, "tidak ada", "gagal") },
+			driver.ErrBadConn, http.StatusServiceUnavailable},
+
+		{"authorize error database", func(w http.ResponseWriter, err error) { authorize(w, false, err) }, driver.ErrBadConn, http.StatusServiceUnavailable},
+		{"authorize ditolak", func(w http.ResponseWriter, err error) { authorize(w, false, err) }, nil, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.write(w, tt.err)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d (body: %s)", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+// TestHandlerDatabaseUnavailable - Error koneksi dari repository sampai ke client sebagai 503, bukan 500
+func TestHandlerDatabaseUnavailable(t *testing.T) {
+	e := newTestEnv(t)
+	e.store.SetErr(driver.ErrBadConn)
+
+	w := e.do(t, e.h.DeletePenilaianHandler, e.admin, http.MethodDelete, "/penilaian/1", map[string]string{"id": "1"}, nil)
+	expectStatus(t, w, http.StatusServiceUnavailable)
+
+	// Guru: gagal saat memeriksa hak akses
+	w = e.do(t, e.h.DeletePenilaianHandler, e.guruA, http.MethodDelete, "/penilaian/1", map[string]string{"id": "1"}, nil)
+	expectStatus(t, w, http.StatusServiceUnavailable)
+}
@@ -0,0 +1,350 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"myapp/internal/models"
+	"myapp/internal/repository"
+	"myapp/internal/spreadsheet"
+)
+
+// maxImportSize - Batas ukuran file nilai/roster yang diunggah
+const maxImportSize = 10 << 20 // 10MB
+
+// ImportNilaiHandler - Import nilai komponen satu mapel dari file CSV/XLSX (kolom NISN + satu kolom per komponen).
+// Tanpa commit=true hanya mengembalikan pratinjau perbandingan dengan nilai yang tersimpan;
+// dengan commit=true file yang sama diproses ulang lalu disimpan dalam satu transaksi. Field form
+// id_semester memilih semester, default semester aktif/terakhir tahun ajaran kelas mapel.
+func (h *Handler) ImportNilaiHandler(w http.ResponseWriter, r *http.Request) {
+	idMapel, ok := pathInt(w, r, "id", "ID mapel tidak valid")
+	if !ok {
+		return
+	}
+
+	allowed, err := h.canManageMapel(r.Context(), currentUser(r), idMapel)
+	if !authorize(w, allowed, err) {
+		return
+	}
+
+	rows, ok := readUpload(w, r)
+	if !ok {
+		return
+	}
+	commit, _ := strconv.ParseBool(r.FormValue("commit"))
+
+	var idSemester *int
+	if v := r.FormValue("id_semester"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "ID semester tidak valid", http.StatusBadRequest)
+			return
+		}
+		idSemester = &id
+	}
+	semester, err := h.mapelSemester(r.Context(), idMapel, idSemester)
+	if err != nil {
+		requestOrRepoError(w, err, "Mata pelajaran tidak ditemukan", "Gagal mengambil semester")
+		return
+	}
+
+	komponen, err := h.Repo.Komponen.ListByMapel(r.Context(), idMapel)
+	if err != nil {
+		dbError(w, err, "Gagal mengambil komponen penilaian")
+		return
+	}
+	siswaList, err := h.anggotaMapel(r.Context(), idMapel, semester.IDSemester)
+	if err != nil {
+		repoError(w, err, "Mata pelajaran tidak ditemukan", "Gagal mengambil data siswa")
+		return
+	}
+	penilaianList, err := h.Repo.Nilai.ListPenilaianByMapel(r.Context(), idMapel, semester.IDSemester)
+	if err != nil {
+		dbError(w, err, "Gagal mengambil penilaian")
+		return
+	}
+
+	preview, err := buildImportNilai(idMapel, semester.IDSemester, rows, komponen, siswaList, penilaianList)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !commit {
+		writeJSON(w, http.StatusOK, preview)
+		return
+	}
+	if preview.Ringkasan.Error > 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, preview)
+		return
+	}
+
+	err = h.Repo.InTx(r.Context(), func(repos repository.Repositories) error {
+		return applyImportNilai(r, repos, &preview)
+	})
+	if errors.Is(err, errBarisDitolak) {
+		writeJSON(w, http.StatusUnprocessableEntity, preview)
+		return
+	}
+	if err != nil {
+		log.Printf("Import nilai Error: %v\n", err)
+		penilaianError(w, err, "Nilai tidak ditemukan", "Gagal menyimpan import nilai")
+		return
+	}
+
+	preview.Tersimpan = true
+	writeJSON(w, http.StatusOK, preview)
+}
+
+// readUpload - Membaca field "file" dari form multipart. Baris judul wajib ada beserta minimal satu baris data.
+func readUpload(w http.ResponseWriter, r *http.Request) ([]spreadsheet.Row, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize+1<<20)
+	if err := r.ParseMultipartForm(maxImportSize); err != nil {
+		http.Error(w, "Gagal parsing form", http.StatusBadRequest)
+		return nil, false
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "File tidak ditemukan", http.StatusBadRequest)
+		return nil, false
+	}
+	defer file.Close()
+
+	rows, err := spreadsheet.Read(file, header.Filename)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	if len(rows) < 2 {
+		http.Error(w, "File harus berisi baris judul dan minimal satu baris data", http.StatusBadRequest)
+		return nil, false
+	}
+	return rows, true
+}
+
+// kolomNama - Judul kolom identitas yang boleh ada di file tanpa dilaporkan sebagai kolom diabaikan
+var kolomNama = []string{"no", "no.", "nama", "nama siswa", "nama_siswa", "kelas"}
+
+// buildImportNilai - Mencocokkan baris file dengan anggota kelas mapel pada semester itu (lewat NISN) dan
+// membandingkan setiap sel dengan nilai komponen yang tersimpan
+func buildImportNilai(idMapel, idSemester int, rows []spreadsheet.Row, komponen []models.KomponenPenilaian,
+	siswaList []models.Siswa, penilaianList []models.Penilaian) (models.ImportNilaiPreview, error) {
+	preview := models.ImportNilaiPreview{
+		IDMapel:        idMapel,
+		IDSemester:     idSemester,
+		Komponen:       []models.KomponenPenilaian{},
+		KolomDiabaikan: []string{},
+		Baris:          []models.ImportNilaiBaris{},
+	}
+
+	header := spreadsheet.NewHeader(rows[0].Cells)
+	nisnCol := header.Index("nisn")
+	if nisnCol < 0 {
+		return preview, errors.New("Kolom NISN tidak ditemukan di baris judul")
+	}
+
+	used := map[int]bool{nisnCol: true}
+	for _, name := range kolomNama {
+		if i := header.Index(name); i >= 0 {
+			used[i] = true
+		}
+	}
+	var cols []int
+	for _, k := range komponen {
+		if i := header.Index(k.NamaKomponen); i >= 0 && !used[i] {
+			used[i] = true
+			cols = append(cols, i)
+			preview.Komponen = append(preview.Komponen, k)
+		}
+	}
+	for i, title := range rows[0].Cells {
+		if !used[i] && title != "" {
+			preview.KolomDiabaikan = append(preview.KolomDiabaikan, title)
+		}
+	}
+	if len(cols) == 0 {
+		return preview, errors.New("Tidak ada kolom yang cocok dengan nama komponen penilaian mapel ini")
+	}
+
+	siswaByNISN := make(map[string]models.Siswa, len(siswaList))
+	for _, s := range siswaList {
+		if key := nisnKey(s.NISN); key != "" {
+			siswaByNISN[key] = s
+		}
+	}
+	type nilaiKey struct{ idSiswa, idKomponen int }
+	tersimpan := make(map[nilaiKey]int, len(penilaianList))
+	for _, p := range penilaianList {
+		if p.IDKomponen != nil {
+			tersimpan[nilaiKey{p.IDSiswa, *p.IDKomponen}] = p.Nilai
+		}
+	}
+
+	barisNISN := map[string]int{}
+	for _, row := range rows[1:] {
+		baris := models.ImportNilaiBaris{Baris: row.Line, NISN: row.Cell(nisnCol), Status: models.ImportCocok, Nilai: []models.ImportNilaiSel{}}
+		key := nisnKey(baris.NISN)
+		siswa, found := siswaByNISN[key]
+
+		switch prev, dup := barisNISN[key]; {
+		case key == "":
+			baris.Status, baris.Error = models.ImportError, "NISN kosong"
+		case dup:
+			baris.Status, baris.Error = models.ImportError, fmt.Sprintf("NISN sudah ada di baris %d", prev)
+		case !found:
+			baris.Status = models.ImportTidakCocok
+		}
+		if key != "" {
+			if _, dup := barisNISN[key]; !dup {
+				barisNISN[key] = row.Line
+			}
+		}
+
+		if found {
+			baris.IDSiswa = &siswa.IDSiswa
+			baris.NamaSiswa = siswa.NamaSiswa
+		}
+		for j, col := range cols {
+			k := preview.Komponen[j]
+			sel := models.ImportNilaiSel{IDKomponen: k.IDKomponen, NamaKomponen: k.NamaKomponen, Status: models.ImportKosong}
+			if lama, ok := tersimpan[nilaiKey{siswa.IDSiswa, k.IDKomponen}]; found && ok {
+				sel.Lama = &lama
+			}
+
+			if raw := row.Cell(col); raw != "" {
+				nilai, err := parseNilaiSel(raw, k.NilaiMaks)
+				switch {
+				case err != nil:
+					sel.Status, sel.Error = models.ImportError, err.Error()
+				case sel.Lama == nil:
+					sel.Status = models.ImportBaru
+				case *sel.Lama != nilai:
+					sel.Status = models.ImportBerubah
+				default:
+					sel.Status = models.ImportSama
+				}
+				if err == nil {
+					sel.Baru = &nilai
+				}
+			}
+			if sel.Status == models.ImportError && baris.Status == models.ImportCocok {
+				baris.Status = models.ImportError
+			}
+			baris.Nilai = append(baris.Nilai, sel)
+		}
+
+		preview.Baris = append(preview.Baris, baris)
+	}
+
+	hitungRingkasan(&preview)
+	return preview, nil
+}
+
+// applyImportNilai - Menyimpan sel baru/berubah milik baris yang cocok. Gagal bobot pada satu sel
+// ditandai di pratinjau dan membatalkan seluruh import lewat errBarisDitolak.
+func applyImportNilai(r *http.Request, repos repository.Repositories, preview *models.ImportNilaiPreview) error {
+	bobot := make([]float64, len(preview.Komponen))
+	for j, k := range preview.Komponen {
+		var err error
+		if bobot[j], err = models.ParseBobot(k.Bobot); err != nil {
+			return fmt.Errorf("bobot komponen %d: %w", k.IDKomponen, err)
+		}
+	}
+
+	// Urut id_siswa supaya urutan kunci baris nilai sama dengan lembar nilai bulk
+	urutan := make([]int, 0, len(preview.Baris))
+	for i, baris := range preview.Baris {
+		if baris.Status == models.ImportCocok {
+			urutan = append(urutan, i)
+		}
+	}
+	sort.Slice(urutan, func(a, b int) bool { return *preview.Baris[urutan[a]].IDSiswa < *preview.Baris[urutan[b]].IDSiswa })
+
+	for _, i := range urutan {
+		baris := &preview.Baris[i]
+		idNilai := 0
+		for j := range baris.Nilai {
+			sel := &baris.Nilai[j]
+			if sel.Status != models.ImportBaru && sel.Status != models.ImportBerubah {
+				continue
+			}
+			if idNilai == 0 {
+				var err error
+				if idNilai, err = repos.Nilai.FindOrCreate(r.Context(), *baris.IDSiswa, preview.IDMapel, preview.IDSemester); err != nil {
+					return fmt.Errorf("find or create nilai: %w", err)
+				}
+			}
+
+			k := preview.Komponen[j]
+			var exceeded *bobotExceededError
+			_, err := upsertPenilaian(r.Context(), repos, idNilai, k.NamaKomponen, *sel.Baru, bobot[j], &k.IDKomponen)
+			if errors.As(err, &exceeded) {
+				sel.Status, sel.Error = models.ImportError, exceeded.Error()
+				baris.Status = models.ImportError
+				continue
+			} else if err != nil {
+				return fmt.Errorf("simpan nilai siswa %d: %w", *baris.IDSiswa, err)
+			}
+		}
+		if idNilai != 0 {
+			if err := repos.Nilai.RecomputeTotal(r.Context(), idNilai); err != nil {
+				return err
+			}
+		}
+	}
+
+	hitungRingkasan(preview)
+	if preview.Ringkasan.Error > 0 {
+		return errBarisDitolak
+	}
+	return nil
+}
+
+// hitungRingkasan - Menghitung ulang ringkasan dari status baris; sel hanya dihitung untuk baris yang cocok
+func hitungRingkasan(preview *models.ImportNilaiPreview) {
+	var sum models.ImportRingkasan
+	for _, baris := range preview.Baris {
+		switch baris.Status {
+		case models.ImportTidakCocok:
+			sum.TidakCocok++
+			continue
+		case models.ImportError:
+			sum.Error++
+			continue
+		}
+		for _, sel := range baris.Nilai {
+			switch sel.Status {
+			case models.ImportBaru:
+				sum.Baru++
+			case models.ImportBerubah:
+				sum.Berubah++
+			case models.ImportSama:
+				sum.Sama++
+			}
+		}
+	}
+	preview.Ringkasan = sum
+}
+
+// nisnKey - NISN tanpa spasi dan nol di depan; Excel sering membuang nol di depan kolom angka
+func nisnKey(nisn string) string {
+	return strings.TrimLeft(strings.Join(strings.Fields(nisn), ""), "0")
+}
+
+// parseNilaiSel - Nilai di file harus bilangan bulat di antara 0 dan nilai maksimum komponen.
+// "85", "85.0" dan "85,0" diterima.
+func parseNilaiSel(raw string, nilaiMaks int) (int, error) {
+	f, err := strconv.ParseFloat(strings.Replace(raw, ",", ".", 1), 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) || f != math.Trunc(f) {
+		return 0, fmt.Errorf("nilai %q bukan bilangan bulat", raw)
+	}
+	if f < models.NilaiMin || f > float64(nilaiMaks) {
+		return 0, models.ValidateNilaiMaks(-1, nilaiMaks)
+	}
+	return int(f), nil
+}
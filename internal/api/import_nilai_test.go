@@ -0,0 +1,42 @@
+package api
+
+import "testing"
+
+func TestParseNilaiSel(t *testing.T) {
+	tests := []struct {
+		raw       string
+		nilaiMaks int
+		want      int
+		wantErr   string
+	}{
+		{"85", 100, 85, ""},
+		{"85.0", 100, 85, ""},
+		{"85,0", 100, 85, ""},
+		{"0", 100, 0, ""},
+		{"100", 100, 100, ""},
+		{"1e2", 100, 100, ""}, // angka dari Excel dalam notasi ilmiah
+		{"50", 50, 50, ""},
+
+		{"101", 100, 0, "nilai harus di antara 0 - 100"},
+		{"-1", 100, 0, "nilai harus di antara 0 - 100"},
+		{"51", 50, 0, "nilai harus di antara 0 - 50"},
+		{"85.5", 100, 0, `nilai "85.5" bukan bilangan bulat`},
+		{"85,5", 100, 0, `nilai "85,5" bukan bilangan bulat`},
+		{"1,000,0", 100, 0, `nilai "1,000,0" bukan bilangan bulat`},
+		{"delapan puluh", 100, 0, `nilai "delapan puluh" bukan bilangan bulat`},
+		{"NaN", 100, 0, `nilai "NaN" bukan bilangan bulat`},
+		{"Inf", 100, 0, `nilai "Inf" bukan bilangan bulat`},
+		{"85%", 100, 0, `nilai "85%" bukan bilangan bulat`},
+	}
+	for _, tt := range tests {
+		got, err := parseNilaiSel(tt.raw, tt.nilaiMaks)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("parseNilaiSel(%q, %d) error = %v", tt.raw, tt.nilaiMaks, err)
+		case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+			t.Errorf("parseNilaiSel(%q, %d) error = %v, want %q", tt.raw, tt.nilaiMaks, err, tt.wantErr)
+		case got != tt.want:
+			t.Errorf("parseNilaiSel(%q, %d) = %d, want %d", tt.raw, tt.nilaiMaks, got, tt.want)
+		}
+	}
+}
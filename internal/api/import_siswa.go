@@ -0,0 +1,416 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"myapp/internal/auth"
+	"myapp/internal/models"
+	"myapp/internal/repository"
+	"myapp/internal/spreadsheet"
+)
+
+// Panjang password awal dan batas panjang username yang dibuat otomatis
+const (
+	passwordAwalLen = 10
+	maxUsernameLen  = 30
+)
+
+// ImportSiswaHandler - Import roster siswa dari CSV/XLSX (nama_siswa, nisn, tanggal_lahir, alamat, kelas).
+// Kolom kelas dicocokkan dengan kelas tahun ajaran semester aktif, atau ?tahun_ajaran= (nama tahun ajaran).
+// Tanpa commit=true hanya mengembalikan hasil validasi. Dengan commit=true akun user dan data siswa
+// dibuat dalam satu transaksi, lalu responsnya berupa lembar akun CSV berisi username dan password awal.
+func (h *Handler) ImportSiswaHandler(w http.ResponseWriter, r *http.Request) {
+	rows, ok := readUpload(w, r)
+	if !ok {
+		return
+	}
+	commit, _ := strconv.ParseBool(r.FormValue("commit"))
+
+	// Tanpa tahun_ajaran, nama kelas dicocokkan di tahun ajaran semester aktif seperti tahunAjaranFilter;
+	// tahun_ajaran baru wajib jika belum ada semester aktif dan nama kelas sama di beberapa tahun ajaran
+	tahunAjaran := strings.TrimSpace(r.FormValue("tahun_ajaran"))
+	var idTahunAjaran *int
+	if tahunAjaran == "" {
+		aktif, err := h.Repo.Periode.ActiveSemester(r.Context())
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			dbError(w, err, "Gagal mengambil semester aktif")
+			return
+		}
+		if err == nil {
+			idTahunAjaran, tahunAjaran = &aktif.IDTahunAjaran, aktif.TahunAjaran
+		}
+	}
+	kelasList, err := h.Repo.Kelas.List(r.Context(), idTahunAjaran)
+	if err != nil {
+		dbError(w, err, "Gagal mengambil data kelas")
+		return
+	}
+
+	preview, err := buildImportSiswa(rows, kelasList, tahunAjaran)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// NISN yang sudah dipakai siswa lain
+	var nisn []string
+	for _, b := range preview.Baris {
+		if b.Status == models.ImportValid {
+			nisn = append(nisn, b.NISN)
+		}
+	}
+	if len(nisn) > 0 {
+		existing, err := h.Repo.Siswa.ExistingNISN(r.Context(), nisn)
+		if err != nil {
+			dbError(w, err, "Gagal memeriksa NISN")
+			return
+		}
+		terdaftar := make(map[string]string, len(existing))
+		for _, n := range existing {
+			terdaftar[nisnKey(n)] = n
+		}
+		for i := range preview.Baris {
+			b := &preview.Baris[i]
+			if n, ok := terdaftar[nisnKey(b.NISN)]; ok && b.Status == models.ImportValid {
+				b.Status, b.Error = models.ImportError, fmt.Sprintf("NISN sudah terdaftar (%s)", n)
+			}
+		}
+	}
+	hitungImportSiswa(&preview)
+
+	if !commit {
+		writeJSON(w, http.StatusOK, preview)
+		return
+	}
+	if preview.Ringkasan.Error > 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, preview)
+		return
+	}
+
+	akun, err := buatAkunSiswa(r, h.Repo, preview.Baris)
+	if err != nil {
+		log.Printf("Import siswa Error: %v\n", err)
+		repoError(w, err, "Kelas tidak ditemukan", "Gagal menyimpan import siswa")
+		return
+	}
+	log.Printf("Import roster: %d siswa ditambahkan\n", len(akun))
+
+	sheet := [][]string{{"No", "NISN", "Nama Siswa", "Kelas", "Username", "Password"}}
+	for i, a := range akun {
+		sheet = append(sheet, []string{strconv.Itoa(i + 1), a.NISN, a.NamaSiswa, a.Kelas, a.Username, a.Password})
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="akun-siswa-%s.csv"`, time.Now().Format("20060102-150405")))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	if err := spreadsheet.WriteCSV(w, sheet); err != nil {
+		log.Println("Gagal menulis lembar akun:", err)
+	}
+}
+
+// buildImportSiswa - Memvalidasi setiap baris roster dan mencocokkan kolom kelas dengan nama_kelas.
+// tahunAjaran (kosong berarti semua) membatasi pencarian kelas ke satu tahun ajaran.
+func buildImportSiswa(rows []spreadsheet.Row, kelasList []models.Kelas, tahunAjaran string) (models.ImportSiswaPreview, error) {
+	preview := models.ImportSiswaPreview{KolomDiabaikan: []string{}, Baris: []models.ImportSiswaBaris{}}
+
+	header := spreadsheet.NewHeader(rows[0].Cells)
+	col := map[string]int{
+		"nama":    header.Index("nama_siswa", "nama siswa", "nama"),
+		"nisn":    header.Index("nisn"),
+		"tanggal": header.Index("tanggal_lahir", "tanggal lahir", "tgl lahir", "tgl_lahir"),
+		"alamat":  header.Index("alamat"),
+		"kelas":   header.Index("kelas", "nama_kelas", "nama kelas"),
+	}
+	for _, name := range []string{"nama", "nisn", "tanggal"} {
+		if col[name] < 0 {
+			return preview, errors.New("Kolom wajib tidak ditemukan: nama_siswa, nisn dan tanggal_lahir harus ada di baris judul")
+		}
+	}
+	used := map[int]bool{header.Index("no", "no."): true}
+	for _, i := range col {
+		used[i] = true
+	}
+	for i, title := range rows[0].Cells {
+		if !used[i] && title != "" {
+			preview.KolomDiabaikan = append(preview.KolomDiabaikan, title)
+		}
+	}
+
+	kelasByNama := map[string][]models.Kelas{}
+	for _, k := range kelasList {
+		if tahunAjaran != "" && k.TahunAjaran != tahunAjaran {
+			continue
+		}
+		key := namaKelasKey(k.NamaKelas)
+		kelasByNama[key] = append(kelasByNama[key], k)
+	}
+
+	barisNISN := map[string]int{}
+	for _, row := range rows[1:] {
+		b := models.ImportSiswaBaris{
+			Baris:     row.Line,
+			NamaSiswa: strings.Join(strings.Fields(row.Cell(col["nama"])), " "),
+			Alamat:    row.Cell(col["alamat"]),
+			Kelas:     row.Cell(col["kelas"]),
+			Status:    models.ImportValid,
+		}
+		invalid := func(msg string) {
+			if b.Status == models.ImportValid {
+				b.Status, b.Error = models.ImportError, msg
+			}
+		}
+
+		if b.NamaSiswa == "" {
+			invalid("nama_siswa kosong")
+		}
+
+		nisn, err := normalizeNISN(row.Cell(col["nisn"]))
+		b.NISN = nisn
+		if err != nil {
+			invalid(err.Error())
+		} else if prev, dup := barisNISN[nisnKey(nisn)]; dup {
+			invalid(fmt.Sprintf("NISN sudah ada di baris %d", prev))
+		} else {
+			barisNISN[nisnKey(nisn)] = row.Line
+		}
+
+		if raw := row.Cell(col["tanggal"]); raw == "" {
+			invalid("tanggal_lahir kosong")
+		} else if t, err := spreadsheet.ParseDate(raw); err != nil {
+			b.TanggalLahir = raw
+			invalid(err.Error())
+		} else {
+			b.TanggalLahir = t.Format(models.TanggalLayout)
+		}
+
+		if b.Kelas != "" {
+			switch kelas := kelasByNama[namaKelasKey(b.Kelas)]; len(kelas) {
+			case 0:
+				if tahunAjaran != "" {
+					invalid(fmt.Sprintf("Kelas %q tidak ditemukan di tahun ajaran %s", b.Kelas, tahunAjaran))
+				} else {
+					invalid(fmt.Sprintf("Kelas %q tidak ditemukan", b.Kelas))
+				}
+			case 1:
+				b.IDKelas = &kelas[0].IDKelas
+			default:
+				invalid(fmt.Sprintf("Kelas %q ada di beberapa tahun ajaran, isi tahun_ajaran", b.Kelas))
+			}
+		}
+
+		preview.Baris = append(preview.Baris, b)
+	}
+
+	hitungImportSiswa(&preview)
+	return preview, nil
+}
+
+func hitungImportSiswa(preview *models.ImportSiswaPreview) {
+	preview.Ringkasan = models.ImportSiswaJumlah{}
+	for _, b := range preview.Baris {
+		if b.Status == models.ImportValid {
+			preview.Ringkasan.Valid++
+		} else {
+			preview.Ringkasan.Error++
+		}
+	}
+}
+
+// buatAkunSiswa - Membuat user (role siswa) dan baris siswa untuk semua baris roster dalam satu transaksi
+func buatAkunSiswa(r *http.Request, repo repository.Repositories, baris []models.ImportSiswaBaris) ([]models.AkunSiswa, error) {
+	passwords := make([]string, len(baris))
+	for i := range passwords {
+		var err error
+		if passwords[i], err = auth.GeneratePassword(passwordAwalLen); err != nil {
+			return nil, err
+		}
+	}
+	hashes, err := hashPasswords(passwords)
+	if err != nil {
+		return nil, err
+	}
+
+	today := time.Now().Format(models.TanggalLayout)
+	var akun []models.AkunSiswa
+	err = repo.InTx(r.Context(), func(repos repository.Repositories) error {
+		akun = make([]models.AkunSiswa, 0, len(baris))
+		dipakai := map[string]bool{}
+		for i, b := range baris {
+			username, err := usernameSiswa(r, repos, b.NamaSiswa, dipakai)
+			if err != nil {
+				return err
+			}
+
+			idUser, err := repos.User.Create(r.Context(), models.User{
+				IDRole:            auth.RoleSiswa,
+				Username:          username,
+				Password:          hashes[i],
+				TanggalRegistrasi: today,
+			})
+			if err != nil {
+				return fmt.Errorf("buat user baris %d: %w", b.Baris, err)
+			}
+
+			idSiswa, err := repos.Siswa.Create(r.Context(), models.Siswa{
+				IDUser:       idUser,
+				IDKelas:      b.IDKelas,
+				NamaSiswa:    b.NamaSiswa,
+				Alamat:       b.Alamat,
+				TanggalLahir: b.TanggalLahir,
+				NISN:         b.NISN,
+			})
+			if err != nil {
+				return fmt.Errorf("buat siswa baris %d: %w", b.Baris, err)
+			}
+			if err := catatKelas(r.Context(), repos, idSiswa, nil, b.IDKelas, models.RiwayatAktif, today); err != nil {
+				return err
+			}
+
+			akun = append(akun, models.AkunSiswa{
+				IDSiswa:   idSiswa,
+				NISN:      b.NISN,
+				NamaSiswa: b.NamaSiswa,
+				Kelas:     b.Kelas,
+				Username:  username,
+				Password:  passwords[i],
+			})
+		}
+		return nil
+	})
+	return akun, err
+}
+
+// hashPasswords - bcrypt sengaja lambat, jadi roster besar di-hash paralel sebelum transaksi dibuka
+func hashPasswords(passwords []string) ([]string, error) {
+	hashes := make([]string, len(passwords))
+	errs := make([]error, len(passwords))
+	next := make(chan int)
+
+	var wg sync.WaitGroup
+	for range runtime.NumCPU() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				hashes[i], errs[i] = auth.HashPassword(passwords[i])
+			}
+		}()
+	}
+	for i := range passwords {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return hashes, nil
+}
+
+// usernameSiswa - Username dari dua kata pertama nama ("Budi Santoso" → "budi.santoso"),
+// diberi angka di belakang jika sudah dipakai di database atau di file yang sama
+func usernameSiswa(r *http.Request, repos repository.Repositories, nama string, dipakai map[string]bool) (string, error) {
+	var words []string
+	for _, word := range strings.Fields(strings.ToLower(nama)) {
+		word = strings.Map(func(c rune) rune {
+			if c <= unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c)) {
+				return c
+			}
+			return -1
+		}, word)
+		if word != "" {
+			words = append(words, word)
+		}
+		if len(words) == 2 {
+			break
+		}
+	}
+	base := strings.Join(words, ".")
+	if base == "" {
+		base = "siswa"
+	}
+	if len(base) > maxUsernameLen-3 {
+		base = base[:maxUsernameLen-3]
+	}
+
+	for n := 1; ; n++ {
+		candidate := base
+		if n > 1 {
+			candidate = base + strconv.Itoa(n)
+		}
+		if dipakai[candidate] {
+			continue
+		}
+		exists, err := repos.User.UsernameExists(r.Context(), candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			dipakai[candidate] = true
+			return candidate, nil
+		}
+	}
+}
+
+// normalizeNISN - NISN 10 digit; nol di depan yang dibuang Excel dikembalikan, begitu juga NISN
+// yang tersimpan sebagai angka ("123456789.0" atau "1.23456789E+08")
+func normalizeNISN(raw string) (string, error) {
+	nisn := strings.Join(strings.Fields(raw), "")
+	if nisn == "" {
+		return "", errors.New("NISN kosong")
+	}
+	if strings.ContainsAny(nisn, ".eE") {
+		angka, err := nisnAngka(nisn)
+		if err != nil {
+			return nisn, fmt.Errorf("NISN %q %w", raw, err)
+		}
+		nisn = angka
+	}
+	for _, c := range nisn {
+		if c < '0' || c > '9' {
+			return nisn, fmt.Errorf("NISN %q harus berupa angka", raw)
+		}
+	}
+	if len(nisn) < 10 {
+		nisn = strings.Repeat("0", 10-len(nisn)) + nisn
+	}
+	if len(nisn) != 10 {
+		return nisn, fmt.Errorf("NISN %q harus 10 digit", raw)
+	}
+	return nisn, nil
+}
+
+// nisnAngka - Digit NISN dari angka desimal atau notasi ilmiah. Notasi ilmiah yang tidak memuat semua
+// digit ("1.23E+09") ditolak karena digit aslinya sudah hilang saat Excel membulatkannya.
+func nisnAngka(s string) (string, error) {
+	mantissa, exp, ilmiah := strings.Cut(strings.ToUpper(s), "E")
+	bulat, pecahan, _ := strings.Cut(mantissa, ".")
+	geser := 0
+	if ilmiah {
+		n, err := strconv.Atoi(exp)
+		if err != nil || n < 0 {
+			return "", errors.New("harus berupa angka")
+		}
+		if len(pecahan) < n {
+			return "", errors.New("tertulis dalam notasi ilmiah sehingga digitnya tidak lengkap, ubah format kolom NISN menjadi teks")
+		}
+		geser = n
+	}
+	bulat, pecahan = bulat+pecahan[:geser], pecahan[geser:]
+	if bulat == "" || strings.Trim(pecahan, "0") != "" {
+		return "", errors.New("harus berupa angka")
+	}
+	return bulat, nil
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeNISN(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr string // potongan pesan error
+	}{
+		{"0012345678", "0012345678", ""},
+		{"1234567890", "1234567890", ""},
+		{"12345678", "0012345678", ""}, // nol di depan dibuang Excel
+		{"1", "0000000001", ""},
+		{" 00 1234 5678 ", "0012345678", ""},
+		{"1234567890.0", "1234567890", ""},
+		{"12345678.00", "0012345678", ""},
+		{"1.23456789E+08", "0123456789", ""},
+		{"1.234567890E+09", "1234567890", ""},
+		{"1.23456789e8", "0123456789", ""},
+		{"1.2345678900E+09", "1234567890", ""},
+
+		{"", "", "NISN kosong"},
+		{"   ", "", "NISN kosong"},
+		{"1.23E+09", "", "notasi ilmiah"},
+		{"1.2345678E+09", "", "notasi ilmiah"},
+		{"1234567890.5", "", "harus berupa angka"},
+		{"1.2345678905E+09", "", "harus berupa angka"},
+		{"1.5E-01", "", "harus berupa angka"},
+		{".0", "", "harus berupa angka"},
+		{"12345-6789", "", "harus berupa angka"},
+		{"NISN123", "", "harus berupa angka"},
+		{"-1234567890", "", "harus berupa angka"},
+		{"12345678901", "", "harus 10 digit"},
+	}
+	for _, tt := range tests {
+		got, err := normalizeNISN(tt.raw)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("normalizeNISN(%q) error = %v", tt.raw, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("normalizeNISN(%q) = %q, %v, want error %q", tt.raw, got, err, tt.wantErr)
+		case tt.wantErr == "" && got != tt.want:
+			t.Errorf("normalizeNISN(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
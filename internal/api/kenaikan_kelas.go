@@ -0,0 +1,243 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"myapp/internal/models"
+	"myapp/internal/repository"
+)
+
+// KenaikanKelasHandler - Kenaikan kelas akhir tahun: semua siswa kelas {id} dipindah ke kelas tingkat
+// berikutnya di tahun ajaran baru, kecuali siswa di tinggal_kelas yang mengulang tingkat yang sama.
+// Tanpa ?commit=true hanya mengembalikan rencananya; dengan commit=true kelas tujuan yang belum ada
+// dibuat, siswa dipindah dan riwayat kelas dicatat dalam satu transaksi.
+func (h *Handler) KenaikanKelasHandler(w http.ResponseWriter, r *http.Request) {
+	idKelas, ok := pathInt(w, r, "id", "ID kelas tidak valid")
+	if !ok {
+		return
+	}
+
+	var input models.KenaikanKelasInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	commit, _ := strconv.ParseBool(r.URL.Query().Get("commit"))
+
+	rencana, err := h.rencanaKenaikan(r.Context(), idKelas, input)
+	if err != nil {
+		requestOrRepoError(w, err, "Kelas tidak ditemukan", "Gagal menyusun kenaikan kelas")
+		return
+	}
+	if !commit {
+		writeJSON(w, http.StatusOK, rencana)
+		return
+	}
+
+	err = h.Repo.InTx(r.Context(), func(repos repository.Repositories) error {
+		return simpanKenaikan(r.Context(), repos, &rencana)
+	})
+	if err != nil {
+		log.Printf("Kenaikan kelas %d error: %v\n", idKelas, err)
+		repoError(w, err, "Kelas tidak ditemukan", "Gagal menyimpan kenaikan kelas")
+		return
+	}
+
+	log.Printf("Kenaikan kelas %s %s: %d naik, %d tinggal kelas\n",
+		rencana.KelasAsal.NamaKelas, rencana.KelasAsal.TahunAjaran, rencana.JumlahNaik, rencana.JumlahTinggal)
+	writeJSON(w, http.StatusOK, rencana)
+}
+
+// rencanaKenaikan - Menentukan tahun ajaran tujuan dan kelas tujuan setiap siswa. Kesalahan input
+// dikembalikan sebagai requestError.
+func (h *Handler) rencanaKenaikan(ctx context.Context, idKelas int, input models.KenaikanKelasInput) (models.KenaikanKelas, error) {
+	var rencana models.KenaikanKelas
+	asal, err := h.Repo.Kelas.GetByID(ctx, idKelas)
+	if err != nil {
+		return rencana, err
+	}
+	siswaList, err := h.Repo.Siswa.ListByKelas(ctx, idKelas, hanyaAktif())
+	if err != nil {
+		return rencana, err
+	}
+	if len(siswaList) == 0 {
+		return rencana, requestError("Kelas belum punya siswa aktif")
+	}
+
+	tanggal := time.Now().Format(models.TanggalLayout)
+	if input.Tanggal != "" {
+		t, err := time.Parse(models.TanggalLayout, strings.TrimSpace(input.Tanggal))
+		if err != nil {
+			return rencana, requestError("tanggal harus berformat YYYY-MM-DD")
+		}
+		tanggal = t.Format(models.TanggalLayout)
+	}
+
+	// Tahun ajaran tujuan harus sudah dibuat dan setelah tahun ajaran kelas asal
+	var tujuan models.TahunAjaran
+	if input.IDTahunAjaran != 0 {
+		tujuan, err = h.Repo.Periode.GetTahunAjaran(ctx, input.IDTahunAjaran)
+	} else if nama, ok := models.TahunAjaranBerikutnya(asal.TahunAjaran); ok {
+		tujuan, err = h.Repo.Periode.FindTahunAjaran(ctx, nama)
+	} else {
+		return rencana, requestError("Tahun ajaran berikutnya tidak bisa ditentukan, isi id_tahun_ajaran")
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return rencana, requestError("Tahun ajaran tujuan belum dibuat")
+	}
+	if err != nil {
+		return rencana, err
+	}
+	if tujuan.Nama <= asal.TahunAjaran {
+		return rencana, requestError(fmt.Sprintf("Tahun ajaran tujuan harus setelah %s", asal.TahunAjaran))
+	}
+
+	// Kelas tujuan dicari lewat id atau nama di tahun ajaran tujuan; yang belum ada direncanakan dibuat
+	kelasTujuan, err := h.Repo.Kelas.List(ctx, &tujuan.IDTahunAjaran)
+	if err != nil {
+		return rencana, err
+	}
+	byNama := make(map[string]models.Kelas, len(kelasTujuan))
+	for _, k := range kelasTujuan {
+		byNama[namaKelasKey(k.NamaKelas)] = k
+	}
+	kelasByID := func(id int) (models.Kelas, error) {
+		k, err := h.Repo.Kelas.GetByID(ctx, id)
+		if errors.Is(err, repository.ErrNotFound) {
+			return k, requestError(fmt.Sprintf("Kelas %d tidak ditemukan", id))
+		}
+		if err == nil && k.IDTahunAjaran != tujuan.IDTahunAjaran {
+			return k, requestError(fmt.Sprintf("Kelas %s bukan kelas tahun ajaran %s", k.NamaKelas, tujuan.Nama))
+		}
+		return k, err
+	}
+	kelasByNama := func(nama string) models.Kelas {
+		if k, ok := byNama[namaKelasKey(nama)]; ok {
+			return k
+		}
+		return models.Kelas{
+			IDGuru:        asal.IDGuru,
+			NamaKelas:     nama,
+			IDTahunAjaran: tujuan.IDTahunAjaran,
+			TahunAjaran:   tujuan.Nama,
+		}
+	}
+
+	var naik models.Kelas
+	if input.IDKelasTujuan != 0 {
+		if naik, err = kelasByID(input.IDKelasTujuan); err != nil {
+			return rencana, err
+		}
+	} else if nama, ok := models.NamaKelasBerikutnya(asal.NamaKelas); ok {
+		naik = kelasByNama(nama)
+	} else {
+		return rencana, requestError(fmt.Sprintf("Tingkat berikutnya kelas %s tidak bisa ditentukan, isi id_kelas_tujuan", asal.NamaKelas))
+	}
+
+	diKelas := make(map[int]bool, len(siswaList))
+	for _, s := range siswaList {
+		diKelas[s.IDSiswa] = true
+	}
+	tinggal := make(map[int]models.Kelas, len(input.TinggalKelas))
+	for _, t := range input.TinggalKelas {
+		if !diKelas[t.IDSiswa] {
+			return rencana, requestError(fmt.Sprintf("Siswa %d bukan siswa kelas %s", t.IDSiswa, asal.NamaKelas))
+		}
+		if _, dup := tinggal[t.IDSiswa]; dup {
+			return rencana, requestError(fmt.Sprintf("Siswa %d muncul lebih dari sekali di tinggal_kelas", t.IDSiswa))
+		}
+		k := kelasByNama(asal.NamaKelas)
+		if t.IDKelas != 0 {
+			if k, err = kelasByID(t.IDKelas); err != nil {
+				return rencana, err
+			}
+		}
+		tinggal[t.IDSiswa] = k
+	}
+
+	rencana = models.KenaikanKelas{
+		KelasAsal:     asal,
+		IDTahunAjaran: tujuan.IDTahunAjaran,
+		TahunAjaran:   tujuan.Nama,
+		Tanggal:       tanggal,
+		KelasTujuan:   naik,
+		Siswa:         make([]models.KenaikanSiswa, 0, len(siswaList)),
+	}
+	for _, s := range siswaList {
+		status, k := models.RiwayatNaik, naik
+		if t, ok := tinggal[s.IDSiswa]; ok {
+			status, k = models.RiwayatTinggal, t
+			rencana.JumlahTinggal++
+		} else {
+			rencana.JumlahNaik++
+		}
+		rencana.Siswa = append(rencana.Siswa, models.KenaikanSiswa{
+			IDSiswa:   s.IDSiswa,
+			NISN:      s.NISN,
+			NamaSiswa: s.NamaSiswa,
+			Status:    status,
+			IDKelas:   k.IDKelas,
+			NamaKelas: k.NamaKelas,
+		})
+	}
+	return rencana, nil
+}
+
+// simpanKenaikan - Membuat kelas tujuan yang belum ada lalu memindahkan siswa satu per satu
+// (urut id_siswa) sambil menutup riwayat kelas asal dengan status naik/tinggal
+func simpanKenaikan(ctx context.Context, repos repository.Repositories, rencana *models.KenaikanKelas) error {
+	dibuat := map[string]int{}
+	for i := range rencana.Siswa {
+		s := &rencana.Siswa[i]
+		if s.IDKelas != 0 {
+			continue
+		}
+		key := namaKelasKey(s.NamaKelas)
+		if _, ok := dibuat[key]; !ok {
+			id, err := repos.Kelas.Create(ctx, models.Kelas{
+				IDGuru:        rencana.KelasAsal.IDGuru,
+				NamaKelas:     s.NamaKelas,
+				IDTahunAjaran: rencana.IDTahunAjaran,
+			})
+			if err != nil {
+				return fmt.Errorf("buat kelas %s: %w", s.NamaKelas, err)
+			}
+			dibuat[key] = id
+		}
+		s.IDKelas = dibuat[key]
+	}
+	if rencana.KelasTujuan.IDKelas == 0 {
+		rencana.KelasTujuan.IDKelas = dibuat[namaKelasKey(rencana.KelasTujuan.NamaKelas)]
+	}
+
+	urutan := make([]int, len(rencana.Siswa))
+	for i := range urutan {
+		urutan[i] = i
+	}
+	sort.Slice(urutan, func(a, b int) bool { return rencana.Siswa[urutan[a]].IDSiswa < rencana.Siswa[urutan[b]].IDSiswa })
+
+	asal := rencana.KelasAsal.IDKelas
+	for _, i := range urutan {
+		s := rencana.Siswa[i]
+		siswa := models.Siswa{IDSiswa: s.IDSiswa, IDKelas: &asal}
+		if err := pindahKelas(ctx, repos, siswa, s.IDKelas, s.Status, rencana.Tanggal); err != nil {
+			return err
+		}
+	}
+	rencana.Tersimpan = true
+	return nil
+}
+
+// namaKelasKey - Nama kelas untuk dibandingkan: huruf kecil dengan spasi dirapikan
+func namaKelasKey(nama string) string {
+	return strings.ToLower(strings.Join(strings.Fields(nama), " "))
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"myapp/internal/models"
+	"myapp/internal/repository"
+)
+
+// GetKomponenByMapelHandler - Daftar komponen penilaian satu mata pelajaran
+func (h *Handler) GetKomponenByMapelHandler(w http.ResponseWriter, r *http.Request) {
+	idMapel, ok := pathInt(w, r, "id", "ID mapel tidak valid")
+	if !ok {
+		return
+	}
+
+	komponen, err := h.Repo.Komponen.ListByMapel(r.Context(), idMapel)
+	if err != nil {
+		dbError(w, err, "Gagal mengambil komponen penilaian")
+		return
+	}
+	if komponen == nil {
+		komponen = []models.KomponenPenilaian{}
+	}
+
+	writeJSON(w, http.StatusOK, komponen)
+}
+
+// CreateKomponenHandler - Menambahkan komponen penilaian ke sebuah mapel
+func (h *Handler) CreateKomponenHandler(w http.ResponseWriter, r *http.Request) {
+	idMapel, ok := pathInt(w, r, "id", "ID mapel tidak valid")
+	if !ok {
+		return
+	}
+
+	allowed, err := h.canManageMapel(r.Context(), currentUser(r), idMapel)
+	if !authorize(w, allowed, err) {
+		return
+	}
+
+	var komponen models.KomponenPenilaian
+	if err := json.NewDecoder(r.Body).Decode(&komponen); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	komponen.IDMapel = idMapel
+
+	bobot, err := komponen.Validate()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.Repo.InTx(r.Context(), func(repos repository.Repositories) error {
+		if err := repos.MataPelajaran.Lock(r.Context(), idMapel); err != nil {
+			return err
+		}
+		if err := checkBobotKomponen(r, repos, idMapel, 0, bobot); err != nil {
+			return err
+		}
+		komponen.IDKomponen, err = repos.Komponen.Create(r.Context(), komponen, bobot)
+		return err
+	})
+	if err != nil {
+		log.Println("Create komponen error:", err)
+		penilaianError(w, err, "Mata Pelajaran not found", "Gagal menyimpan komponen penilaian")
+		return
+	}
+
+	komponen.Bobot = models.FormatBobot(bobot)
+	writeJSON(w, http.StatusCreated, komponen)
+}
+
+// UpdateKomponenHandler - Mengubah komponen penilaian; nama dan bobot ikut berubah di semua nilai siswa
+func (h *Handler) UpdateKomponenHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID komponen tidak valid")
+	if !ok {
+		return
+	}
+
+	existing, ok := h.komponenForManage(w, r, id)
+	if !ok {
+		return
+	}
+
+	var komponen models.KomponenPenilaian
+	if err := json.NewDecoder(r.Body).Decode(&komponen); err != nil {
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+	komponen.IDKomponen = id
+	komponen.IDMapel = existing.IDMapel
+
+	bobot, err := komponen.Validate()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.Repo.InTx(r.Context(), func(repos repository.Repositories) error {
+		if err := repos.MataPelajaran.Lock(r.Context(), existing.IDMapel); err != nil {
+			return err
+		}
+		if err := checkBobotKomponen(r, repos, existing.IDMapel, id, bobot); err != nil {
+			return err
+		}
+		maxNilai, err := repos.Komponen.MaxNilai(r.Context(), id)
+		if err != nil {
+			return err
+		}
+		if maxNilai > komponen.NilaiMaks {
+			return requestError(fmt.Sprintf("nilai_maks tidak boleh di bawah nilai yang sudah diberikan (%d)", maxNilai))
+		}
+		if err := repos.Komponen.Update(r.Context(), id, komponen, bobot); err != nil {
+			return err
+		}
+		if err := repos.Nilai.SyncKomponen(r.Context(), id, komponen.NamaKomponen, bobot); err != nil {
+			return err
+		}
+
+		// Bobot baru juga harus muat bersama penilaian lepas milik setiap siswa
+		maxSum, err := repos.Nilai.MaxSumBobotByMapel(r.Context(), existing.IDMapel)
+		if err != nil {
+			return err
+		}
+		if models.BobotPoints(maxSum) > models.BobotPoints(models.BobotMax) {
+			return &bobotExceededError{sisa: sisaBobot(maxSum - bobot)}
+		}
+		return repos.Nilai.RecomputeByMapel(r.Context(), existing.IDMapel)
+	})
+	if err != nil {
+		log.Println("Update komponen error:", err)
+		penilaianError(w, err, "Komponen tidak ditemukan", "Gagal mengubah komponen penilaian")
+		return
+	}
+
+	komponen.Bobot = models.FormatBobot(bobot)
+	writeJSON(w, http.StatusOK, komponen)
+}
+
+// DeleteKomponenHandler - Menghapus komponen penilaian beserta nilai siswa untuk komponen tersebut
+func (h *Handler) DeleteKomponenHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID komponen tidak valid")
+	if !ok {
+		return
+	}
+
+	existing, ok := h.komponenForManage(w, r, id)
+	if !ok {
+		return
+	}
+
+	err := h.Repo.InTx(r.Context(), func(repos repository.Repositories) error {
+		if err := repos.Komponen.Delete(r.Context(), id); err != nil {
+			return err
+		}
+		return repos.Nilai.RecomputeByMapel(r.Context(), existing.IDMapel)
+	})
+	if err != nil {
+		repoError(w, err, "Komponen tidak ditemukan", "Gagal menghapus komponen penilaian")
+		return
+	}
+
+	log.Println("Komponen penilaian successfully deleted with ID:", id)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Komponen penilaian berhasil dihapus"))
+}
+
+// komponenForManage - Mengambil komponen dan memastikan user boleh mengelola mapel-nya
+func (h *Handler) komponenForManage(w http.ResponseWriter, r *http.Request, id int) (models.KomponenPenilaian, bool) {
+	komponen, err := h.Repo.Komponen.GetByID(r.Context(), id)
+	if err != nil {
+		repoError(w, err, "Komponen tidak ditemukan", "Gagal mengambil komponen penilaian")
+		return komponen, false
+	}
+
+	allowed, err := h.canManageMapel(r.Context(), currentUser(r), komponen.IDMapel)
+	return komponen, authorize(w, allowed, err)
+}
+
+// checkBobotKomponen - Total bobot komponen satu mapel tidak boleh melebihi 100%. Baris mapel harus sudah terkunci.
+func checkBobotKomponen(r *http.Request, repos repository.Repositories, idMapel, exceptKomponen int, bobot float64) error {
+	terpakai, err := repos.Komponen.SumBobot(r.Context(), idMapel, exceptKomponen)
+	if err != nil {
+		return err
+	}
+	if models.BobotPoints(terpakai)+models.BobotPoints(bobot) > models.BobotPoints(models.BobotMax) {
+		return &bobotExceededError{sisa: sisaBobot(terpakai)}
+	}
+	return nil
+}
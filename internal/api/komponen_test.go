@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"myapp/internal/auth"
+	"myapp/internal/models"
+)
+
+func TestKomponenHandlers(t *testing.T) {
+	create := func(t *testing.T, e *testEnv, user *auth.Claims, k models.KomponenPenilaian, want int) int {
+		t.Helper()
+		w := e.do(t, e.h.CreateKomponenHandler, user, http.MethodPost, "/mapel/x/komponen",
+			map[string]string{"id": strconv.Itoa(e.idMapelA)}, k)
+		expectStatus(t, w, want)
+		var created models.KomponenPenilaian
+		if want == http.StatusCreated {
+			if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return created.IDKomponen
+	}
+	update := func(t *testing.T, e *testEnv, id int, k models.KomponenPenilaian, want int) {
+		t.Helper()
+		w := e.do(t, e.h.UpdateKomponenHandler, e.guruA, http.MethodPut, "/komponen/x",
+			map[string]string{"id": strconv.Itoa(id)}, k)
+		expectStatus(t, w, want)
+	}
+	penilaian := func(t *testing.T, e *testEnv, idKomponen, nilai, want int) {
+		t.Helper()
+		w := e.do(t, e.h.CreatePenilaianHandler, e.guruA, http.MethodPost, "/penilaian", nil,
+			models.Penilaian{IDMapel: e.idMapelA, IDSiswa: e.idSiswaA, Nilai: nilai, IDKomponen: &idKomponen})
+		expectStatus(t, w, want)
+	}
+
+	t.Run("total bobot paling banyak 100%", func(t *testing.T) {
+		e := newTestEnv(t)
+		uts := create(t, e, e.guruA, models.KomponenPenilaian{NamaKomponen: "UTS", Bobot: "30%"}, http.StatusCreated)
+		create(t, e, e.guruA, models.KomponenPenilaian{NamaKomponen: "UAS", Bobot: "70%"}, http.StatusCreated)
+		create(t, e, e.guruA, models.KomponenPenilaian{NamaKomponen: "Kuis", Bobot: "0.01%"}, http.StatusBadRequest)
+		create(t, e, e.guruA, models.KomponenPenilaian{NamaKomponen: "Kuis", Bobot: "101%"}, http.StatusBadRequest)
+		create(t, e, e.guruB, models.KomponenPenilaian{NamaKomponen: "Kuis", Bobot: "10%"}, http.StatusForbidden)
+
+		// Bobot komponen yang diubah tidak dihitung dua kali
+		update(t, e, uts, models.KomponenPenilaian{NamaKomponen: "UTS", Bobot: "30%"}, http.StatusOK)
+		update(t, e, uts, models.KomponenPenilaian{NamaKomponen: "UTS", Bobot: "30.01%"}, http.StatusBadRequest)
+		update(t, e, uts, models.KomponenPenilaian{NamaKomponen: "UTS", Bobot: "20%"}, http.StatusOK)
+		create(t, e, e.guruA, models.KomponenPenilaian{NamaKomponen: "Kuis", Bobot: "10%"}, http.StatusCreated)
+		create(t, e, e.guruA, models.KomponenPenilaian{NamaKomponen: "Tugas", Bobot: "0.01%"}, http.StatusBadRequest)
+	})
+
+	t.Run("nilai_maks", func(t *testing.T) {
+		e := newTestEnv(t)
+		tugas := create(t, e, e.guruA, models.KomponenPenilaian{NamaKomponen: "Tugas", Bobot: "30%", NilaiMaks: 50}, http.StatusCreated)
+		create(t, e, e.guruA, models.KomponenPenilaian{NamaKomponen: "Kuis", Bobot: "10%", NilaiMaks: -5}, http.StatusBadRequest)
+
+		penilaian(t, e, tugas, 51, http.StatusBadRequest)
+		penilaian(t, e, tugas, 45, http.StatusCreated)
+
+		idSemester := e.idSemester
+		w := e.do(t, e.h.CreatePenilaianBulkHandler, e.guruA, http.MethodPost, "/penilaian/bulk", nil, models.PenilaianBulk{
+			IDMapel: e.idMapelA, IDKomponen: &tugas, IDSemester: &idSemester,
+			Nilai: []models.NilaiSiswa{{IDSiswa: e.idSiswaA, Nilai: 60}},
+		})
+		expectStatus(t, w, http.StatusUnprocessableEntity)
+
+		// nilai_maks tidak boleh turun di bawah nilai yang sudah diberikan
+		update(t, e, tugas, models.KomponenPenilaian{NamaKomponen: "Tugas", Bobot: "30%", NilaiMaks: 40}, http.StatusBadRequest)
+		update(t, e, tugas, models.KomponenPenilaian{NamaKomponen: "Tugas", Bobot: "30%", NilaiMaks: 45}, http.StatusOK)
+		if got := e.totalNilai(t, e.idSiswaA, e.idMapelA); got != 30 {
+			t.Errorf("total_nilai = %v, want 30", got)
+		}
+	})
+
+	t.Run("bobot komponen bersama penilaian lepas", func(t *testing.T) {
+		e := newTestEnv(t)
+		tugas := create(t, e, e.guruA, models.KomponenPenilaian{NamaKomponen: "Tugas", Bobot: "30%"}, http.StatusCreated)
+		penilaian(t, e, tugas, 80, http.StatusCreated)
+		w := e.do(t, e.h.CreatePenilaianHandler, e.guruA, http.MethodPost, "/penilaian", nil,
+			models.Penilaian{IDMapel: e.idMapelA, IDSiswa: e.idSiswaA, NamaNilai: "Proyek", Nilai: 90, Bobot: "70%"})
+		expectStatus(t, w, http.StatusCreated)
+
+		// Total komponen masih 31%, tapi nilai siswa A menjadi 101%
+		update(t, e, tugas, models.KomponenPenilaian{NamaKomponen: "Tugas", Bobot: "31%"}, http.StatusBadRequest)
+	})
+}
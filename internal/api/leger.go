@@ -0,0 +1,244 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"myapp/internal/models"
+	"myapp/internal/spreadsheet"
+)
+
+// GetLegerHandler - Leger nilai satu kelas: satu baris per siswa, satu kolom total_nilai per mapel,
+// ditambah rata-rata, peringkat, jumlah sakit/izin/alpa dan keterangan lulus (KKM masing-masing mapel).
+// ?format=xlsx (default), csv atau json; ?id_semester= default semester aktif/terakhir tahun ajaran kelas.
+func (h *Handler) GetLegerHandler(w http.ResponseWriter, r *http.Request) {
+	idKelas, ok := pathInt(w, r, "id", "ID kelas tidak valid")
+	if !ok {
+		return
+	}
+
+	allowed, err := h.canManageKelas(r.Context(), currentUser(r), idKelas)
+	if !authorize(w, allowed, err) {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "":
+		format = "xlsx"
+	case "xlsx", "csv", "json":
+	default:
+		http.Error(w, "format harus xlsx, csv atau json", http.StatusBadRequest)
+		return
+	}
+
+	kelas, err := h.Repo.Kelas.GetByID(r.Context(), idKelas)
+	if err != nil {
+		repoError(w, err, "Kelas tidak ditemukan", "Gagal mengambil kelas")
+		return
+	}
+	semester, ok := h.semesterQuery(w, r, kelas.IDTahunAjaran)
+	if !ok {
+		return
+	}
+
+	leger, err := h.buildLeger(r.Context(), kelas, semester)
+	if err != nil {
+		repoError(w, err, "Kelas tidak ditemukan", "Gagal membuat leger nilai")
+		return
+	}
+
+	if format == "json" {
+		writeJSON(w, http.StatusOK, leger)
+		return
+	}
+
+	var buf bytes.Buffer
+	contentType := "text/csv; charset=utf-8"
+	if format == "xlsx" {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		err = spreadsheet.WriteXLSX(&buf, "Leger", legerSheet(leger))
+	} else {
+		err = spreadsheet.WriteCSV(&buf, legerCSV(leger))
+	}
+	if err != nil {
+		log.Println("Tulis leger error:", err)
+		http.Error(w, "Gagal membuat file leger", http.StatusInternalServerError)
+		return
+	}
+
+	filename := safeFilename(fmt.Sprintf("leger-%s-%s-semester%d.%s", kelas.NamaKelas, kelas.TahunAjaran, semester.Semester, format))
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// buildLeger - Menyusun matriks siswa × mapel dari tabel nilai lalu menghitung rata-rata, peringkat dan kelulusan.
+// Barisnya anggota kelas pada semester tersebut (Siswa.ListAnggotaKelas), termasuk yang sudah naik atau pindah.
+func (h *Handler) buildLeger(ctx context.Context, kelas models.Kelas, semester models.Semester) (models.Leger, error) {
+	idKelas := kelas.IDKelas
+	mapel, err := h.Repo.MataPelajaran.ListByKelas(ctx, idKelas)
+	if err != nil {
+		return models.Leger{}, err
+	}
+	siswaList, err := h.Repo.Siswa.ListAnggotaKelas(ctx, idKelas, semester.IDSemester)
+	if err != nil {
+		return models.Leger{}, err
+	}
+	nilaiList, err := h.Repo.Nilai.ListByKelas(ctx, idKelas, semester.IDSemester)
+	if err != nil {
+		return models.Leger{}, err
+	}
+	absensi, err := h.Repo.Absensi.Rekap(ctx, idKelas, nil, semester.TanggalMulai, semester.TanggalSelesai)
+	if err != nil {
+		return models.Leger{}, err
+	}
+
+	kolom := make(map[int]int, len(mapel))
+	for i, m := range mapel {
+		kolom[m.IDMapel] = i
+	}
+	baris := make(map[int]int, len(siswaList))
+	leger := models.Leger{Kelas: kelas, Semester: semester, Mapel: mapel, Baris: make([]models.LegerBaris, len(siswaList))}
+	for i, s := range siswaList {
+		baris[s.IDSiswa] = i
+		leger.Baris[i] = models.LegerBaris{
+			IDSiswa:   s.IDSiswa,
+			NISN:      s.NISN,
+			NamaSiswa: s.NamaSiswa,
+			Nilai:     make([]*float64, len(mapel)),
+		}
+	}
+	for _, n := range nilaiList {
+		i, ok := baris[n.IDSiswa]
+		if !ok {
+			continue
+		}
+		total := n.TotalNilai
+		leger.Baris[i].Nilai[kolom[n.IDMapel]] = &total
+	}
+
+	for i := range leger.Baris {
+		b := &leger.Baris[i]
+		var sum float64
+		dinilai := 0
+		for _, v := range b.Nilai {
+			if v != nil {
+				sum += *v
+				dinilai++
+			}
+		}
+		if dinilai > 0 {
+			rata := math.Round(sum/float64(dinilai)*100) / 100
+			b.RataRata = &rata
+		}
+		b.Lulus = len(mapel) > 0 && dinilai == len(mapel)
+		for j, v := range b.Nilai {
+			if v != nil && *v < mapel[j].KKM {
+				b.Lulus = false
+			}
+		}
+	}
+	for _, a := range absensi {
+		if i, ok := baris[a.IDSiswa]; ok {
+			leger.Baris[i].Absensi = a.RekapAbsensi
+		}
+	}
+	rankLeger(leger.Baris)
+
+	if leger.Mapel == nil {
+		leger.Mapel = []models.MataPelajaran{}
+	}
+	return leger, nil
+}
+
+// rankLeger - Peringkat berdasarkan rata-rata tertinggi; rata-rata sama mendapat peringkat sama dan
+// peringkat berikutnya tidak dilompati (1, 2, 2, 3), sama dengan Nilai.ListPeringkat
+func rankLeger(baris []models.LegerBaris) {
+	urut := make([]*models.LegerBaris, 0, len(baris))
+	for i := range baris {
+		if baris[i].RataRata != nil {
+			urut = append(urut, &baris[i])
+		}
+	}
+	sort.SliceStable(urut, func(i, j int) bool { return *urut[i].RataRata > *urut[j].RataRata })
+	for i, b := range urut {
+		switch {
+		case i == 0:
+			b.Peringkat = 1
+		case *b.RataRata == *urut[i-1].RataRata:
+			b.Peringkat = urut[i-1].Peringkat
+		default:
+			b.Peringkat = urut[i-1].Peringkat + 1
+		}
+	}
+}
+
+// legerHeader - Baris judul kolom leger
+func legerHeader(leger models.Leger) []string {
+	header := []string{"No", "NISN", "Nama Siswa"}
+	for _, m := range leger.Mapel {
+		header = append(header, m.NamaMataPelajaran)
+	}
+	return append(header, "Rata-rata", "Peringkat", "Sakit", "Izin", "Alpa", "Keterangan")
+}
+
+// legerSheet - Leger sebagai sel XLSX; nilai tetap angka, sel kosong untuk yang belum dinilai
+func legerSheet(leger models.Leger) [][]any {
+	header := legerHeader(leger)
+	rows := [][]any{make([]any, len(header))}
+	for i, h := range header {
+		rows[0][i] = h
+	}
+	for i, b := range leger.Baris {
+		row := []any{i + 1, b.NISN, b.NamaSiswa}
+		for _, v := range b.Nilai {
+			row = append(row, numberCell(v))
+		}
+		var peringkat any = ""
+		if b.Peringkat > 0 {
+			peringkat = b.Peringkat
+		}
+		rows = append(rows, append(row, numberCell(b.RataRata), peringkat,
+			b.Absensi.Sakit, b.Absensi.Izin, b.Absensi.Alpa, keteranganLulus(b.Lulus)))
+	}
+	return rows
+}
+
+// legerCSV - Leger sebagai teks CSV
+func legerCSV(leger models.Leger) [][]string {
+	rows := [][]string{legerHeader(leger)}
+	for _, row := range legerSheet(leger)[1:] {
+		cells := make([]string, len(row))
+		for i, c := range row {
+			if f, ok := c.(float64); ok {
+				cells[i] = strconv.FormatFloat(f, 'f', 2, 64)
+				continue
+			}
+			cells[i] = fmt.Sprint(c)
+		}
+		rows = append(rows, cells)
+	}
+	return rows
+}
+
+func numberCell(v *float64) any {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+func keteranganLulus(lulus bool) string {
+	if lulus {
+		return "Lulus"
+	}
+	return "Tidak Lulus"
+}
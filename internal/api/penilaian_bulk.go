@@ -0,0 +1,192 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"myapp/internal/models"
+	"myapp/internal/repository"
+)
+
+// errBarisDitolak - Membatalkan transaksi lembar nilai jika ada baris yang gagal
+var errBarisDitolak = errors.New("lembar nilai ditolak")
+
+// CreatePenilaianBulkHandler - Menyimpan satu penilaian untuk banyak siswa sekaligus.
+// Semua baris divalidasi dulu dan disimpan dalam satu transaksi: satu baris gagal berarti tidak ada yang tersimpan.
+func (h *Handler) CreatePenilaianBulkHandler(w http.ResponseWriter, r *http.Request) {
+	var sheet models.PenilaianBulk
+	if err := json.NewDecoder(r.Body).Decode(&sheet); err != nil {
+		http.Error(w, "Gagal membaca data dari body", http.StatusBadRequest)
+		return
+	}
+	if len(sheet.Nilai) == 0 {
+		http.Error(w, "Lembar nilai kosong", http.StatusBadRequest)
+		return
+	}
+
+	allowed, err := h.canManageMapel(r.Context(), currentUser(r), sheet.IDMapel)
+	if !authorize(w, allowed, err) {
+		return
+	}
+
+	// Step 1: Nama, bobot dan nilai maksimum berlaku untuk semua baris
+	nilaiMaks := models.NilaiMax
+	if sheet.IDKomponen != nil {
+		komponen, err := h.Repo.Komponen.GetByID(r.Context(), *sheet.IDKomponen)
+		if err != nil {
+			repoError(w, err, "Komponen tidak ditemukan", "Gagal mengambil komponen penilaian")
+			return
+		}
+		if komponen.IDMapel != sheet.IDMapel {
+			http.Error(w, "Komponen bukan milik mata pelajaran ini", http.StatusBadRequest)
+			return
+		}
+		sheet.NamaNilai = komponen.NamaKomponen
+		sheet.Bobot = komponen.Bobot
+		nilaiMaks = komponen.NilaiMaks
+	}
+	sheet.NamaNilai = strings.TrimSpace(sheet.NamaNilai)
+	if sheet.NamaNilai == "" {
+		http.Error(w, "nama_nilai wajib diisi", http.StatusBadRequest)
+		return
+	}
+	bobot, err := models.ParseBobot(sheet.Bobot)
+	if err != nil {
+		http.Error(w, "Format bobot tidak valid", http.StatusBadRequest)
+		return
+	}
+	if err := models.ValidateBobot(bobot); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	semester, err := h.mapelSemester(r.Context(), sheet.IDMapel, sheet.IDSemester)
+	if err != nil {
+		requestOrRepoError(w, err, "Mata pelajaran tidak ditemukan", "Gagal mengambil semester")
+		return
+	}
+
+	// Step 2: Validasi setiap baris terhadap anggota kelas mapel pada semester tersebut
+	siswaList, err := h.anggotaMapel(r.Context(), sheet.IDMapel, semester.IDSemester)
+	if err != nil {
+		repoError(w, err, "Mata pelajaran tidak ditemukan", "Gagal mengambil data siswa")
+		return
+	}
+	diKelas := make(map[int]bool, len(siswaList))
+	for _, s := range siswaList {
+		diKelas[s.IDSiswa] = true
+	}
+
+	result := models.PenilaianBulkResponse{Penilaian: []models.Penilaian{}, Errors: []models.BarisError{}}
+	rowError := func(i int, row models.NilaiSiswa, msg string) {
+		result.Errors = append(result.Errors, models.BarisError{Baris: i + 1, IDSiswa: row.IDSiswa, Error: msg})
+	}
+	seen := make(map[int]int, len(sheet.Nilai))
+	for i, row := range sheet.Nilai {
+		prev, dup := seen[row.IDSiswa]
+		if !dup {
+			seen[row.IDSiswa] = i
+		}
+		switch {
+		case !diKelas[row.IDSiswa]:
+			rowError(i, row, "Siswa tidak terdaftar di kelas mata pelajaran ini pada semester tersebut")
+		case dup:
+			rowError(i, row, fmt.Sprintf("Siswa sudah ada di baris %d", prev+1))
+		default:
+			if err := models.ValidateNilaiMaks(row.Nilai, nilaiMaks); err != nil {
+				rowError(i, row, err.Error())
+			}
+		}
+	}
+	if len(result.Errors) > 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, result)
+		return
+	}
+
+	// Step 3: Simpan semua baris. Penilaian komponen yang sudah ada diperbarui nilainya.
+	// Baris nilai dikunci berurutan menurut id_siswa supaya dua lembar nilai bersamaan tidak deadlock.
+	urutan := make([]int, len(sheet.Nilai))
+	for i := range urutan {
+		urutan[i] = i
+	}
+	sort.Slice(urutan, func(a, b int) bool { return sheet.Nilai[urutan[a]].IDSiswa < sheet.Nilai[urutan[b]].IDSiswa })
+
+	err = h.Repo.InTx(r.Context(), func(repos repository.Repositories) error {
+		for _, i := range urutan {
+			row := sheet.Nilai[i]
+			idNilai, err := repos.Nilai.FindOrCreate(r.Context(), row.IDSiswa, sheet.IDMapel, semester.IDSemester)
+			if err != nil {
+				return fmt.Errorf("find or create nilai: %w", err)
+			}
+
+			var exceeded *bobotExceededError
+			idPenilaian, err := upsertPenilaian(r.Context(), repos, idNilai, sheet.NamaNilai, row.Nilai, bobot, sheet.IDKomponen)
+			if errors.As(err, &exceeded) {
+				rowError(i, row, exceeded.Error())
+				continue
+			} else if err != nil {
+				return fmt.Errorf("simpan penilaian siswa %d: %w", row.IDSiswa, err)
+			}
+			if err := repos.Nilai.RecomputeTotal(r.Context(), idNilai); err != nil {
+				return err
+			}
+
+			result.Penilaian = append(result.Penilaian, models.Penilaian{
+				IDPenilaian: idPenilaian,
+				IDNilai:     idNilai,
+				IDMapel:     sheet.IDMapel,
+				IDSiswa:     row.IDSiswa,
+				IDSemester:  semester.IDSemester,
+				NamaNilai:   sheet.NamaNilai,
+				Nilai:       row.Nilai,
+				Bobot:       models.FormatBobot(bobot),
+				IDKomponen:  sheet.IDKomponen,
+			})
+		}
+		if len(result.Errors) > 0 {
+			sort.Slice(result.Errors, func(a, b int) bool { return result.Errors[a].Baris < result.Errors[b].Baris })
+			return errBarisDitolak
+		}
+		return nil
+	})
+	if errors.Is(err, errBarisDitolak) {
+		result.Penilaian = []models.Penilaian{}
+		writeJSON(w, http.StatusUnprocessableEntity, result)
+		return
+	}
+	if err != nil {
+		log.Printf("Bulk penilaian Error: %v\n", err)
+		penilaianError(w, err, "Nilai tidak ditemukan", "Gagal menyimpan lembar nilai")
+		return
+	}
+
+	result.Tersimpan = len(result.Penilaian)
+	writeJSON(w, http.StatusCreated, result)
+}
+
+// upsertPenilaian - Menyimpan satu penilaian di bawah nilai yang sudah terkunci. Penilaian komponen
+// yang sudah ada diperbarui, selain itu dibuat baru. Total bobot dicek lebih dulu (bobotExceededError).
+func upsertPenilaian(ctx context.Context, repos repository.Repositories, idNilai int, nama string, nilai int, bobot float64, idKomponen *int) (int, error) {
+	idPenilaian := 0
+	if idKomponen != nil {
+		var err error
+		idPenilaian, err = repos.Nilai.FindPenilaianKomponen(ctx, idNilai, *idKomponen)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return 0, err
+		}
+	}
+
+	if err := checkBobot(ctx, repos, idNilai, idPenilaian, bobot); err != nil {
+		return 0, err
+	}
+	if idPenilaian != 0 {
+		return idPenilaian, repos.Nilai.UpdatePenilaian(ctx, idPenilaian, nama, nilai, bobot)
+	}
+	return repos.Nilai.CreatePenilaian(ctx, idNilai, nama, nilai, bobot, idKomponen)
+}
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"myapp/internal/auth"
+	"myapp/internal/models"
+)
+
+// totalNilai - total_nilai tersimpan untuk siswa dan mapel pada semester test
+func (e *testEnv) totalNilai(t *testing.T, idSiswa, idMapel int) float64 {
+	t.Helper()
+	n, err := e.repo.Nilai.GetBySiswaAndMapel(context.Background(), idSiswa, idMapel, e.idSemester)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n.TotalNilai
+}
+
+func TestPenilaianHandlers(t *testing.T) {
+	e := newTestEnv(t)
+
+	create := func(t *testing.T, p models.Penilaian, want int) models.Penilaian {
+		t.Helper()
+		w := e.do(t, e.h.CreatePenilaianHandler, e.guruA, http.MethodPost, "/penilaian", nil, p)
+		expectStatus(t, w, want)
+		var created models.Penilaian
+		if want == http.StatusCreated {
+			if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return created
+	}
+	update := func(t *testing.T, id int, p models.Penilaian, want int) {
+		t.Helper()
+		w := e.do(t, e.h.UpdatePenilaianHandler, e.guruA, http.MethodPut, "/penilaian/"+strconv.Itoa(id),
+			map[string]string{"id": strconv.Itoa(id)}, p)
+		expectStatus(t, w, want)
+	}
+	remove := func(t *testing.T, id int, want int) {
+		t.Helper()
+		w := e.do(t, e.h.DeletePenilaianHandler, e.guruA, http.MethodDelete, "/penilaian/"+strconv.Itoa(id),
+			map[string]string{"id": strconv.Itoa(id)}, nil)
+		expectStatus(t, w, want)
+	}
+	expectTotal := func(t *testing.T, want float64) {
+		t.Helper()
+		if got := e.totalNilai(t, e.idSiswaA, e.idMapelA); got != want {
+			t.Errorf("total_nilai = %v, want %v", got, want)
+		}
+	}
+
+	uts := create(t, models.Penilaian{IDMapel: e.idMapelA, IDSiswa: e.idSiswaA, NamaNilai: "UTS", Nilai: 80, Bobot: "30%"}, http.StatusCreated)
+	if uts.IDSemester != e.idSemester || uts.Bobot != "30.00%" {
+		t.Errorf("penilaian dibuat = %+v, want semester %d bobot 30.00%%", uts, e.idSemester)
+	}
+	expectTotal(t, 24)
+	uas := create(t, models.Penilaian{IDMapel: e.idMapelA, IDSiswa: e.idSiswaA, NamaNilai: "UAS", Nilai: 90, Bobot: "70%"}, http.StatusCreated)
+	expectTotal(t, 87)
+
+	t.Run("bobot melebihi 100%", func(t *testing.T) {
+		create(t, models.Penilaian{IDMapel: e.idMapelA, IDSiswa: e.idSiswaA, NamaNilai: "Kuis", Nilai: 100, Bobot: "0.01%"}, http.StatusBadRequest)
+		update(t, uts.IDPenilaian, models.Penilaian{NamaNilai: "UTS", Nilai: 80, Bobot: "30.01%"}, http.StatusBadRequest)
+		expectTotal(t, 87)
+	})
+
+	t.Run("request tidak valid", func(t *testing.T) {
+		create(t, models.Penilaian{IDMapel: e.idMapelA, IDSiswa: e.idSiswaA, NamaNilai: "Kuis", Nilai: 101, Bobot: "10%"}, http.StatusBadRequest)
+		create(t, models.Penilaian{IDMapel: e.idMapelA, IDSiswa: e.idSiswaA, NamaNilai: "Kuis", Nilai: 80, Bobot: "sepuluh"}, http.StatusBadRequest)
+		create(t, models.Penilaian{IDMapel: e.idMapelA, IDSiswa: e.idSiswaA, NamaNilai: "Kuis", Nilai: 80, Bobot: "10%", IDSemester: 999999}, http.StatusBadRequest)
+		// Siswa kelas lain
+		create(t, models.Penilaian{IDMapel: e.idMapelA, IDSiswa: e.idSiswaB, NamaNilai: "Kuis", Nilai: 80, Bobot: "10%"}, http.StatusBadRequest)
+	})
+
+	t.Run("guru kelas lain", func(t *testing.T) {
+		w := e.do(t, e.h.CreatePenilaianHandler, e.guruB, http.MethodPost, "/penilaian", nil,
+			models.Penilaian{IDMapel: e.idMapelA, IDSiswa: e.idSiswaA, NamaNilai: "Kuis", Nilai: 80, Bobot: "10%"})
+		expectStatus(t, w, http.StatusForbidden)
+		w = e.do(t, e.h.DeletePenilaianHandler, e.guruB, http.MethodDelete, "/penilaian/x",
+			map[string]string{"id": strconv.Itoa(uts.IDPenilaian)}, nil)
+		expectStatus(t, w, http.StatusForbidden)
+		expectTotal(t, 87)
+	})
+
+	t.Run("update menghitung ulang total", func(t *testing.T) {
+		update(t, uts.IDPenilaian, models.Penilaian{NamaNilai: "UTS", Nilai: 100, Bobot: "30%"}, http.StatusOK)
+		expectTotal(t, 93)
+		update(t, uts.IDPenilaian, models.Penilaian{NamaNilai: "UTS", Nilai: 100, Bobot: "20%"}, http.StatusOK)
+		expectTotal(t, 83)
+	})
+
+	t.Run("delete menghitung ulang total", func(t *testing.T) {
+		remove(t, uas.IDPenilaian, http.StatusOK)
+		expectTotal(t, 20)
+		remove(t, uas.IDPenilaian, http.StatusNotFound)
+		update(t, uas.IDPenilaian, models.Penilaian{NamaNilai: "UAS", Nilai: 90, Bobot: "70%"}, http.StatusNotFound)
+	})
+
+	t.Run("penilaian komponen", func(t *testing.T) {
+		ctx := context.Background()
+		idKomponen, err := e.repo.Komponen.Create(ctx, models.KomponenPenilaian{IDMapel: e.idMapelA, NamaKomponen: "Tugas", NilaiMaks: 50}, 0.3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Nama dan bobot mengikuti komponen, nilai diskalakan dari nilai_maks 50
+		tugas := create(t, models.Penilaian{IDMapel: e.idMapelA, IDSiswa: e.idSiswaA, Nilai: 40, IDKomponen: &idKomponen}, http.StatusCreated)
+		if tugas.NamaNilai != "Tugas" || tugas.Bobot != "30.00%" {
+			t.Errorf("penilaian komponen = %+v, want nama Tugas bobot 30.00%%", tugas)
+		}
+		expectTotal(t, 44)
+
+		// Bobot masih muat (20% + 30% + 30%), ditolak karena komponen yang sama sudah dinilai
+		create(t, models.Penilaian{IDMapel: e.idMapelA, IDSiswa: e.idSiswaA, Nilai: 45, IDKomponen: &idKomponen}, http.StatusConflict)
+		update(t, tugas.IDPenilaian, models.Penilaian{Nilai: 51}, http.StatusBadRequest)
+		update(t, tugas.IDPenilaian, models.Penilaian{Nilai: 25}, http.StatusOK)
+		expectTotal(t, 35)
+	})
+}
+
+// TestPenilaianAnggotaKelas - Siswa dinilai menurut kelasnya pada semester penilaian, bukan kelas saat ini
+func TestPenilaianAnggotaKelas(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+
+	semester1 := e.idSemester
+	s1, err := e.repo.Periode.GetSemester(ctx, semester1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	semester2, err := e.repo.Periode.CreateSemester(ctx, models.Semester{
+		IDTahunAjaran: s1.IDTahunAjaran, Semester: 2, TanggalMulai: "2026-01-05", TanggalSelesai: "2026-06-20",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := func(username string) int {
+		id, err := e.repo.User.Create(ctx, models.User{Username: username, Password: "x", IDRole: auth.RoleSiswa})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+
+	// Citra di kelas A selama semester 1, lalu pindah ke kelas B saat libur semester
+	citra := e.addSiswa(t, user("citra"), e.idKelasA, "Citra", "0011223366")
+	sw, err := e.repo.Siswa.GetByID(ctx, citra)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pindahKelas(ctx, e.repo, sw, e.idKelasB, models.RiwayatPindah, "2025-12-22"); err != nil {
+		t.Fatal(err)
+	}
+	// Dodi baru masuk kelas A di semester 2
+	e.tanggalSemester = "2026-01-05"
+	dodi := e.addSiswa(t, user("dodi"), e.idKelasA, "Dodi", "0011223377")
+
+	tests := []struct {
+		name       string
+		idSiswa    int
+		idSemester int
+		want       int
+	}{
+		{"sudah pindah, semester lalu", citra, semester1, http.StatusCreated},
+		{"sudah pindah, semester sesudahnya", citra, semester2, http.StatusBadRequest},
+		{"belum masuk kelas", dodi, semester1, http.StatusBadRequest},
+		{"masuk kelas semester ini", dodi, semester2, http.StatusCreated},
+		{"kelas lain", e.idSiswaB, semester1, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := e.do(t, e.h.CreatePenilaianHandler, e.guruA, http.MethodPost, "/penilaian", nil, models.Penilaian{
+				IDMapel: e.idMapelA, IDSiswa: tt.idSiswa, IDSemester: tt.idSemester, NamaNilai: "UTS", Nilai: 80, Bobot: "30%",
+			})
+			expectStatus(t, w, tt.want)
+
+			// Lembar nilai memakai aturan yang sama
+			idSemester := tt.idSemester
+			w = e.do(t, e.h.CreatePenilaianBulkHandler, e.guruA, http.MethodPost, "/penilaian/bulk", nil, models.PenilaianBulk{
+				IDMapel: e.idMapelA, IDSemester: &idSemester, NamaNilai: "UAS", Bobot: "70%",
+				Nilai: []models.NilaiSiswa{{IDSiswa: tt.idSiswa, Nilai: 90}},
+			})
+			if tt.want == http.StatusBadRequest {
+				expectStatus(t, w, http.StatusUnprocessableEntity)
+			} else {
+				expectStatus(t, w, http.StatusCreated)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"myapp/internal/models"
+)
+
+// GetPeringkatKelasHandler - Peringkat siswa satu kelas dari rata-rata total_nilai semua mapel,
+// atau dari satu mapel saja dengan ?id_mapel=. ?id_semester= default semester aktif/terakhir tahun ajaran kelas.
+func (h *Handler) GetPeringkatKelasHandler(w http.ResponseWriter, r *http.Request) {
+	idKelas, ok := pathInt(w, r, "id", "ID kelas tidak valid")
+	if !ok {
+		return
+	}
+
+	allowed, err := h.canManageKelas(r.Context(), currentUser(r), idKelas)
+	if !authorize(w, allowed, err) {
+		return
+	}
+
+	kelas, err := h.Repo.Kelas.GetByID(r.Context(), idKelas)
+	if err != nil {
+		repoError(w, err, "Kelas tidak ditemukan", "Gagal mengambil data kelas")
+		return
+	}
+	semester, ok := h.semesterQuery(w, r, kelas.IDTahunAjaran)
+	if !ok {
+		return
+	}
+
+	var idMapel *int
+	if v := r.URL.Query().Get("id_mapel"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "ID mapel tidak valid", http.StatusBadRequest)
+			return
+		}
+		mapel, err := h.Repo.MataPelajaran.GetByID(r.Context(), id)
+		if err != nil {
+			repoError(w, err, "Mata Pelajaran not found", "Gagal mengambil mata pelajaran")
+			return
+		}
+		if mapel.IDKelas != idKelas {
+			http.Error(w, "Mata pelajaran bukan bagian dari kelas ini", http.StatusBadRequest)
+			return
+		}
+		idMapel = &id
+	}
+
+	siswa, err := h.Repo.Nilai.ListPeringkat(r.Context(), idKelas, idMapel, semester.IDSemester)
+	if err != nil {
+		dbError(w, err, "Gagal menghitung peringkat")
+		return
+	}
+	if siswa == nil {
+		siswa = []models.PeringkatSiswa{}
+	}
+
+	writeJSON(w, http.StatusOK, models.Peringkat{
+		IDKelas:     idKelas,
+		IDMapel:     idMapel,
+		IDSemester:  semester.IDSemester,
+		JumlahSiswa: len(siswa),
+		Siswa:       siswa,
+	})
+}
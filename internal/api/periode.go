@@ -0,0 +1,354 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"myapp/internal/models"
+	"myapp/internal/repository"
+)
+
+// GetTahunAjaranHandler - Semua tahun ajaran beserta semesternya, terbaru lebih dulu
+func (h *Handler) GetTahunAjaranHandler(w http.ResponseWriter, r *http.Request) {
+	tahunList, err := h.Repo.Periode.ListTahunAjaran(r.Context())
+	if err != nil {
+		dbError(w, err, "Gagal mengambil tahun ajaran")
+		return
+	}
+	semesterList, err := h.Repo.Periode.ListSemester(r.Context(), nil)
+	if err != nil {
+		dbError(w, err, "Gagal mengambil semester")
+		return
+	}
+
+	perTahun := make(map[int][]models.Semester)
+	for _, s := range semesterList {
+		perTahun[s.IDTahunAjaran] = append(perTahun[s.IDTahunAjaran], s)
+	}
+	if tahunList == nil {
+		tahunList = []models.TahunAjaran{}
+	}
+	for i, t := range tahunList {
+		tahunList[i].Semester = perTahun[t.IDTahunAjaran]
+		if tahunList[i].Semester == nil {
+			tahunList[i].Semester = []models.Semester{}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, tahunList)
+}
+
+// CreateTahunAjaranHandler - Menambahkan tahun ajaran beserta semester 1 dan 2. Tanggal semester
+// boleh dikirim di field semester; jika tidak, dipakai Juli - Desember dan Januari - Juni.
+func (h *Handler) CreateTahunAjaranHandler(w http.ResponseWriter, r *http.Request) {
+	var tahun models.TahunAjaran
+	if err := json.NewDecoder(r.Body).Decode(&tahun); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := tahun.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	semester := tahun.SemesterBawaan()
+	for _, s := range tahun.Semester {
+		if s.Semester != 1 && s.Semester != 2 {
+			http.Error(w, "semester harus 1 atau 2", http.StatusBadRequest)
+			return
+		}
+		if err := s.ValidateTanggal(); err != nil {
+			http.Error(w, fmt.Sprintf("semester %d: %v", s.Semester, err), http.StatusBadRequest)
+			return
+		}
+		semester[s.Semester-1].TanggalMulai, semester[s.Semester-1].TanggalSelesai = s.TanggalMulai, s.TanggalSelesai
+	}
+
+	err := h.Repo.InTx(r.Context(), func(repos repository.Repositories) error {
+		id, err := repos.Periode.CreateTahunAjaran(r.Context(), tahun)
+		if err != nil {
+			return err
+		}
+		tahun.IDTahunAjaran = id
+		for i := range semester {
+			semester[i].IDTahunAjaran, semester[i].TahunAjaran = id, tahun.Nama
+			if semester[i].IDSemester, err = repos.Periode.CreateSemester(r.Context(), semester[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Println("Create tahun ajaran error:", err)
+		repoError(w, err, "Tahun ajaran tidak ditemukan", "Gagal menyimpan tahun ajaran")
+		return
+	}
+
+	tahun.Semester = semester
+	writeJSON(w, http.StatusCreated, tahun)
+}
+
+// GetSemesterHandler - Daftar semester, bisa difilter ?id_tahun_ajaran=
+func (h *Handler) GetSemesterHandler(w http.ResponseWriter, r *http.Request) {
+	var idTahunAjaran *int
+	if v := r.URL.Query().Get("id_tahun_ajaran"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "ID tahun ajaran tidak valid", http.StatusBadRequest)
+			return
+		}
+		idTahunAjaran = &id
+	}
+
+	semester, err := h.Repo.Periode.ListSemester(r.Context(), idTahunAjaran)
+	if err != nil {
+		dbError(w, err, "Gagal mengambil semester")
+		return
+	}
+	if semester == nil {
+		semester = []models.Semester{}
+	}
+
+	writeJSON(w, http.StatusOK, semester)
+}
+
+// GetSemesterAktifHandler - Semester yang sedang aktif
+func (h *Handler) GetSemesterAktifHandler(w http.ResponseWriter, r *http.Request) {
+	semester, err := h.Repo.Periode.ActiveSemester(r.Context())
+	if err != nil {
+		repoError(w, err, "Belum ada semester aktif", "Gagal mengambil semester aktif")
+		return
+	}
+	writeJSON(w, http.StatusOK, semester)
+}
+
+// UpdateSemesterHandler - Mengubah tanggal mulai dan selesai semester
+func (h *Handler) UpdateSemesterHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID semester tidak valid")
+	if !ok {
+		return
+	}
+
+	var input models.Semester
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+	if err := input.ValidateTanggal(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Repo.Periode.UpdateTanggalSemester(r.Context(), id, input); err != nil {
+		repoError(w, err, "Semester tidak ditemukan", "Gagal mengubah semester")
+		return
+	}
+	semester, err := h.Repo.Periode.GetSemester(r.Context(), id)
+	if err != nil {
+		repoError(w, err, "Semester tidak ditemukan", "Gagal mengambil semester")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, semester)
+}
+
+// AktifkanSemesterHandler - Menjadikan satu semester sebagai semester aktif (periode bawaan API)
+func (h *Handler) AktifkanSemesterHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID semester tidak valid")
+	if !ok {
+		return
+	}
+
+	err := h.Repo.InTx(r.Context(), func(repos repository.Repositories) error {
+		return repos.Periode.Activate(r.Context(), id)
+	})
+	if err != nil {
+		repoError(w, err, "Semester tidak ditemukan", "Gagal mengaktifkan semester")
+		return
+	}
+	semester, err := h.Repo.Periode.GetSemester(r.Context(), id)
+	if err != nil {
+		repoError(w, err, "Semester tidak ditemukan", "Gagal mengambil semester")
+		return
+	}
+
+	log.Printf("Semester aktif: %s semester %d\n", semester.TahunAjaran, semester.Semester)
+	writeJSON(w, http.StatusOK, semester)
+}
+
+// tahunAjaranFilter - Filter ?id_tahun_ajaran= untuk daftar kelas/mapel. Default tahun ajaran semester
+// aktif (semua jika belum ada semester aktif); ?id_tahun_ajaran=semua menampilkan semua tahun ajaran.
+func (h *Handler) tahunAjaranFilter(w http.ResponseWriter, r *http.Request) (*int, bool) {
+	switch v := r.URL.Query().Get("id_tahun_ajaran"); v {
+	case "semua":
+		return nil, true
+	case "":
+		aktif, err := h.Repo.Periode.ActiveSemester(r.Context())
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, true
+		}
+		if err != nil {
+			dbError(w, err, "Gagal mengambil semester aktif")
+			return nil, false
+		}
+		return &aktif.IDTahunAjaran, true
+	default:
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "ID tahun ajaran tidak valid", http.StatusBadRequest)
+			return nil, false
+		}
+		return &id, true
+	}
+}
+
+// semesterFilter - Filter ?id_semester= untuk daftar penilaian. Default semester aktif (semua jika belum
+// ada semester aktif); ?id_semester=semua menampilkan semua semester.
+func (h *Handler) semesterFilter(w http.ResponseWriter, r *http.Request) (*int, bool) {
+	switch r.URL.Query().Get("id_semester") {
+	case "semua":
+		return nil, true
+	case "":
+		aktif, err := h.Repo.Periode.ActiveSemester(r.Context())
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, true
+		}
+		if err != nil {
+			dbError(w, err, "Gagal mengambil semester aktif")
+			return nil, false
+		}
+		return &aktif.IDSemester, true
+	default:
+		return semesterParam(w, r)
+	}
+}
+
+// resolveSemester - Semester idSemester, atau semester bawaan jika nil: semester aktif/terakhir
+// tahun ajaran idTahunAjaran, atau semester aktif jika idTahunAjaran 0. Semester yang bukan bagian
+// dari idTahunAjaran ditolak. Kesalahan input dikembalikan sebagai requestError.
+func (h *Handler) resolveSemester(ctx context.Context, idSemester *int, idTahunAjaran int) (models.Semester, error) {
+	var semester models.Semester
+	var err error
+	switch {
+	case idSemester != nil:
+		semester, err = h.Repo.Periode.GetSemester(ctx, *idSemester)
+		if errors.Is(err, repository.ErrNotFound) {
+			return semester, requestError("Semester tidak ditemukan")
+		}
+	case idTahunAjaran != 0:
+		semester, err = h.Repo.Periode.DefaultSemester(ctx, idTahunAjaran)
+		if errors.Is(err, repository.ErrNotFound) {
+			return semester, requestError("Tahun ajaran kelas ini belum punya semester")
+		}
+	default:
+		semester, err = h.Repo.Periode.ActiveSemester(ctx)
+		if errors.Is(err, repository.ErrNotFound) {
+			return semester, requestError("Belum ada semester aktif, isi id_semester")
+		}
+	}
+	if err != nil {
+		return semester, err
+	}
+	if idTahunAjaran != 0 && semester.IDTahunAjaran != idTahunAjaran {
+		return semester, requestError("Semester bukan bagian dari tahun ajaran kelas")
+	}
+	return semester, nil
+}
+
+// semesterParam - ?id_semester=, nil jika tidak diisi
+func semesterParam(w http.ResponseWriter, r *http.Request) (*int, bool) {
+	v := r.URL.Query().Get("id_semester")
+	if v == "" {
+		return nil, true
+	}
+	id, err := strconv.Atoi(v)
+	if err != nil {
+		http.Error(w, "ID semester tidak valid", http.StatusBadRequest)
+		return nil, false
+	}
+	return &id, true
+}
+
+// semesterQuery - resolveSemester dari ?id_semester=; menulis respons error dan false jika gagal
+func (h *Handler) semesterQuery(w http.ResponseWriter, r *http.Request, idTahunAjaran int) (models.Semester, bool) {
+	idSemester, ok := semesterParam(w, r)
+	if !ok {
+		return models.Semester{}, false
+	}
+
+	semester, err := h.resolveSemester(r.Context(), idSemester, idTahunAjaran)
+	if err != nil {
+		requestOrRepoError(w, err, "Semester tidak ditemukan", "Gagal mengambil semester")
+		return semester, false
+	}
+	return semester, true
+}
+
+// resolveTahunAjaran - id_tahun_ajaran kelas dari input: id_tahun_ajaran, nama tahun_ajaran,
+// atau tahun ajaran semester aktif jika keduanya kosong
+func (h *Handler) resolveTahunAjaran(ctx context.Context, kelas *models.Kelas) error {
+	var tahun models.TahunAjaran
+	var err error
+	switch {
+	case kelas.IDTahunAjaran != 0:
+		tahun, err = h.Repo.Periode.GetTahunAjaran(ctx, kelas.IDTahunAjaran)
+	case kelas.TahunAjaran != "":
+		tahun, err = h.Repo.Periode.FindTahunAjaran(ctx, kelas.TahunAjaran)
+	default:
+		var aktif models.Semester
+		aktif, err = h.Repo.Periode.ActiveSemester(ctx)
+		tahun = models.TahunAjaran{IDTahunAjaran: aktif.IDTahunAjaran, Nama: aktif.TahunAjaran}
+		if errors.Is(err, repository.ErrNotFound) {
+			return requestError("Belum ada semester aktif, isi id_tahun_ajaran")
+		}
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return requestError("Tahun ajaran belum terdaftar")
+	}
+	if err != nil {
+		return err
+	}
+	kelas.IDTahunAjaran, kelas.TahunAjaran = tahun.IDTahunAjaran, tahun.Nama
+	return nil
+}
+
+// mapelSemester - resolveSemester untuk tahun ajaran kelas pemilik mapel
+func (h *Handler) mapelSemester(ctx context.Context, idMapel int, idSemester *int) (models.Semester, error) {
+	mapel, err := h.Repo.MataPelajaran.GetByID(ctx, idMapel)
+	if err != nil {
+		return models.Semester{}, err
+	}
+	kelas, err := h.Repo.Kelas.GetByID(ctx, mapel.IDKelas)
+	if err != nil {
+		return models.Semester{}, err
+	}
+	return h.resolveSemester(ctx, idSemester, kelas.IDTahunAjaran)
+}
+
+// anggotaMapel - Siswa yang tercatat di kelas pemilik mapel selama semester (lihat SiswaRepository.ListAnggotaKelas),
+// termasuk yang sesudahnya pindah kelas atau berhenti, sehingga nilai semester yang sudah lewat tetap bisa diisi
+func (h *Handler) anggotaMapel(ctx context.Context, idMapel, idSemester int) ([]models.Siswa, error) {
+	mapel, err := h.Repo.MataPelajaran.GetByID(ctx, idMapel)
+	if err != nil {
+		return nil, err
+	}
+	return h.Repo.Siswa.ListAnggotaKelas(ctx, mapel.IDKelas, idSemester)
+}
+
+// siswaSemester - Semester idSemester (boleh dari tahun ajaran mana pun), atau jika nil semester
+// aktif/terakhir tahun ajaran kelas siswa saat ini, atau semester aktif jika siswa belum punya kelas
+func (h *Handler) siswaSemester(ctx context.Context, siswa models.Siswa, idSemester *int) (models.Semester, error) {
+	if idSemester != nil || siswa.IDKelas == nil {
+		return h.resolveSemester(ctx, idSemester, 0)
+	}
+	kelas, err := h.Repo.Kelas.GetByID(ctx, *siswa.IDKelas)
+	if err != nil {
+		return models.Semester{}, err
+	}
+	return h.resolveSemester(ctx, nil, kelas.IDTahunAjaran)
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"myapp/internal/models"
+	"myapp/internal/rapor"
+	"myapp/internal/repository"
+)
+
+// errKelasTidakDitemukan - Siswa tidak punya kelas pada tahun ajaran semester yang diminta
+var errKelasTidakDitemukan = errors.New("Siswa tidak terdaftar di kelas mana pun pada tahun ajaran tersebut")
+
+// GetRaporHandler - PDF rapor seorang siswa untuk satu semester (?id_semester=, default semester
+// aktif/terakhir tahun ajaran kelas siswa saat ini)
+func (h *Handler) GetRaporHandler(w http.ResponseWriter, r *http.Request) {
+	idSiswa, ok := pathInt(w, r, "id_siswa", "ID siswa tidak valid")
+	if !ok {
+		return
+	}
+
+	allowed, err := h.canReadSiswa(r.Context(), currentUser(r), idSiswa)
+	if !authorize(w, allowed, err) {
+		return
+	}
+
+	idSemester, ok := semesterParam(w, r)
+	if !ok {
+		return
+	}
+
+	data, err := h.buildRapor(r.Context(), idSiswa, idSemester)
+	if err != nil {
+		raporError(w, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := rapor.Write(&buf, h.Config.Sekolah, data); err != nil {
+		log.Println("Render rapor error:", err)
+		http.Error(w, "Gagal membuat PDF rapor", http.StatusInternalServerError)
+		return
+	}
+
+	filename := fmt.Sprintf("rapor-%s-%s-semester%d.pdf", data.Siswa.NISN, data.TahunAjaran, data.Semester.Semester)
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, safeFilename(filename)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// buildRapor - Mengumpulkan identitas siswa, kelas, wali kelas, nilai semua mapel kelas tersebut dan absensinya
+// pada satu semester (lihat siswaSemester untuk semester bawaan jika idSemester nil)
+func (h *Handler) buildRapor(ctx context.Context, idSiswa int, idSemester *int) (models.Rapor, error) {
+	siswa, err := h.Repo.Siswa.GetByID(ctx, idSiswa)
+	if err != nil {
+		return models.Rapor{}, err
+	}
+	semester, err := h.siswaSemester(ctx, siswa, idSemester)
+	if err != nil {
+		return models.Rapor{}, err
+	}
+
+	kelas, err := h.Repo.Kelas.FindForSiswa(ctx, idSiswa, semester.IDTahunAjaran)
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.Rapor{}, errKelasTidakDitemukan
+	}
+	if err != nil {
+		return models.Rapor{}, err
+	}
+	wali, err := h.Repo.Guru.GetByID(ctx, kelas.IDGuru)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return models.Rapor{}, err
+	}
+	mapel, err := h.Repo.Nilai.ListRapor(ctx, idSiswa, kelas.IDKelas, semester.IDSemester)
+	if err != nil {
+		return models.Rapor{}, err
+	}
+
+	data := models.Rapor{
+		Siswa:        siswa,
+		Kelas:        kelas,
+		WaliKelas:    wali,
+		TahunAjaran:  semester.TahunAjaran,
+		Semester:     semester,
+		Mapel:        mapel,
+		TanggalCetak: time.Now(),
+	}
+	var sum float64
+	var dinilai int
+	for i, m := range data.Mapel {
+		if m.TotalNilai == nil {
+			continue
+		}
+		data.Mapel[i].Predikat = m.Skala.Predikat(*m.TotalNilai)
+		data.Mapel[i].Lulus = *m.TotalNilai >= m.KKM
+		sum += *m.TotalNilai
+		dinilai++
+	}
+	if dinilai > 0 {
+		rata := math.Round(sum/float64(dinilai)*100) / 100
+		data.RataRata = &rata
+	}
+	if data.Mapel == nil {
+		data.Mapel = []models.RaporMapel{}
+	}
+
+	data.Absensi, err = h.Repo.Absensi.RekapSiswa(ctx, idSiswa, kelas.IDKelas, semester.TanggalMulai, semester.TanggalSelesai)
+	if err != nil {
+		return models.Rapor{}, err
+	}
+
+	peringkat, err := h.Repo.Nilai.ListPeringkat(ctx, kelas.IDKelas, nil, semester.IDSemester)
+	if err != nil {
+		return models.Rapor{}, err
+	}
+	data.JumlahSiswa = len(peringkat)
+	for _, p := range peringkat {
+		if p.IDSiswa == idSiswa {
+			data.Peringkat = p.Peringkat
+		}
+	}
+	return data, nil
+}
+
+// raporError - 400 untuk requestError, 404 jika siswa atau kelasnya tidak ada, selain itu dbError
+func raporError(w http.ResponseWriter, err error) {
+	var invalid requestError
+	switch {
+	case errors.As(err, &invalid):
+		http.Error(w, invalid.Error(), http.StatusBadRequest)
+	case errors.Is(err, errKelasTidakDitemukan):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		repoError(w, err, "Siswa tidak ditemukan", "Gagal membuat rapor")
+	}
+}
+
+// safeFilename - Nama file untuk header Content-Disposition tanpa karakter pemisah path atau kutip
+func safeFilename(name string) string {
+	return strings.Map(func(c rune) rune {
+		switch {
+		case c == '/' || c == '\\' || c == ' ':
+			return '-'
+		case c == '"' || c < 0x20 || c > 0x7e:
+			return -1
+		}
+		return c
+	}, name)
+}
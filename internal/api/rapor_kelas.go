@@ -0,0 +1,156 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"myapp/internal/jobs"
+	"myapp/internal/models"
+	"myapp/internal/rapor"
+)
+
+// ExportRaporKelasHandler - Membuat rapor semua siswa satu kelas di latar belakang.
+// ?format=pdf (default, satu PDF gabungan) atau ?format=zip (satu PDF per siswa);
+// ?id_semester= default semester aktif/terakhir tahun ajaran kelas.
+// Respons 202 berisi id_job; progres dipantau lewat GET /jobs/{id} dan hasilnya diunduh
+// dari GET /jobs/{id}/download.
+func (h *Handler) ExportRaporKelasHandler(w http.ResponseWriter, r *http.Request) {
+	idKelas, ok := pathInt(w, r, "id", "ID kelas tidak valid")
+	if !ok {
+		return
+	}
+
+	user := currentUser(r)
+	allowed, err := h.canManageKelas(r.Context(), user, idKelas)
+	if !authorize(w, allowed, err) {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "":
+		format = "pdf"
+	case "pdf", "zip":
+	default:
+		http.Error(w, "format harus pdf atau zip", http.StatusBadRequest)
+		return
+	}
+
+	kelas, err := h.Repo.Kelas.GetByID(r.Context(), idKelas)
+	if err != nil {
+		repoError(w, err, "Kelas tidak ditemukan", "Gagal mengambil data kelas")
+		return
+	}
+	semester, ok := h.semesterQuery(w, r, kelas.IDTahunAjaran)
+	if !ok {
+		return
+	}
+	siswaList, err := h.Repo.Siswa.ListAnggotaKelas(r.Context(), idKelas, semester.IDSemester)
+	if err != nil {
+		dbError(w, err, "Gagal mengambil data siswa")
+		return
+	}
+	if len(siswaList) == 0 {
+		http.Error(w, "Kelas belum punya siswa", http.StatusBadRequest)
+		return
+	}
+
+	job := h.Jobs.Start(user.IDUser, "rapor_kelas", func(ctx context.Context, progress jobs.Progress) (jobs.Result, error) {
+		return h.raporKelas(ctx, kelas, semester, siswaList, format, progress)
+	})
+	log.Printf("Job %s: rapor kelas %d (%s, %d siswa)\n", job.ID, idKelas, format, len(siswaList))
+
+	w.Header().Set("Location", "/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// raporKelas - Isi job ExportRaporKelasHandler
+func (h *Handler) raporKelas(ctx context.Context, kelas models.Kelas, semester models.Semester, siswaList []models.Siswa, format string, progress jobs.Progress) (jobs.Result, error) {
+	var buf bytes.Buffer
+	var zw *zip.Writer
+	if format == "zip" {
+		zw = zip.NewWriter(&buf)
+	}
+
+	all := make([]models.Rapor, 0, len(siswaList))
+	for i, s := range siswaList {
+		if err := ctx.Err(); err != nil {
+			return jobs.Result{}, err
+		}
+		data, err := h.buildRapor(ctx, s.IDSiswa, &semester.IDSemester)
+		if err != nil {
+			return jobs.Result{}, fmt.Errorf("rapor %s: %w", s.NamaSiswa, err)
+		}
+
+		if zw != nil {
+			f, err := zw.Create(safeFilename(fmt.Sprintf("%02d-%s-%s.pdf", i+1, s.NISN, s.NamaSiswa)))
+			if err != nil {
+				return jobs.Result{}, err
+			}
+			if err := rapor.Write(f, h.Config.Sekolah, data); err != nil {
+				return jobs.Result{}, fmt.Errorf("rapor %s: %w", s.NamaSiswa, err)
+			}
+		} else {
+			all = append(all, data)
+		}
+		progress(i+1, len(siswaList))
+	}
+
+	name := safeFilename(fmt.Sprintf("rapor-%s-%s-semester%d", kelas.NamaKelas, kelas.TahunAjaran, semester.Semester))
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return jobs.Result{}, err
+		}
+		return jobs.Result{Filename: name + ".zip", ContentType: "application/zip", Data: buf.Bytes()}, nil
+	}
+	if err := rapor.Write(&buf, h.Config.Sekolah, all...); err != nil {
+		return jobs.Result{}, err
+	}
+	return jobs.Result{Filename: name + ".pdf", ContentType: "application/pdf", Data: buf.Bytes()}, nil
+}
+
+// GetJobHandler - Status dan progres job latar belakang
+func (h *Handler) GetJobHandler(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.jobForUser(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// DownloadJobHandler - Mengunduh file hasil job yang sudah selesai
+func (h *Handler) DownloadJobHandler(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.jobForUser(w, r)
+	if !ok {
+		return
+	}
+	result, ok := h.Jobs.Result(job.ID)
+	if !ok {
+		http.Error(w, "Job belum selesai (status: "+job.Status+")", http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", result.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, result.Filename))
+	w.Header().Set("Content-Length", strconv.Itoa(len(result.Data)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(result.Data)
+}
+
+// jobForUser - Job hanya terlihat oleh pembuatnya dan admin; selain itu 404 supaya id job tidak bisa ditebak-tebak
+func (h *Handler) jobForUser(w http.ResponseWriter, r *http.Request) (jobs.Job, bool) {
+	job, ok := h.Jobs.Get(mux.Vars(r)["id"])
+	user := currentUser(r)
+	if !ok || (job.IDUser != user.IDUser && !user.IsAdmin()) {
+		http.Error(w, "Job tidak ditemukan atau sudah kedaluwarsa", http.StatusNotFound)
+		return jobs.Job{}, false
+	}
+	return job, true
+}
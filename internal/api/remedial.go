@@ -0,0 +1,223 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"myapp/internal/models"
+	"myapp/internal/repository"
+)
+
+// GetSiswaRemedialHandler - Siswa yang total_nilai-nya masih di bawah KKM mapel, beserta penilaian
+// yang belum tuntas dan remedial yang sudah dicoba. ?id_semester= default semester aktif/terakhir
+// tahun ajaran kelas mapel.
+func (h *Handler) GetSiswaRemedialHandler(w http.ResponseWriter, r *http.Request) {
+	idMapel, ok := pathInt(w, r, "id", "ID mapel tidak valid")
+	if !ok {
+		return
+	}
+
+	allowed, err := h.canManageMapel(r.Context(), currentUser(r), idMapel)
+	if !authorize(w, allowed, err) {
+		return
+	}
+
+	mapel, err := h.Repo.MataPelajaran.GetByID(r.Context(), idMapel)
+	if err != nil {
+		repoError(w, err, "Mata Pelajaran not found", "Gagal mengambil mata pelajaran")
+		return
+	}
+	kelas, err := h.Repo.Kelas.GetByID(r.Context(), mapel.IDKelas)
+	if err != nil {
+		repoError(w, err, "Kelas tidak ditemukan", "Gagal mengambil kelas")
+		return
+	}
+	semester, ok := h.semesterQuery(w, r, kelas.IDTahunAjaran)
+	if !ok {
+		return
+	}
+	siswaList, err := h.Repo.Nilai.ListBelumTuntas(r.Context(), idMapel, semester.IDSemester)
+	if err != nil {
+		dbError(w, err, "Gagal mengambil siswa remedial")
+		return
+	}
+	komponen, err := h.Repo.Komponen.ListByMapel(r.Context(), idMapel)
+	if err != nil {
+		dbError(w, err, "Gagal mengambil komponen penilaian")
+		return
+	}
+	penilaianList, err := h.Repo.Nilai.ListPenilaianByMapel(r.Context(), idMapel, semester.IDSemester)
+	if err != nil {
+		dbError(w, err, "Gagal mengambil penilaian")
+		return
+	}
+	remedialList, err := h.Repo.Remedial.ListByMapel(r.Context(), idMapel, semester.IDSemester)
+	if err != nil {
+		dbError(w, err, "Gagal mengambil remedial")
+		return
+	}
+
+	nilaiMaks := make(map[int]int, len(komponen))
+	for _, k := range komponen {
+		nilaiMaks[k.IDKomponen] = k.NilaiMaks
+	}
+	remedial := make(map[int][]models.Remedial)
+	for _, rm := range remedialList {
+		remedial[rm.IDPenilaian] = append(remedial[rm.IDPenilaian], rm)
+	}
+	perSiswa := make(map[int][]models.PenilaianRemedial)
+	for _, p := range penilaianList {
+		maks := models.NilaiMax
+		if p.IDKomponen != nil && nilaiMaks[*p.IDKomponen] > 0 {
+			maks = nilaiMaks[*p.IDKomponen]
+		}
+		if !perluRemedial(p.Nilai, maks, mapel.KKM) {
+			continue
+		}
+		list := remedial[p.IDPenilaian]
+		if list == nil {
+			list = []models.Remedial{}
+		}
+		perSiswa[p.IDSiswa] = append(perSiswa[p.IDSiswa], models.PenilaianRemedial{
+			IDPenilaian: p.IDPenilaian,
+			NamaNilai:   p.NamaNilai,
+			Nilai:       p.Nilai,
+			NilaiMaks:   maks,
+			Remedial:    list,
+		})
+	}
+
+	if siswaList == nil {
+		siswaList = []models.SiswaRemedial{}
+	}
+	for i, s := range siswaList {
+		siswaList[i].Penilaian = perSiswa[s.IDSiswa]
+		if siswaList[i].Penilaian == nil {
+			siswaList[i].Penilaian = []models.PenilaianRemedial{}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, siswaList)
+}
+
+// GetRemedialByPenilaianHandler - Semua percobaan remedial untuk satu penilaian
+func (h *Handler) GetRemedialByPenilaianHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID penilaian tidak valid")
+	if !ok {
+		return
+	}
+
+	allowed, err := h.canManagePenilaian(r.Context(), currentUser(r), id)
+	if !authorize(w, allowed, err) {
+		return
+	}
+
+	if _, err := h.Repo.Nilai.GetPenilaian(r.Context(), id); err != nil {
+		repoError(w, err, "Penilaian not found", "Gagal mengambil penilaian")
+		return
+	}
+	remedial, err := h.Repo.Remedial.ListByPenilaian(r.Context(), id)
+	if err != nil {
+		dbError(w, err, "Gagal mengambil remedial")
+		return
+	}
+	if remedial == nil {
+		remedial = []models.Remedial{}
+	}
+
+	writeJSON(w, http.StatusOK, remedial)
+}
+
+// CreateRemedialHandler - Mencatat nilai remedial untuk penilaian yang belum mencapai KKM.
+// Nilai asli tidak berubah; total_nilai dihitung ulang memakai aturan_remedial mapel.
+func (h *Handler) CreateRemedialHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID penilaian tidak valid")
+	if !ok {
+		return
+	}
+
+	allowed, err := h.canManagePenilaian(r.Context(), currentUser(r), id)
+	if !authorize(w, allowed, err) {
+		return
+	}
+
+	var remedial models.Remedial
+	if err := json.NewDecoder(r.Body).Decode(&remedial); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	remedial.IDPenilaian = id
+
+	err = h.Repo.InTx(r.Context(), func(repos repository.Repositories) error {
+		return changePenilaian(r.Context(), repos, id, func(p models.Penilaian) error {
+			mapel, err := repos.MataPelajaran.GetByID(r.Context(), p.IDMapel)
+			if err != nil {
+				return err
+			}
+			maks := models.NilaiMax
+			if p.IDKomponen != nil {
+				komponen, err := repos.Komponen.GetByID(r.Context(), *p.IDKomponen)
+				if err != nil {
+					return err
+				}
+				maks = komponen.NilaiMaks
+			}
+			if !perluRemedial(p.Nilai, maks, mapel.KKM) {
+				return requestError("Nilai penilaian ini sudah mencapai KKM, tidak perlu remedial")
+			}
+			if err := remedial.Validate(maks); err != nil {
+				return requestError(err.Error())
+			}
+			remedial.IDRemedial, err = repos.Remedial.Create(r.Context(), remedial)
+			return err
+		})
+	})
+	if err != nil {
+		log.Println("Create remedial error:", err)
+		penilaianError(w, err, "Penilaian not found", "Gagal menyimpan remedial")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, remedial)
+}
+
+// DeleteRemedialHandler - Menghapus satu percobaan remedial lalu menghitung ulang total_nilai
+func (h *Handler) DeleteRemedialHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID remedial tidak valid")
+	if !ok {
+		return
+	}
+
+	remedial, err := h.Repo.Remedial.GetByID(r.Context(), id)
+	if err != nil {
+		repoError(w, err, "Remedial not found", "Gagal mengambil remedial")
+		return
+	}
+	allowed, err := h.canManagePenilaian(r.Context(), currentUser(r), remedial.IDPenilaian)
+	if !authorize(w, allowed, err) {
+		return
+	}
+
+	err = h.Repo.InTx(r.Context(), func(repos repository.Repositories) error {
+		return changePenilaian(r.Context(), repos, remedial.IDPenilaian, func(models.Penilaian) error {
+			return repos.Remedial.Delete(r.Context(), id)
+		})
+	})
+	if err != nil {
+		repoError(w, err, "Remedial not found", "Gagal menghapus remedial")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Remedial berhasil dihapus"))
+}
+
+// perluRemedial - true jika nilai penilaian (diskalakan ke 0 - 100) masih di bawah KKM.
+// nilaiMaks 0 (komponen tidak ditemukan) dihitung dengan skala 100 seperti models.TotalNilai.
+func perluRemedial(nilai, nilaiMaks int, kkm float64) bool {
+	if nilaiMaks <= 0 {
+		nilaiMaks = models.NilaiMax
+	}
+	return float64(nilai)*100/float64(nilaiMaks) < kkm
+}
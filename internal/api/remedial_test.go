@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"myapp/internal/models"
+)
+
+func TestPerluRemedial(t *testing.T) {
+	tests := []struct {
+		name      string
+		nilai     int
+		nilaiMaks int
+		kkm       float64
+		want      bool
+	}{
+		{"di bawah KKM", 74, 100, 75, true},
+		{"tepat KKM", 75, 100, 75, false},
+		{"di atas KKM", 90, 100, 75, false},
+		{"KKM desimal", 72, 100, 72.5, true},
+		{"nilai_maks 50 diskalakan", 37, 50, 75, true}, // 74
+		{"nilai_maks 50 tepat KKM", 38, 50, 75, false}, // 76
+		{"nilai_maks 20", 15, 20, 75, false},           // 75
+		{"nilai_maks 0 memakai skala 100", 74, 0, 75, true},
+		{"nilai_maks 0 tidak membagi nol", 0, 0, 75, true},
+		{"nilai_maks 0 nilai tuntas", 80, 0, 75, false},
+		{"KKM 0", 0, 100, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := perluRemedial(tt.nilai, tt.nilaiMaks, tt.kkm); got != tt.want {
+				t.Errorf("perluRemedial(%d, %d, %v) = %v, want %v", tt.nilai, tt.nilaiMaks, tt.kkm, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRemedialAturan - total_nilai setelah remedial menurut aturan_remedial mapel: UTS 60 (50%) diremedial
+// 90 lalu 70, ditambah UAS 80 (50%) yang sudah tuntas
+func TestRemedialAturan(t *testing.T) {
+	tests := []struct {
+		aturan    string
+		wantTotal float64
+	}{
+		{models.AturanRemedialGanti, 75},      // remedial terakhir 70
+		{models.AturanRemedialMaks, 85},       // remedial tertinggi 90
+		{models.AturanRemedialBatasKKM, 77.5}, // remedial tertinggi 90 dibatasi KKM 75
+	}
+	for _, tt := range tests {
+		t.Run(tt.aturan, func(t *testing.T) {
+			e := newTestEnv(t)
+			ctx := context.Background()
+			mapel, err := e.repo.MataPelajaran.GetByID(ctx, e.idMapelA)
+			if err != nil {
+				t.Fatal(err)
+			}
+			mapel.AturanRemedial = tt.aturan
+			if err := e.repo.MataPelajaran.Update(ctx, e.idMapelA, mapel); err != nil {
+				t.Fatal(err)
+			}
+
+			create := func(nama string, nilai int) int {
+				t.Helper()
+				w := e.do(t, e.h.CreatePenilaianHandler, e.guruA, http.MethodPost, "/penilaian", nil,
+					models.Penilaian{IDMapel: e.idMapelA, IDSiswa: e.idSiswaA, NamaNilai: nama, Nilai: nilai, Bobot: "50%"})
+				expectStatus(t, w, http.StatusCreated)
+				var p models.Penilaian
+				if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+					t.Fatal(err)
+				}
+				return p.IDPenilaian
+			}
+			remedial := func(idPenilaian int, rm models.Remedial, want int) {
+				t.Helper()
+				w := e.do(t, e.h.CreateRemedialHandler, e.guruA, http.MethodPost, "/penilaian/x/remedial",
+					map[string]string{"id": strconv.Itoa(idPenilaian)}, rm)
+				expectStatus(t, w, want)
+			}
+
+			uts := create("UTS", 60)
+			uas := create("UAS", 80)
+
+			// Sebelum remedial: total 70, hanya UTS yang belum tuntas
+			w := e.do(t, e.h.GetSiswaRemedialHandler, e.guruA, http.MethodGet, "/mapel/x/remedial",
+				map[string]string{"id": strconv.Itoa(e.idMapelA)}, nil)
+			expectStatus(t, w, http.StatusOK)
+			var siswa []models.SiswaRemedial
+			if err := json.NewDecoder(w.Body).Decode(&siswa); err != nil {
+				t.Fatal(err)
+			}
+			if len(siswa) != 1 || siswa[0].TotalNilai != 70 || len(siswa[0].Penilaian) != 1 ||
+				siswa[0].Penilaian[0].IDPenilaian != uts || siswa[0].Penilaian[0].NilaiMaks != models.NilaiMax {
+				t.Fatalf("siswa remedial = %+v, want siswa A dengan UTS saja", siswa)
+			}
+
+			remedial(uas, models.Remedial{Nilai: 90}, http.StatusBadRequest) // UAS sudah tuntas
+			remedial(uts, models.Remedial{Nilai: 101}, http.StatusBadRequest)
+			remedial(uts, models.Remedial{Nilai: 90, Tanggal: "2025-08-01"}, http.StatusCreated)
+			remedial(uts, models.Remedial{Nilai: 70, Tanggal: "2025-08-10"}, http.StatusCreated)
+
+			if got := e.totalNilai(t, e.idSiswaA, e.idMapelA); got != tt.wantTotal {
+				t.Errorf("total_nilai = %v, want %v", got, tt.wantTotal)
+			}
+		})
+	}
+}
+
+// TestRemedialNilaiMaks - Remedial penilaian komponen memakai skala nilai_maks komponennya
+func TestRemedialNilaiMaks(t *testing.T) {
+	e := newTestEnv(t)
+	ctx := context.Background()
+	idKomponen, err := e.repo.Komponen.Create(ctx, models.KomponenPenilaian{IDMapel: e.idMapelA, NamaKomponen: "Tugas", NilaiMaks: 50}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := e.do(t, e.h.CreatePenilaianHandler, e.guruA, http.MethodPost, "/penilaian", nil,
+		models.Penilaian{IDMapel: e.idMapelA, IDSiswa: e.idSiswaA, Nilai: 30, IDKomponen: &idKomponen})
+	expectStatus(t, w, http.StatusCreated)
+	var p models.Penilaian
+	if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+		t.Fatal(err)
+	}
+
+	remedial := func(nilai, want int) {
+		t.Helper()
+		w := e.do(t, e.h.CreateRemedialHandler, e.guruA, http.MethodPost, "/penilaian/x/remedial",
+			map[string]string{"id": strconv.Itoa(p.IDPenilaian)}, models.Remedial{Nilai: nilai, Tanggal: "2025-08-01"})
+		expectStatus(t, w, want)
+	}
+	remedial(51, http.StatusBadRequest)
+	remedial(45, http.StatusCreated) // 90, dibatasi KKM 75
+	if got := e.totalNilai(t, e.idSiswaA, e.idMapelA); got != 75 {
+		t.Errorf("total_nilai = %v, want 75", got)
+	}
+}
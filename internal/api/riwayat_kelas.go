@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"myapp/internal/models"
+	"myapp/internal/repository"
+)
+
+// GetRiwayatKelasHandler - Semua kelas yang pernah ditempati seorang siswa beserta tanggal dan statusnya
+func (h *Handler) GetRiwayatKelasHandler(w http.ResponseWriter, r *http.Request) {
+	idSiswa, ok := pathInt(w, r, "id", "ID siswa tidak valid")
+	if !ok {
+		return
+	}
+
+	allowed, err := h.canReadSiswa(r.Context(), currentUser(r), idSiswa)
+	if !authorize(w, allowed, err) {
+		return
+	}
+
+	if _, err := h.Repo.Siswa.GetByID(r.Context(), idSiswa); err != nil {
+		repoError(w, err, "Siswa tidak ditemukan", "Gagal mengambil data siswa")
+		return
+	}
+	riwayat, err := h.Repo.RiwayatKelas.ListBySiswa(r.Context(), idSiswa)
+	if err != nil {
+		dbError(w, err, "Gagal mengambil riwayat kelas")
+		return
+	}
+	if riwayat == nil {
+		riwayat = []models.RiwayatKelas{}
+	}
+
+	writeJSON(w, http.StatusOK, riwayat)
+}
+
+// catatKelas - Mencatat perpindahan siswa dari kelas lama ke kelas baru di riwayat_kelas (nil berarti
+// tanpa kelas): riwayat kelas lama ditutup dengan status, lalu riwayat kelas baru dibuka.
+// Tidak melakukan apa pun jika kelasnya sama. siswa.id_kelas diubah sendiri oleh pemanggil.
+func catatKelas(ctx context.Context, repos repository.Repositories, idSiswa int, lama, baru *int, status, tanggal string) error {
+	if kelasSama(lama, baru) {
+		return nil
+	}
+	lama, baru = adaKelas(lama), adaKelas(baru)
+	if tanggal == "" {
+		tanggal = time.Now().Format(models.TanggalLayout)
+	}
+	if lama != nil {
+		if err := repos.RiwayatKelas.Keluar(ctx, idSiswa, tanggal, status); err != nil {
+			return fmt.Errorf("tutup riwayat kelas siswa %d: %w", idSiswa, err)
+		}
+	}
+	if baru != nil {
+		if _, err := repos.RiwayatKelas.Masuk(ctx, idSiswa, *baru, tanggal); err != nil {
+			return fmt.Errorf("buka riwayat kelas siswa %d: %w", idSiswa, err)
+		}
+	}
+	return nil
+}
+
+// pindahKelas - Memindahkan siswa ke kelas idKelas dan mencatatnya di riwayat kelas. Harus di dalam transaksi.
+func pindahKelas(ctx context.Context, repos repository.Repositories, siswa models.Siswa, idKelas int, status, tanggal string) error {
+	if err := catatKelas(ctx, repos, siswa.IDSiswa, siswa.IDKelas, &idKelas, status, tanggal); err != nil {
+		return err
+	}
+	return repos.Siswa.UpdateKelas(ctx, siswa.IDSiswa, idKelas)
+}
+
+// adaKelas - id_kelas 0 diperlakukan sama dengan tanpa kelas, seperti di SiswaRepository.Create
+func adaKelas(idKelas *int) *int {
+	if idKelas != nil && *idKelas == 0 {
+		return nil
+	}
+	return idKelas
+}
+
+// kelasSama - true jika kedua id_kelas menunjuk kelas yang sama (atau sama-sama tanpa kelas)
+func kelasSama(a, b *int) bool {
+	a, b = adaKelas(a), adaKelas(b)
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,211 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"myapp/internal/models"
+	"myapp/internal/repository"
+)
+
+// UpdateStatusSiswaHandler - Mengubah status siswa (aktif, lulus, pindah, keluar) sebagai ganti
+// menghapus siswa. Riwayat kelasnya ditutup; siswa yang diaktifkan kembali boleh langsung
+// dimasukkan ke kelas lewat id_kelas.
+func (h *Handler) UpdateStatusSiswaHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathInt(w, r, "id", "ID siswa tidak valid")
+	if !ok {
+		return
+	}
+
+	var input models.StatusSiswa
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if input.IDKelas != nil && input.Status != models.SiswaAktif {
+		http.Error(w, "id_kelas hanya bisa diisi untuk status aktif", http.StatusBadRequest)
+		return
+	}
+
+	var siswa models.Siswa
+	err := h.Repo.InTx(r.Context(), func(repos repository.Repositories) error {
+		existing, err := repos.Siswa.GetByID(r.Context(), id)
+		if err != nil {
+			return err
+		}
+		if err := ubahStatusSiswa(r.Context(), repos, existing, input); err != nil {
+			return err
+		}
+		siswa, err = repos.Siswa.GetByID(r.Context(), id)
+		return err
+	})
+	if err != nil {
+		repoError(w, err, "Siswa tidak ditemukan", "Gagal mengubah status siswa")
+		return
+	}
+
+	log.Printf("Status siswa %d: %s sejak %s\n", id, input.Status, input.Tanggal)
+	writeJSON(w, http.StatusOK, siswa)
+}
+
+// ubahStatusSiswa - Menyimpan status baru dan menyesuaikan riwayat kelas. Harus di dalam transaksi.
+// Siswa yang berhenti tetap menyimpan id_kelas terakhirnya, hanya riwayat aktifnya yang ditutup.
+func ubahStatusSiswa(ctx context.Context, repos repository.Repositories, siswa models.Siswa, input models.StatusSiswa) error {
+	if err := repos.Siswa.UpdateStatus(ctx, siswa.IDSiswa, input); err != nil {
+		return err
+	}
+
+	if input.Status != models.SiswaAktif {
+		if siswa.Status != models.SiswaAktif {
+			return nil
+		}
+		return repos.RiwayatKelas.Keluar(ctx, siswa.IDSiswa, input.Tanggal, input.StatusRiwayat())
+	}
+
+	if siswa.Status == models.SiswaAktif {
+		if adaKelas(input.IDKelas) == nil {
+			return nil
+		}
+		return pindahKelas(ctx, repos, siswa, *input.IDKelas, models.RiwayatPindah, input.Tanggal)
+	}
+	// Aktif kembali: riwayat baru dibuka di kelas yang dipilih, atau kelas terakhirnya
+	idKelas := adaKelas(input.IDKelas)
+	if idKelas == nil {
+		idKelas = adaKelas(siswa.IDKelas)
+	}
+	if idKelas == nil {
+		return nil
+	}
+	if err := catatKelas(ctx, repos, siswa.IDSiswa, nil, idKelas, models.RiwayatAktif, input.Tanggal); err != nil {
+		return err
+	}
+	return repos.Siswa.UpdateKelas(ctx, siswa.IDSiswa, *idKelas)
+}
+
+// KelulusanHandler - Meluluskan semua siswa aktif satu kelas sekaligus, kecuali yang ada di tidak_lulus
+func (h *Handler) KelulusanHandler(w http.ResponseWriter, r *http.Request) {
+	idKelas, ok := pathInt(w, r, "id", "ID kelas tidak valid")
+	if !ok {
+		return
+	}
+
+	var input models.Kelulusan
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	status := models.StatusSiswa{Status: models.SiswaLulus, Tanggal: input.Tanggal}
+	if err := status.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	kelas, err := h.Repo.Kelas.GetByID(r.Context(), idKelas)
+	if err != nil {
+		repoError(w, err, "Kelas tidak ditemukan", "Gagal mengambil data kelas")
+		return
+	}
+	siswaList, err := h.Repo.Siswa.ListByKelas(r.Context(), idKelas, hanyaAktif())
+	if err != nil {
+		dbError(w, err, "Gagal mengambil data siswa")
+		return
+	}
+	diKelas := make(map[int]bool, len(siswaList))
+	for _, s := range siswaList {
+		diKelas[s.IDSiswa] = true
+	}
+	tidakLulus := make(map[int]bool, len(input.TidakLulus))
+	for _, id := range input.TidakLulus {
+		if !diKelas[id] {
+			http.Error(w, fmt.Sprintf("Siswa %d bukan siswa aktif kelas %s", id, kelas.NamaKelas), http.StatusBadRequest)
+			return
+		}
+		tidakLulus[id] = true
+	}
+
+	sort.Slice(siswaList, func(a, b int) bool { return siswaList[a].IDSiswa < siswaList[b].IDSiswa })
+	lulus := make([]models.Siswa, 0, len(siswaList))
+	err = h.Repo.InTx(r.Context(), func(repos repository.Repositories) error {
+		for _, s := range siswaList {
+			if tidakLulus[s.IDSiswa] {
+				continue
+			}
+			if err := ubahStatusSiswa(r.Context(), repos, s, status); err != nil {
+				return fmt.Errorf("luluskan siswa %d: %w", s.IDSiswa, err)
+			}
+			s.Status, s.TanggalStatus = status.Status, &status.Tanggal
+			lulus = append(lulus, s)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Kelulusan kelas %d error: %v\n", idKelas, err)
+		repoError(w, err, "Siswa tidak ditemukan", "Gagal menyimpan kelulusan")
+		return
+	}
+
+	log.Printf("Kelulusan kelas %s %s: %d siswa lulus\n", kelas.NamaKelas, kelas.TahunAjaran, len(lulus))
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message":      "Kelulusan berhasil disimpan",
+		"jumlah_lulus": len(lulus),
+		"siswa":        lulus,
+	})
+}
+
+// GetAlumniHandler - Daftar alumni (siswa lulus) beserta kelas terakhirnya, bisa difilter ?tahun_lulus=
+func (h *Handler) GetAlumniHandler(w http.ResponseWriter, r *http.Request) {
+	var tahunLulus *int
+	if v := r.URL.Query().Get("tahun_lulus"); v != "" {
+		tahun, err := strconv.Atoi(v)
+		if err != nil || tahun < 1900 || tahun > time.Now().Year()+1 {
+			http.Error(w, "tahun_lulus tidak valid", http.StatusBadRequest)
+			return
+		}
+		tahunLulus = &tahun
+	}
+
+	alumni, err := h.Repo.Siswa.ListAlumni(r.Context(), tahunLulus)
+	if err != nil {
+		dbError(w, err, "Gagal mengambil data alumni")
+		return
+	}
+	if alumni == nil {
+		alumni = []models.Alumni{}
+	}
+
+	writeJSON(w, http.StatusOK, alumni)
+}
+
+// statusFilter - Filter ?status= untuk daftar siswa: default aktif, ?status=semua untuk semua status
+func statusFilter(w http.ResponseWriter, r *http.Request) (*string, bool) {
+	switch status := r.URL.Query().Get("status"); {
+	case status == "":
+		return hanyaAktif(), true
+	case status == "semua":
+		return nil, true
+	case models.ValidStatusSiswa(status):
+		return &status, true
+	default:
+		http.Error(w, "status harus aktif, lulus, pindah, keluar atau semua", http.StatusBadRequest)
+		return nil, false
+	}
+}
+
+// hanyaAktif - Filter status untuk proses yang hanya berlaku bagi siswa aktif
+func hanyaAktif() *string {
+	status := models.SiswaAktif
+	return &status
+}
+
+// errSiswaNonaktif - Siswa yang sudah lulus/pindah/keluar tidak bisa dipindah kelas
+const errSiswaNonaktif = "Siswa sudah tidak aktif, aktifkan kembali lewat status siswa"
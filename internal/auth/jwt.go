@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	tokenIssuer     = "lasharan"
+	defaultTokenTTL = 12 * time.Hour
+)
+
+var (
+	jwtSecret []byte
+	tokenTTL  = defaultTokenTTL
+)
+
+// Claims - Isi token akses yang dikirim ke frontend
+type Claims struct {
+	IDUser int `json:"id_user"`
+	IDRole int `json:"id_role"`
+	jwt.RegisteredClaims
+}
+
+// InitJWT - Menyimpan secret dan masa berlaku token dari konfigurasi
+func InitJWT(secret string, ttl time.Duration) {
+	if secret == "" {
+		log.Fatal("JWT secret is empty")
+	}
+	jwtSecret = []byte(secret)
+	if ttl > 0 {
+		tokenTTL = ttl
+	}
+}
+
+// GenerateToken - Membuat token akses bertanda tangan untuk user yang berhasil login
+func GenerateToken(idUser, idRole int) (string, time.Time, error) {
+	if len(jwtSecret) == 0 {
+		return "", time.Time{}, errors.New("jwt secret is not initialized")
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(tokenTTL)
+	claims := Claims{
+		IDUser: idUser,
+		IDRole: idRole,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tokenIssuer,
+			Subject:   strconv.Itoa(idUser),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSecret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// ParseToken - Memvalidasi tanda tangan dan masa berlaku token
+func ParseToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return jwtSecret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(tokenIssuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+)
+
+type contextKey int
+
+const userContextKey contextKey = iota
+
+// Middleware - Memeriksa header Authorization dan menyimpan user ke context request
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		tokenString, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || tokenString == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Missing or invalid Authorization header", http.StatusUnauthorized)
+			return
+		}
+
+		claims, err := ParseToken(tokenString)
+		if err != nil {
+			log.Println("Token validation error:", err)
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), claims)))
+	})
+}
+
+// WithUser - Context berisi user yang sudah terautentikasi, dipakai Middleware (dan test handler)
+func WithUser(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, userContextKey, claims)
+}
+
+// UserFromContext - Mengambil user yang sudah terautentikasi dari context
+func UserFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(userContextKey).(*Claims)
+	return claims, ok
+}
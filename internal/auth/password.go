@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordCost - Cost bcrypt untuk hash password baru
+const PasswordCost = 12
+
+var (
+	ErrEmptyPassword   = errors.New("password tidak boleh kosong")
+	ErrPasswordTooLong = errors.New("password maksimal 72 byte")
+)
+
+// dummyHash dipakai agar waktu respons login sama walaupun username tidak ditemukan
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("lasharan-dummy-password"), PasswordCost)
+
+// HashPassword - Membuat hash bcrypt dari password plaintext
+func HashPassword(password string) (string, error) {
+	if password == "" {
+		return "", ErrEmptyPassword
+	}
+	if len(password) > 72 {
+		return "", ErrPasswordTooLong
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), PasswordCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// VerifyPassword - Mencocokkan password dengan nilai yang tersimpan di database.
+// Baris lama yang masih plaintext tetap bisa login, tetapi needsRehash bernilai true
+// supaya pemanggil bisa langsung menggantinya dengan hash.
+func VerifyPassword(stored, password string) (ok bool, needsRehash bool) {
+	if !isBcryptHash(stored) {
+		match := stored != "" && subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+		return match, match
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)); err != nil {
+		return false, false
+	}
+
+	cost, err := bcrypt.Cost([]byte(stored))
+	return true, err != nil || cost < PasswordCost
+}
+
+// BurnPasswordCheck - Menjalankan perbandingan bcrypt palsu untuk user yang tidak ada
+func BurnPasswordCheck(password string) {
+	bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+}
+
+func isBcryptHash(s string) bool {
+	return len(s) == 60 && (strings.HasPrefix(s, "$2a$") || strings.HasPrefix(s, "$2b$") || strings.HasPrefix(s, "$2y$"))
+}
+
+// passwordAlphabet - Tanpa huruf/angka yang mudah tertukar saat dibaca dari kertas (0/O, 1/l/I)
+const passwordAlphabet = "abcdefghjkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// GeneratePassword - Password awal acak sepanjang n karakter untuk akun yang dibuat admin
+func GeneratePassword(n int) (string, error) {
+	b := make([]byte, n)
+	max := big.NewInt(int64(len(passwordAlphabet)))
+	for i := range b {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = passwordAlphabet[idx.Int64()]
+	}
+	return string(b), nil
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"net/http"
+	"slices"
+)
+
+// Nilai id_role pada tabel "user"
+const (
+	RoleAdmin = 1
+	RoleGuru  = 2
+	RoleSiswa = 3
+)
+
+// RoleName - Nama role untuk keperluan log dan pesan error
+func RoleName(idRole int) string {
+	switch idRole {
+	case RoleAdmin:
+		return "admin"
+	case RoleGuru:
+		return "guru"
+	case RoleSiswa:
+		return "siswa"
+	default:
+		return "unknown"
+	}
+}
+
+// HasRole - Mengecek apakah user memiliki salah satu role yang diizinkan
+func (c *Claims) HasRole(roles ...int) bool {
+	return c != nil && slices.Contains(roles, c.IDRole)
+}
+
+// IsAdmin - Admin melewati semua pemeriksaan kepemilikan data
+func (c *Claims) IsAdmin() bool {
+	return c.HasRole(RoleAdmin)
+}
+
+// RequireRoles - Middleware yang menolak request dengan 403 jika role user tidak diizinkan.
+// Harus dipasang setelah Middleware supaya user sudah ada di context.
+func RequireRoles(roles ...int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := UserFromContext(r.Context())
+			if !ok {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			if !claims.HasRole(roles...) {
+				http.Error(w, "Forbidden: role "+RoleName(claims.IDRole)+" tidak memiliki akses", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -1,40 +1,74 @@
 package db
 
 import (
+	"context"
 	"database/sql"
-	"fmt"
+	"database/sql/driver"
+	"errors"
+	"io"
 	"log"
+	"net"
+	"time"
 
-	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/lib/pq" // PostgreSQL driver
 )
 
-// Konfigurasi database
-const (
-	DbHost     = "mydb-lasharan.cnyoam46yv1l.ap-southeast-3.rds.amazonaws.com"
-	DbPort     = 5432
-	DbUser     = "postgres"
-	DbPassword = "AyamBangkok123"
-	DbName     = "lasharan"
-)
-
-func ConnectToDB() (*sql.DB, error) {
-	// Format connection string lengkap
-	connStr := fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=require",
-		DbHost, DbPort, DbUser, DbPassword, DbName,
-	)
+// PoolConfig - Pengaturan connection pool yang dipakai bersama oleh semua handler
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
 
-	db, err := sql.Open("postgres", connStr)
+// Open - Membuat satu *sql.DB yang hidup selama server berjalan
+func Open(dsn string, pool PoolConfig) (*sql.DB, error) {
+	db, err := sql.Open("postgres", dsn)
 	if err != nil {
-		log.Fatal("Error connecting to the database: ", err)
 		return nil, err
 	}
 
-	if err = db.Ping(); err != nil {
-		log.Fatal("Error pinging database: ", err)
-		return nil, err
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+
+	// Database yang belum siap saat startup tidak menghentikan server,
+	// koneksi akan dicoba lagi oleh pool pada request berikutnya
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		log.Println("Warning: database is not reachable yet:", err)
+	} else {
+		log.Println("Successfully connected to the database!")
 	}
 
-	fmt.Println("Successfully connected to the database!")
 	return db, nil
 }
+
+// IsUnavailable - true jika error berasal dari koneksi database yang putus/tidak tersedia,
+// bukan dari query yang salah
+func IsUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Class() {
+		case "08", "53", "57": // connection_exception, insufficient_resources, operator_intervention
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS penilaian;
+DROP TABLE IF EXISTS nilai;
+DROP TABLE IF EXISTS siswa;
+DROP TABLE IF EXISTS mata_pelajaran;
+DROP TABLE IF EXISTS kelas;
+DROP TABLE IF EXISTS guru;
+DROP TABLE IF EXISTS "user";
@@ -0,0 +1,73 @@
+-- Skema awal, sama dengan tabel yang sudah ada di RDS production.
+-- id_role: 1 = admin, 2 = guru, 3 = siswa (lihat internal/auth/roles.go)
+
+CREATE TABLE "user" (
+    id_user            SERIAL PRIMARY KEY,
+    username           VARCHAR(100) NOT NULL UNIQUE,
+    password           VARCHAR(255) NOT NULL,
+    id_role            INTEGER      NOT NULL CHECK (id_role IN (1, 2, 3)),
+    tanggal_registrasi DATE         NOT NULL DEFAULT CURRENT_DATE
+);
+
+CREATE TABLE guru (
+    id_guru        SERIAL PRIMARY KEY,
+    id_user        INTEGER      NOT NULL REFERENCES "user" (id_user),
+    -- id_mapel dan mata_pelajaran adalah kolom lama yang hanya bersifat informasi,
+    -- relasi guru ke mapel yang sebenarnya lewat kelas.id_guru
+    id_mapel       INTEGER      NOT NULL DEFAULT 0,
+    nama_guru      VARCHAR(150) NOT NULL,
+    mata_pelajaran VARCHAR(150) NOT NULL DEFAULT '',
+    nip            VARCHAR(30)  NOT NULL DEFAULT '',
+    alamat         TEXT         NOT NULL DEFAULT '',
+    email          VARCHAR(150) NOT NULL DEFAULT '',
+    no_telp        VARCHAR(30)  NOT NULL DEFAULT '',
+    foto           TEXT
+);
+CREATE INDEX guru_id_user_idx ON guru (id_user);
+
+CREATE TABLE kelas (
+    id_kelas     SERIAL PRIMARY KEY,
+    id_guru      INTEGER      NOT NULL REFERENCES guru (id_guru),
+    nama_kelas   VARCHAR(50)  NOT NULL,
+    tahun_ajaran VARCHAR(20)  NOT NULL
+);
+CREATE INDEX kelas_id_guru_idx ON kelas (id_guru);
+
+CREATE TABLE mata_pelajaran (
+    id_mapel            SERIAL PRIMARY KEY,
+    id_kelas            INTEGER      NOT NULL REFERENCES kelas (id_kelas) ON DELETE CASCADE,
+    nama_mata_pelajaran VARCHAR(150) NOT NULL
+);
+CREATE INDEX mata_pelajaran_id_kelas_idx ON mata_pelajaran (id_kelas);
+
+CREATE TABLE siswa (
+    id_siswa      SERIAL PRIMARY KEY,
+    id_user       INTEGER      NOT NULL REFERENCES "user" (id_user),
+    id_kelas      INTEGER      REFERENCES kelas (id_kelas) ON DELETE SET NULL,
+    nama_siswa    VARCHAR(150) NOT NULL,
+    alamat        TEXT         NOT NULL DEFAULT '',
+    tanggal_lahir DATE         NOT NULL,
+    nisn          VARCHAR(20)  NOT NULL,
+    foto          TEXT
+);
+CREATE INDEX siswa_id_user_idx ON siswa (id_user);
+CREATE INDEX siswa_id_kelas_idx ON siswa (id_kelas);
+
+CREATE TABLE nilai (
+    id_nilai    SERIAL PRIMARY KEY,
+    id_siswa    INTEGER      NOT NULL REFERENCES siswa (id_siswa) ON DELETE CASCADE,
+    id_mapel    INTEGER      NOT NULL REFERENCES mata_pelajaran (id_mapel) ON DELETE CASCADE,
+    total_nilai NUMERIC(6, 2) NOT NULL DEFAULT 0
+);
+CREATE INDEX nilai_id_siswa_idx ON nilai (id_siswa);
+CREATE INDEX nilai_id_mapel_idx ON nilai (id_mapel);
+
+CREATE TABLE penilaian (
+    id_penilaian SERIAL PRIMARY KEY,
+    id_nilai     INTEGER       NOT NULL REFERENCES nilai (id_nilai) ON DELETE CASCADE,
+    nama_nilai   VARCHAR(100)  NOT NULL,
+    nilai        INTEGER       NOT NULL,
+    -- bobot disimpan sebagai desimal: 0.3 berarti 30%
+    bobot        NUMERIC(5, 4) NOT NULL
+);
+CREATE INDEX penilaian_id_nilai_idx ON penilaian (id_nilai);
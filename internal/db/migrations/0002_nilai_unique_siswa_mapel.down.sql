@@ -0,0 +1,3 @@
+-- Baris duplikat yang sudah digabung tidak dikembalikan
+CREATE INDEX nilai_id_siswa_idx ON nilai (id_siswa);
+ALTER TABLE nilai DROP CONSTRAINT nilai_id_siswa_id_mapel_key;
@@ -0,0 +1,30 @@
+-- Satu baris nilai per (id_siswa, id_mapel). Baris duplikat dari find-or-insert lama
+-- digabung ke id_nilai terkecil: penilaian-nya dipindah, lalu total_nilai dihitung ulang.
+
+CREATE TEMP TABLE nilai_dedup ON COMMIT DROP AS
+SELECT n.id_nilai AS id_lama, k.id_nilai AS id_baru
+FROM nilai n
+JOIN (
+    SELECT id_siswa, id_mapel, MIN(id_nilai) AS id_nilai
+    FROM nilai
+    GROUP BY id_siswa, id_mapel
+) k ON n.id_siswa = k.id_siswa AND n.id_mapel = k.id_mapel
+WHERE n.id_nilai <> k.id_nilai;
+
+UPDATE penilaian p
+SET id_nilai = d.id_baru
+FROM nilai_dedup d
+WHERE p.id_nilai = d.id_lama;
+
+DELETE FROM nilai n
+USING nilai_dedup d
+WHERE n.id_nilai = d.id_lama;
+
+UPDATE nilai n
+SET total_nilai = COALESCE((SELECT ROUND(SUM(p.nilai * p.bobot), 2) FROM penilaian p WHERE p.id_nilai = n.id_nilai), 0)
+WHERE n.id_nilai IN (SELECT id_baru FROM nilai_dedup);
+
+ALTER TABLE nilai ADD CONSTRAINT nilai_id_siswa_id_mapel_key UNIQUE (id_siswa, id_mapel);
+
+-- Index unique di atas sudah mencakup pencarian berdasarkan id_siswa
+DROP INDEX nilai_id_siswa_idx;
@@ -0,0 +1,3 @@
+ALTER TABLE penilaian
+    DROP CONSTRAINT penilaian_nilai_range,
+    DROP CONSTRAINT penilaian_bobot_range;
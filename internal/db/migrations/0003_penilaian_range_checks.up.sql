@@ -0,0 +1,6 @@
+-- Batas yang sama dengan models.ValidateNilai / models.ValidateBobot.
+-- NOT VALID: hanya berlaku untuk baris baru/yang diubah, data lama yang melanggar
+-- tetap bisa dibaca dan diperbaiki lewat API.
+ALTER TABLE penilaian
+    ADD CONSTRAINT penilaian_nilai_range CHECK (nilai BETWEEN 0 AND 100) NOT VALID,
+    ADD CONSTRAINT penilaian_bobot_range CHECK (bobot > 0 AND bobot <= 1) NOT VALID;
@@ -0,0 +1,5 @@
+ALTER TABLE penilaian DROP CONSTRAINT penilaian_nilai_range;
+ALTER TABLE penilaian ADD CONSTRAINT penilaian_nilai_range CHECK (nilai BETWEEN 0 AND 100) NOT VALID;
+
+ALTER TABLE penilaian DROP COLUMN id_komponen;
+DROP TABLE komponen_penilaian;
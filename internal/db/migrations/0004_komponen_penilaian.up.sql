@@ -0,0 +1,21 @@
+-- Komponen penilaian (UTS, UAS, tugas, ...) didefinisikan sekali per mata_pelajaran.
+-- Penilaian seorang siswa untuk komponen tertentu menunjuk ke komponen lewat id_komponen;
+-- nama_nilai dan bobot-nya disalin dari komponen supaya perhitungan total tetap sama.
+CREATE TABLE komponen_penilaian (
+    id_komponen   SERIAL PRIMARY KEY,
+    id_mapel      INTEGER       NOT NULL REFERENCES mata_pelajaran (id_mapel) ON DELETE CASCADE,
+    nama_komponen VARCHAR(100)  NOT NULL,
+    bobot         NUMERIC(5, 4) NOT NULL CHECK (bobot > 0 AND bobot <= 1),
+    nilai_maks    INTEGER       NOT NULL DEFAULT 100 CHECK (nilai_maks > 0),
+    tenggat       DATE,
+    CONSTRAINT komponen_penilaian_id_mapel_nama_key UNIQUE (id_mapel, nama_komponen)
+);
+
+ALTER TABLE penilaian
+    ADD COLUMN id_komponen INTEGER REFERENCES komponen_penilaian (id_komponen) ON DELETE CASCADE,
+    ADD CONSTRAINT penilaian_id_nilai_id_komponen_key UNIQUE (id_nilai, id_komponen);
+CREATE INDEX penilaian_id_komponen_idx ON penilaian (id_komponen);
+
+-- Nilai komponen dibatasi oleh nilai_maks komponennya (dicek di aplikasi), bukan lagi 0 - 100
+ALTER TABLE penilaian DROP CONSTRAINT penilaian_nilai_range;
+ALTER TABLE penilaian ADD CONSTRAINT penilaian_nilai_range CHECK (nilai >= 0) NOT VALID;
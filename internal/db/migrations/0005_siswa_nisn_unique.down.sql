@@ -0,0 +1 @@
+DROP INDEX IF EXISTS siswa_nisn_key;
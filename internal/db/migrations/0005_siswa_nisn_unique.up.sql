@@ -0,0 +1,5 @@
+-- NISN unik per siswa. Nol di depan diabaikan karena Excel sering membuangnya
+-- (0012345678 dan 12345678 dianggap sama). Jika migrasi ini gagal, NISN ganda
+-- yang sudah ada harus dibereskan manual lebih dulu:
+--   SELECT LTRIM(nisn, '0'), array_agg(id_siswa) FROM siswa WHERE nisn <> '' GROUP BY 1 HAVING COUNT(*) > 1;
+CREATE UNIQUE INDEX siswa_nisn_key ON siswa (LTRIM(nisn, '0')) WHERE nisn <> '';
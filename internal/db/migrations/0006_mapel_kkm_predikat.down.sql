@@ -0,0 +1 @@
+ALTER TABLE mata_pelajaran DROP COLUMN IF EXISTS skala_predikat, DROP COLUMN IF EXISTS kkm;
@@ -0,0 +1,7 @@
+-- KKM (kriteria ketuntasan minimal) dan skala predikat diatur per mata_pelajaran.
+-- skala_predikat berisi daftar {"predikat", "min"} urut dari min terbesar; predikat berlaku
+-- untuk total_nilai >= min. Mapel yang sudah ada memakai KKM 75 dan skala A/B/C/D bawaan.
+ALTER TABLE mata_pelajaran
+    ADD COLUMN kkm NUMERIC(5, 2) NOT NULL DEFAULT 75 CHECK (kkm >= 0 AND kkm <= 100),
+    ADD COLUMN skala_predikat JSONB NOT NULL
+        DEFAULT '[{"predikat": "A", "min": 86}, {"predikat": "B", "min": 71}, {"predikat": "C", "min": 56}, {"predikat": "D", "min": 0}]';
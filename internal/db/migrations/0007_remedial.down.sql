@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS remedial;
+ALTER TABLE mata_pelajaran DROP COLUMN IF EXISTS aturan_remedial;
@@ -0,0 +1,18 @@
+-- Remedial: percobaan ulang untuk satu penilaian siswa yang belum tuntas. Nilai remedial
+-- tidak menimpa penilaian asli; total_nilai menghitung nilai efektifnya menurut
+-- mata_pelajaran.aturan_remedial:
+--   ganti     - nilai remedial terakhir menggantikan nilai asli
+--   batas_kkm - nilai remedial tertinggi dipakai tapi paling banyak sebesar KKM (nilai asli jika lebih baik)
+--   maks      - nilai tertinggi antara nilai asli dan semua remedial
+ALTER TABLE mata_pelajaran
+    ADD COLUMN aturan_remedial VARCHAR(20) NOT NULL DEFAULT 'batas_kkm'
+        CHECK (aturan_remedial IN ('ganti', 'batas_kkm', 'maks'));
+
+CREATE TABLE remedial (
+    id_remedial  SERIAL PRIMARY KEY,
+    id_penilaian INTEGER NOT NULL REFERENCES penilaian (id_penilaian) ON DELETE CASCADE,
+    nilai        INTEGER NOT NULL CHECK (nilai >= 0),
+    tanggal      DATE    NOT NULL DEFAULT CURRENT_DATE,
+    catatan      TEXT    NOT NULL DEFAULT ''
+);
+CREATE INDEX remedial_id_penilaian_idx ON remedial (id_penilaian);
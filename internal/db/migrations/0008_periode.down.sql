@@ -0,0 +1,15 @@
+-- Gagal jika seorang siswa sudah punya nilai di dua semester untuk mapel yang sama;
+-- salah satunya harus dihapus dulu.
+ALTER TABLE nilai
+    DROP CONSTRAINT nilai_id_siswa_id_mapel_id_semester_key,
+    ADD CONSTRAINT nilai_id_siswa_id_mapel_key UNIQUE (id_siswa, id_mapel),
+    DROP COLUMN id_semester;
+
+ALTER TABLE kelas ADD COLUMN tahun_ajaran VARCHAR(20);
+UPDATE kelas k SET tahun_ajaran = t.nama
+FROM tahun_ajaran t
+WHERE t.id_tahun_ajaran = k.id_tahun_ajaran;
+ALTER TABLE kelas ALTER COLUMN tahun_ajaran SET NOT NULL, DROP COLUMN id_tahun_ajaran;
+
+DROP TABLE semester;
+DROP TABLE tahun_ajaran;
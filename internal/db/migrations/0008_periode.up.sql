@@ -0,0 +1,68 @@
+-- Tahun ajaran dan semester menjadi tabel sendiri. Kelas terikat ke satu tahun ajaran,
+-- nilai terikat ke satu semester sehingga nilai siswa per semester tidak tercampur.
+-- Hanya satu semester yang boleh aktif; semester aktif menjadi periode bawaan di API.
+CREATE TABLE tahun_ajaran (
+    id_tahun_ajaran SERIAL PRIMARY KEY,
+    nama            VARCHAR(20) NOT NULL,
+    CONSTRAINT tahun_ajaran_nama_key UNIQUE (nama)
+);
+
+CREATE TABLE semester (
+    id_semester     SERIAL PRIMARY KEY,
+    id_tahun_ajaran INTEGER  NOT NULL REFERENCES tahun_ajaran (id_tahun_ajaran) ON DELETE CASCADE,
+    semester        SMALLINT NOT NULL CHECK (semester IN (1, 2)),
+    tanggal_mulai   DATE     NOT NULL,
+    tanggal_selesai DATE     NOT NULL,
+    aktif           BOOLEAN  NOT NULL DEFAULT FALSE,
+    CONSTRAINT semester_id_tahun_ajaran_semester_key UNIQUE (id_tahun_ajaran, semester),
+    CONSTRAINT semester_tanggal_check CHECK (tanggal_selesai > tanggal_mulai)
+);
+CREATE UNIQUE INDEX semester_aktif_key ON semester (aktif) WHERE aktif;
+
+-- Tahun ajaran dari teks kelas.tahun_ajaran yang sudah ada. Semester 1 Juli - Desember tahun
+-- pertama, semester 2 Januari - Juni tahun berikutnya; teks tanpa angka tahun memakai tahun ini.
+INSERT INTO tahun_ajaran (nama)
+SELECT DISTINCT TRIM(tahun_ajaran) FROM kelas;
+
+INSERT INTO semester (id_tahun_ajaran, semester, tanggal_mulai, tanggal_selesai)
+SELECT t.id_tahun_ajaran, s.semester,
+    MAKE_DATE(y.tahun + s.semester - 1, s.bulan_mulai, 1),
+    (MAKE_DATE(y.tahun + s.semester - 1, s.bulan_mulai, 1) + INTERVAL '6 months' - INTERVAL '1 day')::date
+FROM tahun_ajaran t
+CROSS JOIN LATERAL (
+    SELECT COALESCE(SUBSTRING(t.nama FROM '\d{4}')::int, EXTRACT(YEAR FROM CURRENT_DATE)::int) AS tahun
+) y
+CROSS JOIN (VALUES (1, 7), (2, 1)) AS s (semester, bulan_mulai);
+
+UPDATE semester SET aktif = TRUE
+WHERE id_semester = (
+    SELECT id_semester FROM semester
+    WHERE CURRENT_DATE BETWEEN tanggal_mulai AND tanggal_selesai
+    ORDER BY id_semester
+    LIMIT 1
+);
+
+ALTER TABLE kelas ADD COLUMN id_tahun_ajaran INTEGER REFERENCES tahun_ajaran (id_tahun_ajaran);
+UPDATE kelas k SET id_tahun_ajaran = t.id_tahun_ajaran
+FROM tahun_ajaran t
+WHERE t.nama = TRIM(k.tahun_ajaran);
+ALTER TABLE kelas ALTER COLUMN id_tahun_ajaran SET NOT NULL, DROP COLUMN tahun_ajaran;
+CREATE INDEX kelas_id_tahun_ajaran_idx ON kelas (id_tahun_ajaran);
+
+-- Nilai lama masuk ke semester terakhir yang sudah dimulai pada tahun ajaran kelasnya
+-- (semester 1 jika tahun ajarannya belum dimulai)
+ALTER TABLE nilai ADD COLUMN id_semester INTEGER REFERENCES semester (id_semester);
+UPDATE nilai n SET id_semester = (
+    SELECT s.id_semester
+    FROM mata_pelajaran m
+    JOIN kelas k ON k.id_kelas = m.id_kelas
+    JOIN semester s ON s.id_tahun_ajaran = k.id_tahun_ajaran
+    WHERE m.id_mapel = n.id_mapel
+    ORDER BY s.tanggal_mulai <= CURRENT_DATE DESC, ABS(s.tanggal_mulai - CURRENT_DATE)
+    LIMIT 1
+);
+ALTER TABLE nilai
+    ALTER COLUMN id_semester SET NOT NULL,
+    DROP CONSTRAINT nilai_id_siswa_id_mapel_key,
+    ADD CONSTRAINT nilai_id_siswa_id_mapel_id_semester_key UNIQUE (id_siswa, id_mapel, id_semester);
+CREATE INDEX nilai_id_semester_idx ON nilai (id_semester);
@@ -0,0 +1 @@
+DROP TABLE riwayat_kelas;
@@ -0,0 +1,30 @@
+-- Riwayat kelas siswa. siswa.id_kelas tetap menunjuk kelas saat ini; setiap perpindahan
+-- menutup baris riwayat yang terbuka (tanggal_keluar + status) dan membuka baris baru.
+--   aktif   - kelas siswa saat ini, tanggal_keluar masih kosong
+--   naik    - naik ke tingkat berikutnya lewat kenaikan kelas
+--   tinggal - tinggal kelas, mengulang tingkat yang sama di tahun ajaran berikutnya
+--   pindah  - dipindah ke kelas lain
+-- Tahun ajaran riwayat diambil dari kelasnya.
+CREATE TABLE riwayat_kelas (
+    id_riwayat     SERIAL PRIMARY KEY,
+    id_siswa       INTEGER     NOT NULL REFERENCES siswa (id_siswa) ON DELETE CASCADE,
+    id_kelas       INTEGER     NOT NULL REFERENCES kelas (id_kelas) ON DELETE CASCADE,
+    tanggal_masuk  DATE        NOT NULL DEFAULT CURRENT_DATE,
+    tanggal_keluar DATE,
+    status         VARCHAR(20) NOT NULL DEFAULT 'aktif',
+    CONSTRAINT riwayat_kelas_status_check CHECK (status IN ('aktif', 'naik', 'tinggal', 'pindah')),
+    CONSTRAINT riwayat_kelas_tanggal_check CHECK (tanggal_keluar IS NULL OR tanggal_keluar >= tanggal_masuk),
+    CONSTRAINT riwayat_kelas_aktif_check CHECK ((status = 'aktif') = (tanggal_keluar IS NULL))
+);
+CREATE INDEX riwayat_kelas_id_siswa_idx ON riwayat_kelas (id_siswa);
+CREATE INDEX riwayat_kelas_id_kelas_idx ON riwayat_kelas (id_kelas);
+-- Paling banyak satu kelas aktif per siswa
+CREATE UNIQUE INDEX riwayat_kelas_aktif_key ON riwayat_kelas (id_siswa) WHERE tanggal_keluar IS NULL;
+
+-- Kelas siswa saat ini menjadi riwayat aktif sejak awal tahun ajaran kelasnya
+INSERT INTO riwayat_kelas (id_siswa, id_kelas, tanggal_masuk)
+SELECT s.id_siswa, s.id_kelas, LEAST(COALESCE(MIN(sm.tanggal_mulai), CURRENT_DATE), CURRENT_DATE)
+FROM siswa s
+JOIN kelas k ON k.id_kelas = s.id_kelas
+LEFT JOIN semester sm ON sm.id_tahun_ajaran = k.id_tahun_ajaran
+GROUP BY s.id_siswa, s.id_kelas;
@@ -0,0 +1,10 @@
+-- Riwayat yang ditutup karena siswa meninggalkan sekolah dicatat kembali sebagai pindah
+UPDATE riwayat_kelas SET status = 'pindah' WHERE status IN ('lulus', 'pindah_sekolah', 'keluar');
+ALTER TABLE riwayat_kelas DROP CONSTRAINT riwayat_kelas_status_check;
+ALTER TABLE riwayat_kelas
+    ADD CONSTRAINT riwayat_kelas_status_check CHECK (status IN ('aktif', 'naik', 'tinggal', 'pindah'));
+
+ALTER TABLE siswa
+    DROP COLUMN keterangan,
+    DROP COLUMN tanggal_status,
+    DROP COLUMN status;
@@ -0,0 +1,20 @@
+-- Status siswa menggantikan DELETE untuk siswa yang sudah tidak bersekolah, supaya nilai dan
+-- riwayatnya tetap ada. tanggal_status adalah tanggal status berlaku (kosong untuk siswa aktif
+-- yang belum pernah berubah status). id_kelas siswa nonaktif tetap menunjuk kelas terakhirnya.
+--   aktif  - masih bersekolah
+--   lulus  - alumni
+--   pindah - pindah ke sekolah lain (keterangan: sekolah tujuan)
+--   keluar - berhenti / dikeluarkan (keterangan: alasan)
+ALTER TABLE siswa
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'aktif',
+    ADD COLUMN tanggal_status DATE,
+    ADD COLUMN keterangan TEXT NOT NULL DEFAULT '',
+    ADD CONSTRAINT siswa_status_check CHECK (status IN ('aktif', 'lulus', 'pindah', 'keluar')),
+    ADD CONSTRAINT siswa_tanggal_status_check CHECK (status = 'aktif' OR tanggal_status IS NOT NULL);
+CREATE INDEX siswa_status_idx ON siswa (status);
+
+-- Riwayat kelas siswa yang meninggalkan sekolah ditutup dengan status yang sesuai
+ALTER TABLE riwayat_kelas DROP CONSTRAINT riwayat_kelas_status_check;
+ALTER TABLE riwayat_kelas
+    ADD CONSTRAINT riwayat_kelas_status_check
+        CHECK (status IN ('aktif', 'naik', 'tinggal', 'pindah', 'lulus', 'pindah_sekolah', 'keluar'));
@@ -0,0 +1,19 @@
+-- Absensi siswa. id_mapel kosong berarti absensi harian (dicatat wali kelas), terisi berarti absensi
+-- per jam pelajaran mapel tersebut. Satu siswa paling banyak satu catatan harian per tanggal dan
+-- satu catatan per mapel per tanggal; mencatat ulang menimpa catatan sebelumnya.
+-- id_kelas disimpan supaya rekap kelas lama tetap benar setelah siswa naik/pindah kelas.
+CREATE TABLE absensi (
+    id_absensi   SERIAL PRIMARY KEY,
+    id_siswa     INTEGER     NOT NULL REFERENCES siswa (id_siswa) ON DELETE CASCADE,
+    id_kelas     INTEGER     NOT NULL REFERENCES kelas (id_kelas) ON DELETE CASCADE,
+    id_mapel     INTEGER     REFERENCES mata_pelajaran (id_mapel) ON DELETE CASCADE,
+    tanggal      DATE        NOT NULL,
+    status       VARCHAR(10) NOT NULL,
+    keterangan   TEXT        NOT NULL DEFAULT '',
+    dicatat_oleh INTEGER     REFERENCES "user" (id_user) ON DELETE SET NULL,
+    dicatat_pada TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT absensi_status_check CHECK (status IN ('hadir', 'sakit', 'izin', 'alpa'))
+);
+CREATE UNIQUE INDEX absensi_harian_key ON absensi (id_siswa, tanggal) WHERE id_mapel IS NULL;
+CREATE UNIQUE INDEX absensi_mapel_key ON absensi (id_siswa, id_mapel, tanggal) WHERE id_mapel IS NOT NULL;
+CREATE INDEX absensi_id_kelas_tanggal_idx ON absensi (id_kelas, tanggal);
@@ -0,0 +1,163 @@
+// Package jobs - Antrian pekerjaan latar belakang di memori untuk proses yang terlalu lama
+// untuk satu request HTTP (misalnya ekspor rapor satu kelas). Hasil hilang saat server restart.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Status sebuah job
+const (
+	StatusAntri    = "antri"
+	StatusBerjalan = "berjalan"
+	StatusSelesai  = "selesai"
+	StatusGagal    = "gagal"
+)
+
+// Result - File hasil job yang bisa diunduh
+type Result struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Progress - Dipanggil job setiap kali satu unit pekerjaan selesai
+type Progress func(done, total int)
+
+// Func - Isi pekerjaan. ctx dibatalkan jika job melewati batas waktunya.
+type Func func(ctx context.Context, progress Progress) (Result, error)
+
+// Job - Keadaan satu job. Salinan yang dikembalikan Store aman dibaca tanpa lock.
+type Job struct {
+	ID       string     `json:"id_job"`
+	Jenis    string     `json:"jenis"`
+	Status   string     `json:"status"`
+	Selesai  int        `json:"selesai"`
+	Total    int        `json:"total"`
+	Error    string     `json:"error,omitempty"`
+	Filename string     `json:"filename,omitempty"`
+	Dibuat   time.Time  `json:"dibuat"`
+	Berakhir *time.Time `json:"berakhir,omitempty"`
+	IDUser   int        `json:"-"` // pemilik job, hanya dia (dan admin) yang boleh melihat hasilnya
+	result   *Result
+}
+
+// Store - Menyimpan job di memori. Job yang sudah berakhir dibuang setelah ttl.
+type Store struct {
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	ttl     time.Duration
+	timeout time.Duration
+	slots   chan struct{}
+}
+
+// NewStore - maxParallel job berjalan bersamaan, sisanya menunggu dengan status antri
+func NewStore(ttl, timeout time.Duration, maxParallel int) *Store {
+	return &Store{
+		jobs:    map[string]*Job{},
+		ttl:     ttl,
+		timeout: timeout,
+		slots:   make(chan struct{}, maxParallel),
+	}
+}
+
+// Start - Mendaftarkan job baru dan menjalankannya di goroutine terpisah
+func (s *Store) Start(idUser int, jenis string, fn Func) Job {
+	job := &Job{ID: newID(), Jenis: jenis, Status: StatusAntri, Dibuat: time.Now(), IDUser: idUser}
+
+	s.mu.Lock()
+	s.cleanup()
+	s.jobs[job.ID] = job
+	snapshot := *job
+	s.mu.Unlock()
+
+	go s.run(job, fn)
+	return snapshot
+}
+
+// Get - Salinan keadaan job, false jika tidak ada atau sudah kedaluwarsa
+func (s *Store) Get(id string) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cleanup()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// Result - File hasil job yang sudah selesai, false jika job belum selesai atau gagal
+func (s *Store) Result(id string) (Result, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok || job.result == nil {
+		return Result{}, false
+	}
+	return *job.result, true
+}
+
+func (s *Store) run(job *Job, fn Func) {
+	s.slots <- struct{}{}
+	defer func() { <-s.slots }()
+
+	s.update(job, func(j *Job) { j.Status = StatusBerjalan })
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	result, err := safeRun(ctx, fn, func(done, total int) {
+		s.update(job, func(j *Job) { j.Selesai, j.Total = done, total })
+	})
+
+	s.update(job, func(j *Job) {
+		now := time.Now()
+		j.Berakhir = &now
+		if err != nil {
+			j.Status, j.Error = StatusGagal, err.Error()
+			return
+		}
+		j.Status, j.Filename, j.result = StatusSelesai, result.Filename, &result
+	})
+}
+
+// safeRun - Panic di dalam job dicatat sebagai job gagal, bukan menjatuhkan server
+func safeRun(ctx context.Context, fn Func, progress Progress) (result Result, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("job panic: %v\n%s", p, debug.Stack())
+			err = errors.New("job berhenti karena kesalahan internal")
+		}
+	}()
+	return fn(ctx, progress)
+}
+
+func (s *Store) update(job *Job, fn func(*Job)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(job)
+}
+
+// cleanup - Membuang job yang sudah berakhir lebih dari ttl. Dipanggil dengan mu terkunci.
+func (s *Store) cleanup() {
+	for id, job := range s.jobs {
+		if job.Berakhir != nil && time.Since(*job.Berakhir) > s.ttl {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
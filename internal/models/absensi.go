@@ -0,0 +1,144 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+)
+
+// Status absensi
+const (
+	AbsensiHadir = "hadir"
+	AbsensiSakit = "sakit"
+	AbsensiIzin  = "izin"
+	AbsensiAlpa  = "alpa" // tanpa keterangan
+)
+
+// Absensi - Kehadiran seorang siswa pada satu tanggal. IDMapel null untuk absensi harian.
+type Absensi struct {
+	IDAbsensi  int    `json:"id_absensi"`
+	IDSiswa    int    `json:"id_siswa"`
+	NamaSiswa  string `json:"nama_siswa"`
+	IDKelas    int    `json:"id_kelas"`
+	IDMapel    *int   `json:"id_mapel"`
+	NamaMapel  string `json:"nama_mapel,omitempty"`
+	Tanggal    string `json:"tanggal"` // Format YYYY-MM-DD
+	Status     string `json:"status"`
+	Keterangan string `json:"keterangan"`
+}
+
+// AbsensiBulk - Absensi satu kelas (atau satu mapel) pada satu tanggal. Jika SisanyaHadir, siswa aktif
+// kelas yang tidak ada di Absensi dicatat hadir, jadi guru cukup mengirim siswa yang tidak hadir.
+type AbsensiBulk struct {
+	IDKelas      int            `json:"id_kelas"`
+	IDMapel      *int           `json:"id_mapel,omitempty"`
+	Tanggal      string         `json:"tanggal"` // Format YYYY-MM-DD, default hari ini
+	SisanyaHadir bool           `json:"sisanya_hadir"`
+	Absensi      []AbsensiSiswa `json:"absensi"`
+}
+
+// AbsensiSiswa - Satu baris absensi bulk
+type AbsensiSiswa struct {
+	IDSiswa    int    `json:"id_siswa"`
+	Status     string `json:"status"`
+	Keterangan string `json:"keterangan"`
+}
+
+// ValidStatusAbsensi - true jika status salah satu status absensi
+func ValidStatusAbsensi(status string) bool {
+	switch status {
+	case AbsensiHadir, AbsensiSakit, AbsensiIzin, AbsensiAlpa:
+		return true
+	}
+	return false
+}
+
+// ValidateTanggal - Tanggal kosong diisi hari ini; absensi tidak boleh untuk tanggal yang belum lewat
+func (b *AbsensiBulk) ValidateTanggal() error {
+	today := time.Now().Format(TanggalLayout)
+	if b.Tanggal == "" {
+		b.Tanggal = today
+		return nil
+	}
+	t, err := time.Parse(TanggalLayout, strings.TrimSpace(b.Tanggal))
+	if err != nil {
+		return errors.New("tanggal harus berformat YYYY-MM-DD")
+	}
+	b.Tanggal = t.Format(TanggalLayout)
+	if b.Tanggal > today {
+		return errors.New("absensi tidak bisa dicatat untuk tanggal yang akan datang")
+	}
+	return nil
+}
+
+// RekapAbsensi - Jumlah catatan absensi per status dalam satu rentang tanggal
+type RekapAbsensi struct {
+	Hadir           int     `json:"hadir"`
+	Sakit           int     `json:"sakit"`
+	Izin            int     `json:"izin"`
+	Alpa            int     `json:"alpa"`
+	Total           int     `json:"total"`
+	PersentaseHadir float64 `json:"persentase_hadir"` // 0-100, dibulatkan 2 desimal; 0 jika belum ada catatan
+}
+
+// Hitung - Mengisi Total dan PersentaseHadir dari jumlah per status
+func (r *RekapAbsensi) Hitung() {
+	r.Total = r.Hadir + r.Sakit + r.Izin + r.Alpa
+	r.PersentaseHadir = 0
+	if r.Total > 0 {
+		r.PersentaseHadir = math.Round(float64(r.Hadir)/float64(r.Total)*10000) / 100
+	}
+}
+
+// Tambah - Menjumlahkan rekap lain ke r
+func (r *RekapAbsensi) Tambah(o RekapAbsensi) {
+	r.Hadir += o.Hadir
+	r.Sakit += o.Sakit
+	r.Izin += o.Izin
+	r.Alpa += o.Alpa
+	r.Hitung()
+}
+
+// RekapAbsensiSiswa - Rekap absensi seorang siswa di rekap kelas
+type RekapAbsensiSiswa struct {
+	IDSiswa   int    `json:"id_siswa"`
+	NISN      string `json:"nisn"`
+	NamaSiswa string `json:"nama_siswa"`
+	RekapAbsensi
+}
+
+// RekapAbsensiMapel - Rekap absensi seorang siswa pada satu mapel
+type RekapAbsensiMapel struct {
+	IDMapel   int    `json:"id_mapel"`
+	NamaMapel string `json:"nama_mapel"`
+	RekapAbsensi
+}
+
+// RekapAbsensiKelas - Rekap absensi satu kelas (harian, atau satu mapel jika IDMapel diisi)
+type RekapAbsensiKelas struct {
+	IDKelas int                 `json:"id_kelas"`
+	IDMapel *int                `json:"id_mapel"`
+	Dari    string              `json:"dari"`
+	Sampai  string              `json:"sampai"`
+	Total   RekapAbsensi        `json:"total"`
+	Siswa   []RekapAbsensiSiswa `json:"siswa"`
+}
+
+// RekapAbsensiPerSiswa - Absensi seorang siswa dalam satu rentang tanggal: rekap harian, rekap per mapel
+// dan semua catatannya
+type RekapAbsensiPerSiswa struct {
+	IDSiswa int                 `json:"id_siswa"`
+	Dari    string              `json:"dari"`
+	Sampai  string              `json:"sampai"`
+	Harian  RekapAbsensi        `json:"harian"`
+	Mapel   []RekapAbsensiMapel `json:"mapel"`
+	Absensi []Absensi           `json:"absensi"`
+}
+
+// AbsensiBulkResponse - Hasil penyimpanan absensi bulk. Jika Errors tidak kosong, tidak ada baris yang tersimpan.
+type AbsensiBulkResponse struct {
+	Tersimpan int          `json:"tersimpan"`
+	Absensi   []Absensi    `json:"absensi"`
+	Errors    []BarisError `json:"errors"`
+}
@@ -0,0 +1,53 @@
+package models
+
+// Status baris dan sel pada pratinjau import nilai
+const (
+	ImportBaru       = "baru"        // siswa belum punya nilai untuk komponen ini
+	ImportBerubah    = "berubah"     // nilai di file berbeda dengan nilai yang tersimpan
+	ImportSama       = "sama"        // nilai di file sama dengan yang tersimpan
+	ImportKosong     = "kosong"      // sel kosong, nilai yang tersimpan tidak diubah
+	ImportCocok      = "cocok"       // NISN ditemukan di kelas mapel
+	ImportTidakCocok = "tidak_cocok" // NISN tidak ditemukan di kelas mapel, baris dilewati
+	ImportError      = "error"       // baris atau sel tidak valid, import tidak bisa disimpan
+)
+
+// ImportNilaiPreview - Perbandingan isi file nilai dengan penilaian yang tersimpan untuk satu mapel
+type ImportNilaiPreview struct {
+	IDMapel        int                 `json:"id_mapel"`
+	IDSemester     int                 `json:"id_semester"`
+	Komponen       []KomponenPenilaian `json:"komponen"`        // komponen yang kolomnya ditemukan di file
+	KolomDiabaikan []string            `json:"kolom_diabaikan"` // judul kolom yang bukan NISN, nama, atau komponen
+	Baris          []ImportNilaiBaris  `json:"baris"`
+	Ringkasan      ImportRingkasan     `json:"ringkasan"`
+	Tersimpan      bool                `json:"tersimpan"`
+}
+
+// ImportNilaiBaris - Satu baris file, Baris sesuai nomor baris di spreadsheet
+type ImportNilaiBaris struct {
+	Baris     int              `json:"baris"`
+	NISN      string           `json:"nisn"`
+	IDSiswa   *int             `json:"id_siswa"`
+	NamaSiswa string           `json:"nama_siswa,omitempty"`
+	Status    string           `json:"status"`
+	Error     string           `json:"error,omitempty"`
+	Nilai     []ImportNilaiSel `json:"nilai"`
+}
+
+// ImportNilaiSel - Nilai satu komponen pada satu baris
+type ImportNilaiSel struct {
+	IDKomponen   int    `json:"id_komponen"`
+	NamaKomponen string `json:"nama_komponen"`
+	Lama         *int   `json:"lama"`
+	Baru         *int   `json:"baru"`
+	Status       string `json:"status"`
+	Error        string `json:"error,omitempty"`
+}
+
+// ImportRingkasan - Jumlah sel per status (baris cocok saja) dan jumlah baris yang tidak cocok atau error
+type ImportRingkasan struct {
+	Baru       int `json:"baru"`
+	Berubah    int `json:"berubah"`
+	Sama       int `json:"sama"`
+	TidakCocok int `json:"tidak_cocok"`
+	Error      int `json:"error"`
+}
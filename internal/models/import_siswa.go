@@ -0,0 +1,40 @@
+package models
+
+// ImportValid - Baris roster siap disimpan
+const ImportValid = "valid"
+
+// ImportSiswaPreview - Hasil validasi file roster sebelum akun siswa dibuat
+type ImportSiswaPreview struct {
+	KolomDiabaikan []string           `json:"kolom_diabaikan"`
+	Baris          []ImportSiswaBaris `json:"baris"`
+	Ringkasan      ImportSiswaJumlah  `json:"ringkasan"`
+}
+
+// ImportSiswaBaris - Satu siswa di file roster. TanggalLahir sudah dinormalkan ke TanggalLayout.
+type ImportSiswaBaris struct {
+	Baris        int    `json:"baris"`
+	NamaSiswa    string `json:"nama_siswa"`
+	NISN         string `json:"nisn"`
+	TanggalLahir string `json:"tanggal_lahir"`
+	Alamat       string `json:"alamat"`
+	Kelas        string `json:"kelas"`
+	IDKelas      *int   `json:"id_kelas"`
+	Status       string `json:"status"`
+	Error        string `json:"error,omitempty"`
+}
+
+// ImportSiswaJumlah - Jumlah baris valid dan error di file roster
+type ImportSiswaJumlah struct {
+	Valid int `json:"valid"`
+	Error int `json:"error"`
+}
+
+// AkunSiswa - Satu baris lembar akun yang dibagikan ke siswa setelah import roster
+type AkunSiswa struct {
+	IDSiswa   int
+	NISN      string
+	NamaSiswa string
+	Kelas     string
+	Username  string
+	Password  string
+}
@@ -5,6 +5,7 @@ type Kelas struct {
     IDKelas      int       `json:"id_kelas"`
 	IDGuru       int       `json:"id_guru"`
     NamaKelas    string    `json:"nama_kelas"`
-	TahunAjaran  string    `json:"tahun_ajaran"`
+	IDTahunAjaran int      `json:"id_tahun_ajaran"`
+	TahunAjaran  string    `json:"tahun_ajaran"` // nama tahun ajaran; saat membuat kelas boleh diisi sebagai ganti id_tahun_ajaran
 	JumlahSiswa  int    `json:"jumlah_siswa"`
 }
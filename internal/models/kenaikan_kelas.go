@@ -0,0 +1,92 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TingkatMaks - Tingkat kelas tertinggi (kelas XII / 12); kelas ini tidak punya tingkat berikutnya
+const TingkatMaks = 12
+
+// KenaikanKelasInput - Permintaan kenaikan satu kelas ke tahun ajaran berikutnya. Semua field boleh kosong:
+// tahun ajaran tujuan default tahun ajaran setelah tahun ajaran kelas asal, kelas tujuan default kelas
+// bernama tingkat berikutnya (contoh "VII A" jadi "VIII A") di tahun ajaran tujuan, dibuat jika belum ada.
+type KenaikanKelasInput struct {
+	IDTahunAjaran int            `json:"id_tahun_ajaran"`
+	IDKelasTujuan int            `json:"id_kelas_tujuan"`
+	Tanggal       string         `json:"tanggal"` // Format YYYY-MM-DD, default hari ini
+	TinggalKelas  []TinggalKelas `json:"tinggal_kelas"`
+}
+
+// TinggalKelas - Siswa yang tidak naik. IDKelas kosong berarti kelas bernama sama dengan kelas asal
+// di tahun ajaran tujuan.
+type TinggalKelas struct {
+	IDSiswa int `json:"id_siswa"`
+	IDKelas int `json:"id_kelas"`
+}
+
+// KenaikanKelas - Rencana (atau hasil jika Tersimpan) kenaikan kelas. Kelas tujuan yang belum ada
+// ditampilkan dengan id_kelas 0 dan baru dibuat saat disimpan.
+type KenaikanKelas struct {
+	KelasAsal     Kelas           `json:"kelas_asal"`
+	IDTahunAjaran int             `json:"id_tahun_ajaran"`
+	TahunAjaran   string          `json:"tahun_ajaran"`
+	Tanggal       string          `json:"tanggal"`
+	KelasTujuan   Kelas           `json:"kelas_tujuan"`
+	Siswa         []KenaikanSiswa `json:"siswa"`
+	JumlahNaik    int             `json:"jumlah_naik"`
+	JumlahTinggal int             `json:"jumlah_tinggal"`
+	Tersimpan     bool            `json:"tersimpan"`
+}
+
+// KenaikanSiswa - Kelas tujuan seorang siswa. Status RiwayatNaik atau RiwayatTinggal.
+type KenaikanSiswa struct {
+	IDSiswa   int    `json:"id_siswa"`
+	NISN      string `json:"nisn"`
+	NamaSiswa string `json:"nama_siswa"`
+	Status    string `json:"status"`
+	IDKelas   int    `json:"id_kelas"`
+	NamaKelas string `json:"nama_kelas"`
+}
+
+var (
+	romawi        = []string{"", "I", "II", "III", "IV", "V", "VI", "VII", "VIII", "IX", "X", "XI", "XII"}
+	tingkatPrefix = `(?i)^(kelas\s+)?`
+	// Angka romawi harus diikuti bukan-huruf supaya "XA" tidak terbaca sebagai "X" + "A" yang ambigu
+	tingkatRomawi = regexp.MustCompile(tingkatPrefix + `(XII|XI|X|IX|VIII|VII|VI|V|IV|III|II|I)([^A-Za-z].*)?$`)
+	tingkatAngka  = regexp.MustCompile(tingkatPrefix + `(\d{1,2})(\D.*)?$`)
+)
+
+// NamaKelasBerikutnya - Nama kelas satu tingkat di atas nama, contoh "VII A" jadi "VIII A" dan
+// "10 IPA 1" jadi "11 IPA 1". false jika tingkatnya tidak dikenali atau sudah TingkatMaks.
+func NamaKelasBerikutnya(nama string) (string, bool) {
+	nama = strings.TrimSpace(nama)
+	if m := tingkatRomawi.FindStringSubmatch(nama); m != nil {
+		for tingkat, r := range romawi {
+			if strings.EqualFold(r, m[2]) && tingkat > 0 && tingkat < TingkatMaks {
+				return m[1] + romawi[tingkat+1] + m[3], true
+			}
+		}
+		return "", false
+	}
+	if m := tingkatAngka.FindStringSubmatch(nama); m != nil {
+		tingkat, _ := strconv.Atoi(m[2])
+		if tingkat < 1 || tingkat >= TingkatMaks {
+			return "", false
+		}
+		return m[1] + strconv.Itoa(tingkat+1) + m[3], true
+	}
+	return "", false
+}
+
+// TahunAjaranBerikutnya - "2025/2026" jadi "2026/2027"; false jika nama bukan format YYYY/YYYY
+func TahunAjaranBerikutnya(nama string) (string, bool) {
+	t := TahunAjaran{Nama: nama}
+	if t.Validate() != nil {
+		return "", false
+	}
+	awal, _ := strconv.Atoi(t.Nama[:4])
+	return fmt.Sprintf("%d/%d", awal+1, awal+2), true
+}
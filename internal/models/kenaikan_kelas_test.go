@@ -0,0 +1,72 @@
+package models
+
+import "testing"
+
+func TestNamaKelasBerikutnya(t *testing.T) {
+	tests := []struct {
+		nama   string
+		want   string
+		wantOK bool
+	}{
+		{"X IPA 1", "XI IPA 1", true},
+		{"XI IPS 2", "XII IPS 2", true},
+		{"VII A", "VIII A", true},
+		{"VIII-B", "IX-B", true},
+		{"IX", "X", true},
+		{"I", "II", true},
+		{"IV C", "V C", true},
+		{"Kelas VII A", "Kelas VIII A", true},
+		{"kelas x ipa 1", "kelas XI ipa 1", true},
+		{"  X IPA 1  ", "XI IPA 1", true},
+		{"10 IPA 1", "11 IPA 1", true},
+		{"7A", "8A", true},
+		{"9", "10", true},
+		{"11-IPS", "12-IPS", true},
+		{"Kelas 1", "Kelas 2", true},
+
+		// Tingkat akhir tidak punya kelas berikutnya
+		{"XII", "", false},
+		{"XII IPA 1", "", false},
+		{"12 IPS 3", "", false},
+		{"Kelas XII", "", false},
+
+		// Tingkat tidak dikenali
+		{"", "", false},
+		{"IPA 1", "", false},
+		{"XA", "", false},
+		{"XIII IPA", "", false},
+		{"0 A", "", false},
+		{"13 IPA", "", false},
+		{"123", "", false},
+		{"Kelas", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := NamaKelasBerikutnya(tt.nama)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("NamaKelasBerikutnya(%q) = %q, %v, want %q, %v", tt.nama, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestTahunAjaranBerikutnya(t *testing.T) {
+	tests := []struct {
+		nama   string
+		want   string
+		wantOK bool
+	}{
+		{"2025/2026", "2026/2027", true},
+		{" 2025 / 2026 ", "2026/2027", true},
+		{"1999/2000", "2000/2001", true},
+		{"2025/2027", "", false},
+		{"2025-2026", "", false},
+		{"2025", "", false},
+		{"", "", false},
+		{"25/26", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := TahunAjaranBerikutnya(tt.nama)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("TahunAjaranBerikutnya(%q) = %q, %v, want %q, %v", tt.nama, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// KomponenPenilaian - Komponen penilaian (misalnya "UTS 30%") yang berlaku untuk semua siswa satu mapel
+type KomponenPenilaian struct {
+	IDKomponen   int     `json:"id_komponen"`
+	IDMapel      int     `json:"id_mapel"`
+	NamaKomponen string  `json:"nama_komponen"`
+	Bobot        string  `json:"bobot"`
+	NilaiMaks    int     `json:"nilai_maks"`
+	Tenggat      *string `json:"tenggat"` // Format YYYY-MM-DD, null jika tidak ada tenggat
+}
+
+// KomponenNilai - Satu komponen mapel beserta nilai seorang siswa, IDPenilaian dan Nilai null jika belum dinilai
+type KomponenNilai struct {
+	KomponenPenilaian
+	IDPenilaian *int `json:"id_penilaian"`
+	Nilai       *int `json:"nilai"`
+}
+
+// TanggalLayout - Format tanggal yang dipakai di request/response
+const TanggalLayout = "2006-01-02"
+
+// Validate - Memeriksa isi komponen dan mengembalikan bobotnya dalam bentuk desimal
+func (k *KomponenPenilaian) Validate() (float64, error) {
+	k.NamaKomponen = strings.TrimSpace(k.NamaKomponen)
+	if k.NamaKomponen == "" {
+		return 0, errors.New("nama_komponen wajib diisi")
+	}
+
+	bobot, err := ParseBobot(k.Bobot)
+	if err != nil {
+		return 0, errors.New("format bobot tidak valid")
+	}
+	if err := ValidateBobot(bobot); err != nil {
+		return 0, err
+	}
+
+	if k.NilaiMaks == 0 {
+		k.NilaiMaks = NilaiMax
+	}
+	if k.NilaiMaks < 0 {
+		return 0, errors.New("nilai_maks harus lebih dari 0")
+	}
+
+	if k.Tenggat != nil && *k.Tenggat != "" {
+		if _, err := time.Parse(TanggalLayout, *k.Tenggat); err != nil {
+			return 0, fmt.Errorf("tenggat harus berformat %s", "YYYY-MM-DD")
+		}
+	} else {
+		k.Tenggat = nil
+	}
+	return bobot, nil
+}
+
+// Range - Rentang nilai komponen, contoh "0 - 20"
+func (k KomponenPenilaian) Range() string {
+	return fmt.Sprintf("%d - %d", NilaiMin, k.NilaiMaks)
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKomponenValidate(t *testing.T) {
+	tanggal := func(s string) *string { return &s }
+	tests := []struct {
+		name        string
+		in          KomponenPenilaian
+		wantBobot   float64
+		wantMaks    int
+		wantTenggat bool
+		wantErr     bool
+	}{
+		{"lengkap", KomponenPenilaian{NamaKomponen: " UTS ", Bobot: "30%", NilaiMaks: 50, Tenggat: tanggal("2025-10-01")}, 0.3, 50, true, false},
+		{"nilai_maks kosong jadi 100", KomponenPenilaian{NamaKomponen: "UTS", Bobot: "30"}, 0.3, NilaiMax, false, false},
+		{"tenggat kosong jadi null", KomponenPenilaian{NamaKomponen: "UTS", Bobot: "30%", Tenggat: tanggal("")}, 0.3, NilaiMax, false, false},
+		{"bobot 100%", KomponenPenilaian{NamaKomponen: "UAS", Bobot: "100%"}, 1, NilaiMax, false, false},
+		{"bobot 0.01%", KomponenPenilaian{NamaKomponen: "Kuis", Bobot: "0.01%"}, 0.0001, NilaiMax, false, false},
+		{"nama kosong", KomponenPenilaian{NamaKomponen: "  ", Bobot: "30%"}, 0, 0, false, true},
+		{"bobot bukan angka", KomponenPenilaian{NamaKomponen: "UTS", Bobot: "tiga puluh"}, 0, 0, false, true},
+		{"bobot 0%", KomponenPenilaian{NamaKomponen: "UTS", Bobot: "0%"}, 0, 0, false, true},
+		{"bobot negatif", KomponenPenilaian{NamaKomponen: "UTS", Bobot: "-10%"}, 0, 0, false, true},
+		{"bobot lebih dari 100%", KomponenPenilaian{NamaKomponen: "UTS", Bobot: "100.01%"}, 0, 0, false, true},
+		{"nilai_maks negatif", KomponenPenilaian{NamaKomponen: "UTS", Bobot: "30%", NilaiMaks: -1}, 0, 0, false, true},
+		{"tenggat salah format", KomponenPenilaian{NamaKomponen: "UTS", Bobot: "30%", Tenggat: tanggal("01-10-2025")}, 0, 0, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := tt.in
+			bobot, err := k.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if BobotPoints(bobot) != BobotPoints(tt.wantBobot) {
+				t.Errorf("bobot = %v, want %v", bobot, tt.wantBobot)
+			}
+			if k.NilaiMaks != tt.wantMaks {
+				t.Errorf("nilai_maks = %d, want %d", k.NilaiMaks, tt.wantMaks)
+			}
+			if (k.Tenggat != nil) != tt.wantTenggat {
+				t.Errorf("tenggat = %v, want ada %v", k.Tenggat, tt.wantTenggat)
+			}
+			if want := strings.TrimSpace(tt.in.NamaKomponen); k.NamaKomponen != want {
+				t.Errorf("nama_komponen = %q, want %q", k.NamaKomponen, want)
+			}
+		})
+	}
+}
+
+// TestKomponenBobotTotal - Total bobot komponen satu mapel paling banyak 100%, dibandingkan dalam poin
+// seperti checkBobotKomponen supaya pecahan seperti 33.33% + 33.33% + 33.34% tetap muat
+func TestKomponenBobotTotal(t *testing.T) {
+	tests := []struct {
+		name  string
+		bobot []string
+		muat  bool
+	}{
+		{"pas 100%", []string{"30%", "30%", "40%"}, true},
+		{"tiga pertiga", []string{"33.33%", "33.33%", "33.34%"}, true},
+		{"sepersepuluh", []string{"10%", "10%", "10%", "10%", "10%", "10%", "10%", "10%", "10%", "10%"}, true},
+		{"kurang dari 100%", []string{"20%", "30%"}, true},
+		{"lebih 0.01%", []string{"50%", "50.01%"}, false},
+		{"sudah penuh", []string{"100%", "0.01%"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var total int64
+			for _, s := range tt.bobot {
+				k := KomponenPenilaian{NamaKomponen: "Tugas", Bobot: s}
+				bobot, err := k.Validate()
+				if err != nil {
+					t.Fatalf("Validate(%q) error = %v", s, err)
+				}
+				total += BobotPoints(bobot)
+			}
+			if muat := total <= BobotPoints(BobotMax); muat != tt.muat {
+				t.Errorf("total %d poin muat = %v, want %v", total, muat, tt.muat)
+			}
+		})
+	}
+}
+
+func TestKomponenRange(t *testing.T) {
+	if got := (KomponenPenilaian{NilaiMaks: 20}).Range(); got != "0 - 20" {
+		t.Errorf("Range() = %q, want %q", got, "0 - 20")
+	}
+}
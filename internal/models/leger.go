@@ -0,0 +1,22 @@
+package models
+
+// Leger - Matriks nilai satu kelas pada satu semester: satu baris per siswa, satu kolom per mata pelajaran
+type Leger struct {
+	Kelas    Kelas           `json:"kelas"`
+	Semester Semester        `json:"semester"`
+	Mapel    []MataPelajaran `json:"mapel"`
+	Baris    []LegerBaris    `json:"baris"`
+}
+
+// LegerBaris - Nilai seorang siswa, urutan Nilai sama dengan Leger.Mapel (null jika belum dinilai).
+// Peringkat 0 untuk siswa yang belum punya nilai sama sekali.
+type LegerBaris struct {
+	IDSiswa   int          `json:"id_siswa"`
+	NISN      string       `json:"nisn"`
+	NamaSiswa string       `json:"nama_siswa"`
+	Nilai     []*float64   `json:"nilai"`
+	RataRata  *float64     `json:"rata_rata"`
+	Peringkat int          `json:"peringkat"`
+	Absensi   RekapAbsensi `json:"absensi"` // absensi harian selama semester
+	Lulus     bool         `json:"lulus"`   // semua mapel sudah dinilai dan tidak ada yang di bawah KKM mapelnya
+}
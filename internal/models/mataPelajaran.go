@@ -5,4 +5,17 @@ type MataPelajaran struct {
     IDMapel       		  int       `json:"id_mapel"`
     IDKelas               int       `json:"id_kelas"`
     NamaMataPelajaran     string    `json:"nama_mata_pelajaran"`
+    KKM                   float64   `json:"kkm"`            // total_nilai minimal untuk lulus
+    SkalaPredikat         SkalaPredikat `json:"skala_predikat"`
+    AturanRemedial        string    `json:"aturan_remedial"` // ganti, batas_kkm atau maks
+}
+
+// MataPelajaranDetail - Ringkasan mapel beserta guru pengajar dan jumlah siswa di kelasnya
+type MataPelajaranDetail struct {
+	IDMapel           int    `json:"id_mapel"`
+	IDKelas           int    `json:"id_kelas"`
+	NamaMataPelajaran string `json:"nama_mata_pelajaran"`
+	NamaGuru          string `json:"nama_guru"`
+	TahunAjaran       string `json:"tahun_ajaran"`
+	JumlahSiswa       int    `json:"jumlah_siswa"`
 }
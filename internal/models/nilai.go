@@ -0,0 +1,21 @@
+package models
+
+// Nilai - Nilai akhir seorang siswa untuk satu mata pelajaran
+type Nilai struct {
+	IDNilai    int     `json:"id_nilai"`
+	IDSiswa    int     `json:"id_siswa"`
+	IDMapel    int     `json:"id_mapel"`
+	IDSemester int     `json:"id_semester"`
+	TotalNilai float64 `json:"total_nilai"`
+}
+
+// NilaiMapel - Satu baris daftar nilai siswa per mata pelajaran
+type NilaiMapel struct {
+	ID       int           `json:"id_nilai"`
+	Nilai    float64       `json:"nilai"`
+	Mapel    string        `json:"mapel"`
+	KKM      float64       `json:"kkm"`
+	Predikat string        `json:"predikat"`
+	Lulus    bool          `json:"lulus"`
+	Skala    SkalaPredikat `json:"-"` // skala predikat mapel, bahan mengisi Predikat
+}
@@ -1,12 +1,72 @@
 package models
 
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Batas nilai dan bobot sebuah penilaian
+const (
+	NilaiMin = 0
+	NilaiMax = 100
+	// BobotMax - Total bobot semua penilaian di bawah satu nilai tidak boleh melebihi 100%
+	BobotMax = 1.0
+)
+
+// NilaiRange - Rentang nilai yang ditampilkan ke client, contoh "0 - 100"
+var NilaiRange = fmt.Sprintf("%d - %d", NilaiMin, NilaiMax)
+
 type Penilaian struct {
 	IDPenilaian int     `json:"id_penilaian"`
 	IDNilai     int     `json:"id_nilai"`
 	IDMapel     int     `json:"id_mapel"`
 	IDSiswa     int     `json:"id_siswa"`
+	IDSemester  int     `json:"id_semester"` // 0 saat membuat penilaian: semester aktif/terakhir tahun ajaran kelasnya
 	NamaNilai   string  `json:"nama_nilai"`
 	Nilai       int `json:"nilai"`
 	Bobot       string `json:"bobot"`
     Range string  `json:"range"`
-}
\ No newline at end of file
+	IDKomponen  *int   `json:"id_komponen,omitempty"` // Diisi jika penilaian ini nilai untuk komponen mapel
+}
+
+// FormatBobot - Bobot disimpan sebagai desimal (0.3) dan ditampilkan sebagai persen ("30.00%")
+func FormatBobot(bobot float64) string {
+	return fmt.Sprintf("%.2f%%", bobot*100)
+}
+
+// ParseBobot - Mengubah "30%" atau "30" menjadi 0.3
+func ParseBobot(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "%")), 64)
+	if err != nil {
+		return 0, err
+	}
+	return v / 100, nil
+}
+
+// ValidateNilai - nilai harus berada di dalam NilaiRange
+func ValidateNilai(nilai int) error {
+	return ValidateNilaiMaks(nilai, NilaiMax)
+}
+
+// ValidateNilaiMaks - nilai harus di antara NilaiMin dan nilai maksimum komponennya
+func ValidateNilaiMaks(nilai, maks int) error {
+	if nilai < NilaiMin || nilai > maks {
+		return fmt.Errorf("nilai harus di antara %d - %d", NilaiMin, maks)
+	}
+	return nil
+}
+
+// ValidateBobot - bobot satu penilaian harus lebih dari 0% dan paling banyak 100%
+func ValidateBobot(bobot float64) error {
+	if math.IsNaN(bobot) || bobot <= 0 || BobotPoints(bobot) > BobotPoints(BobotMax) {
+		return fmt.Errorf("bobot harus lebih dari 0%% dan paling banyak %s", FormatBobot(BobotMax))
+	}
+	return nil
+}
+
+// BobotPoints - Bobot dalam satuan 0.01% supaya penjumlahan bobot bisa dibandingkan tanpa galat float
+func BobotPoints(bobot float64) int64 {
+	return int64(math.Round(bobot * 10000))
+}
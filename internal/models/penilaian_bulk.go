@@ -0,0 +1,32 @@
+package models
+
+// PenilaianBulk - Lembar nilai satu penilaian untuk banyak siswa dalam satu mapel.
+// Jika IDKomponen diisi, nama dan bobot diambil dari komponen dan NamaNilai/Bobot diabaikan.
+type PenilaianBulk struct {
+	IDMapel    int          `json:"id_mapel"`
+	IDKomponen *int         `json:"id_komponen,omitempty"`
+	IDSemester *int         `json:"id_semester,omitempty"` // default semester aktif/terakhir tahun ajaran kelas mapel
+	NamaNilai  string       `json:"nama_nilai"`
+	Bobot      string       `json:"bobot"`
+	Nilai      []NilaiSiswa `json:"nilai"`
+}
+
+// NilaiSiswa - Satu baris lembar nilai
+type NilaiSiswa struct {
+	IDSiswa int `json:"id_siswa"`
+	Nilai   int `json:"nilai"`
+}
+
+// BarisError - Kesalahan pada satu baris lembar nilai, Baris dihitung mulai 1
+type BarisError struct {
+	Baris   int    `json:"baris"`
+	IDSiswa int    `json:"id_siswa"`
+	Error   string `json:"error"`
+}
+
+// PenilaianBulkResponse - Hasil penyimpanan lembar nilai. Jika Errors tidak kosong, tidak ada baris yang tersimpan.
+type PenilaianBulkResponse struct {
+	Tersimpan int          `json:"tersimpan"`
+	Penilaian []Penilaian  `json:"penilaian"`
+	Errors    []BarisError `json:"errors"`
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseBobot(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{"30%", 0.3, false},
+		{"30", 0.3, false},
+		{" 12.5 % ", 0.125, false},
+		{"100%", 1, false},
+		{"33.33%", 0.3333, false},
+		{"0", 0, false},
+		{"", 0, true},
+		{"%", 0, true},
+		{"tiga puluh", 0, true},
+		{"30%%", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseBobot(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseBobot(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && BobotPoints(got) != BobotPoints(tt.want) {
+			t.Errorf("ParseBobot(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatBobot(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want string
+	}{
+		{0.3, "30.00%"},
+		{1, "100.00%"},
+		{0.3333, "33.33%"},
+		{0.125, "12.50%"},
+		{0, "0.00%"},
+	}
+	for _, tt := range tests {
+		if got := FormatBobot(tt.in); got != tt.want {
+			t.Errorf("FormatBobot(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBobotPoints(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want int64
+	}{
+		{0.3, 3000},
+		{0.1 + 0.2, 3000}, // 0.30000000000000004
+		{1, 10000},
+		{0.3333, 3333},
+		{0.00005, 1}, // dibulatkan, bukan dipotong
+		{0.00004, 0},
+	}
+	for _, tt := range tests {
+		if got := BobotPoints(tt.in); got != tt.want {
+			t.Errorf("BobotPoints(%v) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestBobotSum - Penjumlahan bobot dibandingkan dalam poin supaya tepat 100% tidak dianggap melebihi batas
+func TestBobotSum(t *testing.T) {
+	tests := []struct {
+		name   string
+		bobot  []string
+		muat   bool
+		sisaPt int64
+	}{
+		{"10% + 20% + 70%", []string{"10%", "20%", "70%"}, true, 0},
+		{"tiga pertiga", []string{"33.33%", "33.33%", "33.34%"}, true, 0},
+		{"sepuluh kali 10%", []string{"10", "10", "10", "10", "10", "10", "10", "10", "10", "10"}, true, 0},
+		{"belum penuh", []string{"25%", "25%"}, true, 5000},
+		{"lebih 0.01%", []string{"50%", "50.01%"}, false, -1},
+		{"lebih jauh", []string{"60%", "60%"}, false, -2000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sum float64
+			var points int64
+			for _, s := range tt.bobot {
+				b, err := ParseBobot(s)
+				if err != nil {
+					t.Fatalf("ParseBobot(%q): %v", s, err)
+				}
+				sum += b
+				points += BobotPoints(b)
+			}
+			if BobotPoints(sum) != points {
+				t.Errorf("BobotPoints(sum) = %d, jumlah poin = %d", BobotPoints(sum), points)
+			}
+			if muat := points <= BobotPoints(BobotMax); muat != tt.muat {
+				t.Errorf("muat = %v, want %v (poin %d)", muat, tt.muat, points)
+			}
+			if sisa := BobotPoints(BobotMax) - points; sisa != tt.sisaPt {
+				t.Errorf("sisa poin = %d, want %d", sisa, tt.sisaPt)
+			}
+		})
+	}
+}
+
+func TestValidateBobot(t *testing.T) {
+	tests := []struct {
+		in      float64
+		wantErr bool
+	}{
+		{0.3, false},
+		{1, false},
+		{1.00004, false}, // 100.00% setelah dibulatkan ke 0.01%
+		{1.0001, true},
+		{0, true},
+		{-0.1, true},
+		{math.NaN(), true},
+	}
+	for _, tt := range tests {
+		if err := ValidateBobot(tt.in); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateBobot(%v) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateNilaiMaks(t *testing.T) {
+	tests := []struct {
+		nilai, maks int
+		wantErr     bool
+	}{
+		{0, 100, false},
+		{100, 100, false},
+		{101, 100, true},
+		{-1, 100, true},
+		{50, 50, false},
+		{51, 50, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateNilaiMaks(tt.nilai, tt.maks); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateNilaiMaks(%d, %d) error = %v, wantErr %v", tt.nilai, tt.maks, err, tt.wantErr)
+		}
+	}
+}
@@ -4,4 +4,9 @@ package models
 type PenilaianResponse struct {
     PenilaianList []Penilaian `json:"penilaian"`
     TotalNilai    string      `json:"total"`
+    SisaBobot     string      `json:"sisa_bobot"` // Bobot yang belum dipakai penilaian mana pun, contoh "40.00%"
+    Komponen      []KomponenNilai `json:"komponen"` // Semua komponen mapel, termasuk yang belum dinilai
+    KKM           float64     `json:"kkm"`
+    Predikat      string      `json:"predikat"` // "" jika siswa belum dinilai
+    Lulus         bool        `json:"lulus"`    // total >= kkm
 }
\ No newline at end of file
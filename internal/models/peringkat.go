@@ -0,0 +1,23 @@
+package models
+
+// Peringkat - Peringkat siswa satu kelas berdasarkan rata-rata total_nilai, semua mapel atau satu mapel saja
+type Peringkat struct {
+	IDKelas     int              `json:"id_kelas"`
+	IDMapel     *int             `json:"id_mapel"` // null jika peringkat dari semua mapel kelas
+	IDSemester  int              `json:"id_semester"`
+	JumlahSiswa int              `json:"jumlah_siswa"`
+	Siswa       []PeringkatSiswa `json:"siswa"`
+}
+
+// PeringkatSiswa - Rata-rata dibulatkan 2 desimal sebelum diperingkat; rata-rata sama mendapat peringkat
+// sama dan peringkat berikutnya tidak dilompati (1, 2, 2, 3). Persentil adalah persentase siswa lain
+// yang rata-ratanya lebih rendah (100 untuk siswa terbaik, 0 untuk yang terendah).
+type PeringkatSiswa struct {
+	IDSiswa     int     `json:"id_siswa"`
+	NISN        string  `json:"nisn"`
+	NamaSiswa   string  `json:"nama_siswa"`
+	RataRata    float64 `json:"rata_rata"`
+	JumlahMapel int     `json:"jumlah_mapel"` // mapel yang sudah dinilai
+	Peringkat   int     `json:"peringkat"`
+	Persentil   float64 `json:"persentil"`
+}
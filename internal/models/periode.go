@@ -0,0 +1,81 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TahunAjaran - Tahun ajaran, contoh "2025/2026", beserta kedua semesternya
+type TahunAjaran struct {
+	IDTahunAjaran int        `json:"id_tahun_ajaran"`
+	Nama          string     `json:"nama"`
+	Semester      []Semester `json:"semester"`
+}
+
+// Semester - Semester 1 (ganjil) atau 2 (genap) dalam satu tahun ajaran. Nilai siswa dicatat per semester.
+type Semester struct {
+	IDSemester     int    `json:"id_semester"`
+	IDTahunAjaran  int    `json:"id_tahun_ajaran"`
+	TahunAjaran    string `json:"tahun_ajaran"`
+	Semester       int    `json:"semester"`
+	TanggalMulai   string `json:"tanggal_mulai"`   // Format YYYY-MM-DD
+	TanggalSelesai string `json:"tanggal_selesai"` // Format YYYY-MM-DD
+	Aktif          bool   `json:"aktif"`
+}
+
+var tahunAjaranPattern = regexp.MustCompile(`^(\d{4})/(\d{4})$`)
+
+// Validate - Nama harus "YYYY/YYYY" dengan tahun kedua = tahun pertama + 1
+func (t *TahunAjaran) Validate() error {
+	t.Nama = strings.ReplaceAll(strings.TrimSpace(t.Nama), " ", "")
+	m := tahunAjaranPattern.FindStringSubmatch(t.Nama)
+	if m == nil {
+		return errors.New("nama tahun ajaran harus berformat YYYY/YYYY, contoh 2025/2026")
+	}
+	awal, _ := strconv.Atoi(m[1])
+	akhir, _ := strconv.Atoi(m[2])
+	if akhir != awal+1 {
+		return fmt.Errorf("tahun ajaran %s harus diikuti %d", m[1], awal+1)
+	}
+	return nil
+}
+
+// SemesterBawaan - Semester 1 Juli - Desember dan semester 2 Januari - Juni untuk tahun ajaran yang sudah valid
+func (t TahunAjaran) SemesterBawaan() []Semester {
+	awal, _ := strconv.Atoi(t.Nama[:4])
+	ganjil := time.Date(awal, time.July, 1, 0, 0, 0, 0, time.UTC)
+	genap := time.Date(awal+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return []Semester{
+		{Semester: 1, TanggalMulai: ganjil.Format(TanggalLayout), TanggalSelesai: ganjil.AddDate(0, 6, -1).Format(TanggalLayout)},
+		{Semester: 2, TanggalMulai: genap.Format(TanggalLayout), TanggalSelesai: genap.AddDate(0, 6, -1).Format(TanggalLayout)},
+	}
+}
+
+// ValidateTanggal - Kedua tanggal wajib berformat YYYY-MM-DD dan tanggal_selesai setelah tanggal_mulai
+func (s *Semester) ValidateTanggal() error {
+	mulai, err := time.Parse(TanggalLayout, strings.TrimSpace(s.TanggalMulai))
+	if err != nil {
+		return fmt.Errorf("tanggal_mulai harus berformat %s", "YYYY-MM-DD")
+	}
+	selesai, err := time.Parse(TanggalLayout, strings.TrimSpace(s.TanggalSelesai))
+	if err != nil {
+		return fmt.Errorf("tanggal_selesai harus berformat %s", "YYYY-MM-DD")
+	}
+	if !selesai.After(mulai) {
+		return errors.New("tanggal_selesai harus setelah tanggal_mulai")
+	}
+	s.TanggalMulai, s.TanggalSelesai = mulai.Format(TanggalLayout), selesai.Format(TanggalLayout)
+	return nil
+}
+
+// Nama - Contoh "1 (Ganjil)" untuk ditampilkan di rapor
+func (s Semester) Nama() string {
+	if s.Semester == 1 {
+		return "1 (Ganjil)"
+	}
+	return fmt.Sprintf("%d (Genap)", s.Semester)
+}
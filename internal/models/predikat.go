@@ -0,0 +1,108 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultKKM - KKM mapel baru jika tidak diisi
+const DefaultKKM = 75.0
+
+// BatasPredikat - Predikat berlaku untuk nilai >= Min
+type BatasPredikat struct {
+	Predikat string  `json:"predikat"`
+	Min      float64 `json:"min"`
+}
+
+// SkalaPredikat - Batas predikat urut dari Min terbesar. Disimpan sebagai JSONB di mata_pelajaran.skala_predikat.
+type SkalaPredikat []BatasPredikat
+
+// DefaultSkalaPredikat - Skala predikat bawaan (A 86-100, B 71-85, C 56-70, D di bawahnya)
+var DefaultSkalaPredikat = SkalaPredikat{
+	{Predikat: "A", Min: 86},
+	{Predikat: "B", Min: 71},
+	{Predikat: "C", Min: 56},
+	{Predikat: "D", Min: 0},
+}
+
+// Predikat - Predikat untuk nilai, "" jika nilai di bawah semua batas
+func (s SkalaPredikat) Predikat(nilai float64) string {
+	for _, b := range s {
+		if nilai >= b.Min {
+			return b.Predikat
+		}
+	}
+	return ""
+}
+
+// Normalize - Mengurutkan batas dari Min terbesar lalu memeriksa bahwa predikat dan Min tidak ganda,
+// Min di antara 0 - 100, dan batas terendah 0 supaya setiap nilai mendapat predikat
+func (s SkalaPredikat) Normalize() (SkalaPredikat, error) {
+	if len(s) == 0 {
+		return nil, errors.New("skala_predikat minimal berisi satu predikat")
+	}
+	out := make(SkalaPredikat, len(s))
+	copy(out, s)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Min > out[j].Min })
+
+	seen := make(map[string]bool, len(out))
+	for i := range out {
+		b := &out[i]
+		b.Predikat = strings.TrimSpace(b.Predikat)
+		switch {
+		case b.Predikat == "":
+			return nil, errors.New("nama predikat tidak boleh kosong")
+		case seen[b.Predikat]:
+			return nil, fmt.Errorf("predikat %q ditulis lebih dari sekali", b.Predikat)
+		case b.Min < 0 || b.Min > 100:
+			return nil, fmt.Errorf("batas predikat %s harus 0 - 100", b.Predikat)
+		case i > 0 && b.Min == out[i-1].Min:
+			return nil, fmt.Errorf("predikat %s dan %s punya batas yang sama", out[i-1].Predikat, b.Predikat)
+		}
+		seen[b.Predikat] = true
+	}
+	if last := out[len(out)-1]; last.Min != 0 {
+		return nil, fmt.Errorf("batas predikat terendah (%s) harus 0", last.Predikat)
+	}
+	return out, nil
+}
+
+// Value - driver.Valuer untuk kolom JSONB
+func (s SkalaPredikat) Value() (driver.Value, error) {
+	if s == nil {
+		s = DefaultSkalaPredikat
+	}
+	b, err := json.Marshal(s)
+	return string(b), err
+}
+
+// Scan - sql.Scanner untuk kolom JSONB
+func (s *SkalaPredikat) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	case nil:
+		*s = append(SkalaPredikat(nil), DefaultSkalaPredikat...)
+		return nil
+	}
+	return fmt.Errorf("skala_predikat: tipe %T tidak didukung", src)
+}
+
+// ValidateKKM - KKM harus di antara 0 - 100 seperti total_nilai
+func ValidateKKM(kkm float64) error {
+	if kkm < 0 || kkm > 100 {
+		return errors.New("kkm harus di antara 0 - 100")
+	}
+	return nil
+}
+
+// Ketuntasan - Predikat dan status lulus sebuah total_nilai terhadap KKM dan skala predikat mapel
+func (mp MataPelajaran) Ketuntasan(total float64) (predikat string, lulus bool) {
+	return mp.SkalaPredikat.Predikat(total), total >= mp.KKM
+}
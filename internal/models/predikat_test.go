@@ -0,0 +1,128 @@
+package models
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestSkalaPredikatNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      SkalaPredikat
+		want    SkalaPredikat
+		wantErr bool
+	}{
+		{"bawaan", DefaultSkalaPredikat, DefaultSkalaPredikat, false},
+		{"diurutkan dari Min terbesar", SkalaPredikat{{"C", 0}, {"A", 80}, {"B", 60}}, SkalaPredikat{{"A", 80}, {"B", 60}, {"C", 0}}, false},
+		{"nama dirapikan", SkalaPredikat{{" Tuntas ", 75}, {"Belum tuntas", 0}}, SkalaPredikat{{"Tuntas", 75}, {"Belum tuntas", 0}}, false},
+		{"satu predikat", SkalaPredikat{{"Lulus", 0}}, SkalaPredikat{{"Lulus", 0}}, false},
+		{"batas pecahan", SkalaPredikat{{"A", 85.5}, {"B", 0}}, SkalaPredikat{{"A", 85.5}, {"B", 0}}, false},
+		{"batas 100", SkalaPredikat{{"Sempurna", 100}, {"B", 0}}, SkalaPredikat{{"Sempurna", 100}, {"B", 0}}, false},
+
+		{"kosong", SkalaPredikat{}, nil, true},
+		{"nil", nil, nil, true},
+		{"nama kosong", SkalaPredikat{{"A", 80}, {" ", 0}}, nil, true},
+		{"nama ganda", SkalaPredikat{{"A", 80}, {"A", 0}}, nil, true},
+		{"nama ganda setelah dirapikan", SkalaPredikat{{"A", 80}, {"A ", 0}}, nil, true},
+		{"Min ganda", SkalaPredikat{{"A", 80}, {"B", 80}, {"C", 0}}, nil, true},
+		{"batas terendah bukan 0", SkalaPredikat{{"A", 80}, {"B", 60}}, nil, true},
+		{"Min negatif", SkalaPredikat{{"A", 80}, {"B", -1}}, nil, true},
+		{"Min lebih dari 100", SkalaPredikat{{"A", 101}, {"B", 0}}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := slices.Clone(tt.in)
+			got, err := tt.in.Normalize()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Normalize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Normalize() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(tt.in, in) {
+				t.Errorf("Normalize() mengubah skala asal menjadi %v", tt.in)
+			}
+		})
+	}
+}
+
+func TestSkalaPredikatPredikat(t *testing.T) {
+	tests := []struct {
+		nilai float64
+		want  string
+	}{
+		{100, "A"},
+		{86, "A"},
+		{85.99, "B"},
+		{71, "B"},
+		{70.5, "C"},
+		{56, "C"},
+		{55.99, "D"},
+		{0, "D"},
+	}
+	for _, tt := range tests {
+		if got := DefaultSkalaPredikat.Predikat(tt.nilai); got != tt.want {
+			t.Errorf("Predikat(%v) = %q, want %q", tt.nilai, got, tt.want)
+		}
+	}
+
+	// Skala yang tidak dinormalisasi bisa tidak punya batas 0
+	tanpaNol := SkalaPredikat{{"A", 80}, {"B", 60}}
+	if got := tanpaNol.Predikat(59); got != "" {
+		t.Errorf("Predikat(59) = %q, want kosong", got)
+	}
+}
+
+func TestSkalaPredikatScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     any
+		want    SkalaPredikat
+		wantErr bool
+	}{
+		{"NULL memakai skala bawaan", nil, DefaultSkalaPredikat, false},
+		{"bytes", []byte(`[{"predikat":"Tuntas","min":75},{"predikat":"Belum tuntas","min":0}]`),
+			SkalaPredikat{{"Tuntas", 75}, {"Belum tuntas", 0}}, false},
+		{"string", `[{"predikat":"A","min":0}]`, SkalaPredikat{{"A", 0}}, false},
+		{"JSON rusak", []byte(`[{"predikat":`), nil, true},
+		{"tipe lain", 42, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got SkalaPredikat
+			err := got.Scan(tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Scan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Skala hasil NULL bukan slice bawaan itu sendiri
+	var s SkalaPredikat
+	if err := s.Scan(nil); err != nil {
+		t.Fatal(err)
+	}
+	s[0].Min = 90
+	if DefaultSkalaPredikat[0].Min != 86 {
+		t.Errorf("DefaultSkalaPredikat ikut berubah: %v", DefaultSkalaPredikat)
+	}
+}
+
+func TestSkalaPredikatValue(t *testing.T) {
+	var s SkalaPredikat
+	v, err := s.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back SkalaPredikat
+	if err := back.Scan(v); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, DefaultSkalaPredikat) {
+		t.Errorf("Value() nil = %v, want skala bawaan", v)
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Rapor - Data satu rapor siswa untuk satu tahun ajaran
+type Rapor struct {
+	Siswa        Siswa        `json:"siswa"`
+	Kelas        Kelas        `json:"kelas"`
+	WaliKelas    Guru         `json:"wali_kelas"`
+	TahunAjaran  string       `json:"tahun_ajaran"`
+	Semester     Semester     `json:"semester"`
+	Mapel        []RaporMapel `json:"mapel"`
+	RataRata     *float64     `json:"rata_rata"`    // rata-rata mapel yang sudah dinilai, null jika belum ada
+	Peringkat    int          `json:"peringkat"`    // peringkat di kelas, 0 jika belum dinilai
+	JumlahSiswa  int          `json:"jumlah_siswa"` // jumlah siswa yang diperingkat
+	Absensi      RekapAbsensi `json:"absensi"`      // absensi harian selama semester
+	TanggalCetak time.Time    `json:"tanggal_cetak"`
+}
+
+// RaporMapel - Satu baris mata pelajaran di rapor. TotalNilai null jika siswa belum dinilai.
+type RaporMapel struct {
+	IDMapel    int      `json:"id_mapel"`
+	NamaMapel  string   `json:"nama_mata_pelajaran"`
+	TotalNilai *float64 `json:"total_nilai"`
+	KKM        float64  `json:"kkm"`
+	Predikat   string   `json:"predikat"`
+	Lulus      bool     `json:"lulus"`
+	NamaGuru   string   `json:"nama_guru"`
+
+	Skala SkalaPredikat `json:"-"` // skala predikat mapel, bahan mengisi Predikat
+}
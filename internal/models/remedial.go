@@ -0,0 +1,69 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Aturan remedial per mapel: bagaimana nilai remedial memengaruhi total_nilai
+const (
+	AturanRemedialGanti    = "ganti"     // nilai remedial terakhir menggantikan nilai asli
+	AturanRemedialBatasKKM = "batas_kkm" // remedial tertinggi, paling banyak sebesar KKM
+	AturanRemedialMaks     = "maks"      // nilai tertinggi antara nilai asli dan remedial
+)
+
+// ValidateAturanRemedial - Aturan kosong diganti aturan bawaan (batas_kkm)
+func ValidateAturanRemedial(aturan string) (string, error) {
+	switch aturan {
+	case "":
+		return AturanRemedialBatasKKM, nil
+	case AturanRemedialGanti, AturanRemedialBatasKKM, AturanRemedialMaks:
+		return aturan, nil
+	}
+	return "", fmt.Errorf("aturan_remedial harus %s, %s atau %s",
+		AturanRemedialGanti, AturanRemedialBatasKKM, AturanRemedialMaks)
+}
+
+// Remedial - Satu percobaan remedial untuk sebuah penilaian. Nilai memakai skala penilaian aslinya.
+type Remedial struct {
+	IDRemedial  int    `json:"id_remedial"`
+	IDPenilaian int    `json:"id_penilaian"`
+	Nilai       int    `json:"nilai"`
+	Tanggal     string `json:"tanggal"` // Format YYYY-MM-DD, default hari ini
+	Catatan     string `json:"catatan"`
+}
+
+// Validate - nilai dibatasi nilai maksimum penilaian aslinya
+func (r *Remedial) Validate(nilaiMaks int) error {
+	if err := ValidateNilaiMaks(r.Nilai, nilaiMaks); err != nil {
+		return err
+	}
+	r.Tanggal = strings.TrimSpace(r.Tanggal)
+	if r.Tanggal == "" {
+		r.Tanggal = time.Now().Format(TanggalLayout)
+	} else if _, err := time.Parse(TanggalLayout, r.Tanggal); err != nil {
+		return fmt.Errorf("tanggal harus berformat %s", "YYYY-MM-DD")
+	}
+	r.Catatan = strings.TrimSpace(r.Catatan)
+	return nil
+}
+
+// SiswaRemedial - Siswa yang total_nilai-nya di bawah KKM mapel beserta penilaian yang belum tuntas
+type SiswaRemedial struct {
+	IDSiswa    int                 `json:"id_siswa"`
+	NISN       string              `json:"nisn"`
+	NamaSiswa  string              `json:"nama_siswa"`
+	TotalNilai float64             `json:"total_nilai"`
+	KKM        float64             `json:"kkm"`
+	Penilaian  []PenilaianRemedial `json:"penilaian"`
+}
+
+// PenilaianRemedial - Penilaian di bawah KKM (setelah diskalakan ke 0 - 100) beserta remedial yang sudah dicoba
+type PenilaianRemedial struct {
+	IDPenilaian int        `json:"id_penilaian"`
+	NamaNilai   string     `json:"nama_nilai"`
+	Nilai       int        `json:"nilai"`
+	NilaiMaks   int        `json:"nilai_maks"`
+	Remedial    []Remedial `json:"remedial"`
+}
@@ -0,0 +1,27 @@
+package models
+
+// Status riwayat kelas
+const (
+	RiwayatAktif   = "aktif"   // kelas siswa saat ini
+	RiwayatNaik    = "naik"    // naik ke tingkat berikutnya lewat kenaikan kelas
+	RiwayatTinggal = "tinggal" // tinggal kelas, mengulang tingkat yang sama di tahun ajaran berikutnya
+	RiwayatPindah  = "pindah"  // dipindah ke kelas lain
+
+	// Siswa meninggalkan sekolah, lihat status siswa
+	RiwayatLulus         = "lulus"
+	RiwayatPindahSekolah = "pindah_sekolah"
+	RiwayatKeluar        = "keluar"
+)
+
+// RiwayatKelas - Satu periode siswa berada di sebuah kelas. TanggalKeluar null selama masih aktif.
+type RiwayatKelas struct {
+	IDRiwayat     int     `json:"id_riwayat"`
+	IDSiswa       int     `json:"id_siswa"`
+	IDKelas       int     `json:"id_kelas"`
+	NamaKelas     string  `json:"nama_kelas"`
+	IDTahunAjaran int     `json:"id_tahun_ajaran"`
+	TahunAjaran   string  `json:"tahun_ajaran"`
+	TanggalMasuk  string  `json:"tanggal_masuk"`  // Format YYYY-MM-DD
+	TanggalKeluar *string `json:"tanggal_keluar"` // Format YYYY-MM-DD
+	Status        string  `json:"status"`
+}
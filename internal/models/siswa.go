@@ -10,4 +10,7 @@ type Siswa struct {
     TanggalLahir  string 	`json:"tanggal_lahir"`  // Tipe data time.Time untuk tanggal
 	NISN           string 	`json:"nisn"`
 	Foto 		  string 	`json:"foto"`
+	Status        string    `json:"status"`         // aktif, lulus, pindah atau keluar; diubah lewat PUT /siswa/{id}/status
+	TanggalStatus *string   `json:"tanggal_status"` // Format YYYY-MM-DD, tanggal status berlaku
+	Keterangan    string    `json:"keterangan"`
 }
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Status siswa
+const (
+	SiswaAktif  = "aktif"
+	SiswaLulus  = "lulus"  // alumni
+	SiswaPindah = "pindah" // pindah ke sekolah lain
+	SiswaKeluar = "keluar" // berhenti atau dikeluarkan
+)
+
+// StatusSiswa - Perubahan status seorang siswa. IDKelas hanya dipakai saat mengaktifkan kembali siswa.
+type StatusSiswa struct {
+	Status     string `json:"status"`
+	Tanggal    string `json:"tanggal"` // Format YYYY-MM-DD, default hari ini
+	Keterangan string `json:"keterangan"`
+	IDKelas    *int   `json:"id_kelas,omitempty"`
+}
+
+// ValidStatusSiswa - true jika status salah satu status siswa
+func ValidStatusSiswa(status string) bool {
+	switch status {
+	case SiswaAktif, SiswaLulus, SiswaPindah, SiswaKeluar:
+		return true
+	}
+	return false
+}
+
+// Validate - Status wajib valid; tanggal kosong diisi hari ini
+func (s *StatusSiswa) Validate() error {
+	s.Status = strings.ToLower(strings.TrimSpace(s.Status))
+	if !ValidStatusSiswa(s.Status) {
+		return errors.New("status harus aktif, lulus, pindah atau keluar")
+	}
+	s.Keterangan = strings.TrimSpace(s.Keterangan)
+	if s.Tanggal == "" {
+		s.Tanggal = time.Now().Format(TanggalLayout)
+		return nil
+	}
+	t, err := time.Parse(TanggalLayout, strings.TrimSpace(s.Tanggal))
+	if err != nil {
+		return errors.New("tanggal harus berformat YYYY-MM-DD")
+	}
+	s.Tanggal = t.Format(TanggalLayout)
+	return nil
+}
+
+// StatusRiwayat - Status penutup riwayat kelas untuk siswa yang berhenti dengan status ini
+func (s StatusSiswa) StatusRiwayat() string {
+	switch s.Status {
+	case SiswaLulus:
+		return RiwayatLulus
+	case SiswaPindah:
+		return RiwayatPindahSekolah
+	}
+	return RiwayatKeluar
+}
+
+// Alumni - Siswa yang sudah lulus beserta kelas terakhirnya
+type Alumni struct {
+	Siswa
+	NamaKelas   string `json:"nama_kelas"`   // kelas terakhir
+	TahunAjaran string `json:"tahun_ajaran"` // tahun ajaran kelas terakhir
+	TahunLulus  int    `json:"tahun_lulus"`
+}
+
+// Kelulusan - Meluluskan semua siswa aktif satu kelas kecuali yang ada di TidakLulus
+type Kelulusan struct {
+	Tanggal    string `json:"tanggal"` // Format YYYY-MM-DD, default hari ini
+	TidakLulus []int  `json:"tidak_lulus"`
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"math"
+	"math/big"
+)
+
+// PenilaianTertimbang - Satu penilaian yang ikut dihitung di total_nilai
+type PenilaianTertimbang struct {
+	Nilai     int
+	NilaiMaks int     // nilai_maks komponennya, 0 untuk penilaian lepas (skala 0 - 100)
+	Bobot     float64 // desimal, 0.3 untuk 30%
+	Remedial  []Remedial
+}
+
+// TotalNilai - total_nilai satu siswa untuk satu mapel, sama dengan weightedTotal di repository/nilai.go:
+// setiap nilai diskalakan ke 0 - 100 memakai nilai_maks, diganti nilai efektif remedial menurut
+// aturan_remedial, dikali bobot, lalu jumlahnya dibulatkan 2 desimal (setengah ke atas seperti ROUND
+// di PostgreSQL). Dihitung dengan pecahan eksak supaya pembulatan tidak meleset karena galat float.
+func TotalNilai(aturan string, kkm float64, penilaian []PenilaianTertimbang) float64 {
+	total := new(big.Rat)
+	for _, p := range penilaian {
+		bobot := big.NewRat(BobotPoints(p.Bobot), 10000)
+		total.Add(total, bobot.Mul(bobot, nilaiEfektif(aturan, kkm, p)))
+	}
+	return roundRat(total, 2)
+}
+
+// nilaiEfektif - Nilai penilaian di skala 0 - 100 setelah remedial
+func nilaiEfektif(aturan string, kkm float64, p PenilaianTertimbang) *big.Rat {
+	maks := int64(p.NilaiMaks)
+	if maks <= 0 {
+		maks = NilaiMax
+	}
+	skala := func(nilai int) *big.Rat { return big.NewRat(int64(nilai)*100, maks) }
+
+	asli := skala(p.Nilai)
+	if len(p.Remedial) == 0 {
+		return asli
+	}
+	terakhir, tertinggi := p.Remedial[0], p.Remedial[0].Nilai
+	for _, r := range p.Remedial[1:] {
+		if r.Tanggal > terakhir.Tanggal || (r.Tanggal == terakhir.Tanggal && r.IDRemedial > terakhir.IDRemedial) {
+			terakhir = r
+		}
+		tertinggi = max(tertinggi, r.Nilai)
+	}
+
+	switch aturan {
+	case AturanRemedialGanti:
+		return skala(terakhir.Nilai)
+	case AturanRemedialMaks:
+		return skala(max(p.Nilai, tertinggi))
+	default:
+		batas := big.NewRat(int64(math.Round(kkm*100)), 100)
+		remedial := skala(tertinggi)
+		if remedial.Cmp(batas) > 0 {
+			remedial = batas
+		}
+		if asli.Cmp(remedial) > 0 {
+			return asli
+		}
+		return remedial
+	}
+}
+
+// roundRat - Membulatkan x (tidak negatif) ke desimal angka di belakang koma, setengah ke atas
+func roundRat(x *big.Rat, desimal int) float64 {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(desimal)), nil)
+	scaled := new(big.Rat).Mul(x, new(big.Rat).SetInt(scale))
+	scaled.Add(scaled, big.NewRat(1, 2))
+	floor := new(big.Int).Quo(scaled.Num(), scaled.Denom())
+	f, _ := new(big.Rat).SetFrac(floor, scale).Float64()
+	return f
+}
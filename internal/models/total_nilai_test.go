@@ -0,0 +1,94 @@
+package models
+
+import "testing"
+
+func TestTotalNilai(t *testing.T) {
+	remedial := func(id, nilai int, tanggal string) Remedial {
+		return Remedial{IDRemedial: id, Nilai: nilai, Tanggal: tanggal}
+	}
+
+	tests := []struct {
+		name      string
+		aturan    string
+		kkm       float64
+		penilaian []PenilaianTertimbang
+		want      float64
+	}{
+		{"belum ada penilaian", AturanRemedialBatasKKM, 75, nil, 0},
+		{"satu penilaian 100%", AturanRemedialBatasKKM, 75, []PenilaianTertimbang{{Nilai: 85, Bobot: 1}}, 85},
+		{"bobot 30% dan 70%", AturanRemedialBatasKKM, 75, []PenilaianTertimbang{
+			{Nilai: 85, Bobot: 0.3},
+			{Nilai: 90, Bobot: 0.7},
+		}, 88.5},
+		{"bobot belum penuh", AturanRemedialBatasKKM, 75, []PenilaianTertimbang{{Nilai: 80, Bobot: 0.5}}, 40},
+		{"nilai_maks 50 diskalakan ke 100", AturanRemedialBatasKKM, 75, []PenilaianTertimbang{
+			{Nilai: 40, NilaiMaks: 50, Bobot: 1},
+		}, 80},
+		{"nilai_maks 0 berarti skala 100", AturanRemedialBatasKKM, 75, []PenilaianTertimbang{
+			{Nilai: 40, NilaiMaks: 0, Bobot: 1},
+		}, 40},
+		{"sepertiga dibulatkan ke bawah", AturanRemedialBatasKKM, 75, []PenilaianTertimbang{
+			{Nilai: 1, NilaiMaks: 3, Bobot: 1},
+		}, 33.33},
+		{"dua pertiga dibulatkan ke atas", AturanRemedialBatasKKM, 75, []PenilaianTertimbang{
+			{Nilai: 2, NilaiMaks: 3, Bobot: 1},
+		}, 66.67},
+		// 0.1235 × 10 di float64 = 1.2349999999999999, ROUND di PostgreSQL tetap 1.24
+		{"tepat setengah dibulatkan ke atas", AturanRemedialBatasKKM, 75, []PenilaianTertimbang{
+			{Nilai: 10, Bobot: 0.1235},
+		}, 1.24},
+		{"tiga bobot 33.33% + 33.33% + 33.34%", AturanRemedialBatasKKM, 75, []PenilaianTertimbang{
+			{Nilai: 70, Bobot: 0.3333},
+			{Nilai: 80, Bobot: 0.3333},
+			{Nilai: 90, Bobot: 0.3334},
+		}, 80.0},
+
+		{"ganti memakai remedial terakhir", AturanRemedialGanti, 75, []PenilaianTertimbang{
+			{Nilai: 60, Bobot: 1, Remedial: []Remedial{remedial(1, 70, "2024-01-10"), remedial(2, 65, "2024-01-12")}},
+		}, 65},
+		{"ganti tanggal sama memakai id_remedial terbesar", AturanRemedialGanti, 75, []PenilaianTertimbang{
+			{Nilai: 60, Bobot: 1, Remedial: []Remedial{remedial(3, 70, "2024-01-10"), remedial(2, 65, "2024-01-10")}},
+		}, 70},
+		{"ganti boleh lebih rendah dari nilai asli", AturanRemedialGanti, 75, []PenilaianTertimbang{
+			{Nilai: 60, Bobot: 1, Remedial: []Remedial{remedial(1, 50, "2024-01-10")}},
+		}, 50},
+		{"ganti diskalakan nilai_maks", AturanRemedialGanti, 75, []PenilaianTertimbang{
+			{Nilai: 20, NilaiMaks: 50, Bobot: 1, Remedial: []Remedial{remedial(1, 45, "2024-01-10")}},
+		}, 90},
+
+		{"maks memakai remedial tertinggi", AturanRemedialMaks, 75, []PenilaianTertimbang{
+			{Nilai: 60, Bobot: 1, Remedial: []Remedial{remedial(1, 70, "2024-01-10"), remedial(2, 65, "2024-01-12")}},
+		}, 70},
+		{"maks tetap nilai asli jika lebih tinggi", AturanRemedialMaks, 75, []PenilaianTertimbang{
+			{Nilai: 80, Bobot: 1, Remedial: []Remedial{remedial(1, 70, "2024-01-10")}},
+		}, 80},
+
+		{"batas_kkm dibatasi KKM", AturanRemedialBatasKKM, 75, []PenilaianTertimbang{
+			{Nilai: 60, Bobot: 1, Remedial: []Remedial{remedial(1, 90, "2024-01-10")}},
+		}, 75},
+		{"batas_kkm di bawah KKM memakai remedial", AturanRemedialBatasKKM, 75, []PenilaianTertimbang{
+			{Nilai: 60, Bobot: 1, Remedial: []Remedial{remedial(1, 70, "2024-01-10")}},
+		}, 70},
+		{"batas_kkm nilai asli di atas KKM tidak turun", AturanRemedialBatasKKM, 75, []PenilaianTertimbang{
+			{Nilai: 80, Bobot: 1, Remedial: []Remedial{remedial(1, 90, "2024-01-10")}},
+		}, 80},
+		{"batas_kkm KKM desimal", AturanRemedialBatasKKM, 72.5, []PenilaianTertimbang{
+			{Nilai: 30, NilaiMaks: 50, Bobot: 1, Remedial: []Remedial{remedial(1, 45, "2024-01-10")}},
+		}, 72.5},
+		{"aturan kosong sama dengan batas_kkm", "", 75, []PenilaianTertimbang{
+			{Nilai: 60, Bobot: 1, Remedial: []Remedial{remedial(1, 90, "2024-01-10")}},
+		}, 75},
+		{"remedial hanya memengaruhi penilaiannya sendiri", AturanRemedialGanti, 75, []PenilaianTertimbang{
+			{Nilai: 60, Bobot: 0.5, Remedial: []Remedial{remedial(1, 80, "2024-01-10")}},
+			{Nilai: 90, Bobot: 0.5},
+		}, 85},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TotalNilai(tt.aturan, tt.kkm, tt.penilaian); got != tt.want {
+				t.Errorf("TotalNilai() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
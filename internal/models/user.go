@@ -1,5 +1,7 @@
 package models
 
+import "encoding/json"
+
 type User struct {
 	IDUser      int    `json:"id_user"`
 	IDRole     	int `json:"id_role"`
@@ -7,3 +9,12 @@ type User struct {
 	Password 	string `json:"password"`
 	TanggalRegistrasi string `json:"tanggal_registrasi"`
 }
+
+// MarshalJSON - Password hanya diterima dari request, tidak pernah dikirim balik ke client
+func (u User) MarshalJSON() ([]byte, error) {
+	type userAlias User
+	return json.Marshal(struct {
+		userAlias
+		Password string `json:"password,omitempty"`
+	}{userAlias: userAlias(u)})
+}
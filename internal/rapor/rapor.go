@@ -0,0 +1,242 @@
+// Package rapor - Membuat PDF rapor siswa di server memakai fpdf
+package rapor
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+
+	"myapp/config"
+	"myapp/internal/models"
+)
+
+// Ukuran halaman A4 portrait dalam mm
+const (
+	margin     = 15.0
+	pageWidth  = 210.0
+	contentW   = pageWidth - 2*margin
+	lineHeight = 6.0
+)
+
+// Lebar kolom tabel nilai: No, Mata Pelajaran, KKM, Nilai, Predikat, Guru Pengajar
+var colWidths = []float64{10, 62, 16, 20, 20, contentW - 128}
+
+var bulan = [...]string{"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+
+// Tanggal - Format tanggal Indonesia, contoh "18 Oktober 2026"
+func Tanggal(t time.Time) string {
+	return fmt.Sprintf("%d %s %d", t.Day(), bulan[t.Month()-1], t.Year())
+}
+
+// Write - Menulis satu PDF berisi rapor-rapor yang diberikan, setiap rapor dimulai di halaman baru
+func Write(w io.Writer, sekolah config.SekolahConfig, rapor ...models.Rapor) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetAutoPageBreak(true, margin)
+	pdf.SetTitle("Rapor "+sekolah.Nama, true)
+	pdf.SetCreator(sekolah.Nama, true)
+
+	// Font inti PDF memakai cp1252; teks UTF-8 (nama dengan aksen) harus diterjemahkan dulu
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	for _, r := range rapor {
+		writeRapor(pdf, tr, sekolah, r)
+	}
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	return pdf.Output(w)
+}
+
+func writeRapor(pdf *fpdf.Fpdf, tr func(string) string, sekolah config.SekolahConfig, r models.Rapor) {
+	pdf.AddPage()
+	writeKop(pdf, tr, sekolah)
+
+	pdf.SetFont("Helvetica", "B", 13)
+	pdf.CellFormat(contentW, 8, "LAPORAN HASIL BELAJAR PESERTA DIDIK", "", 1, "C", false, 0, "")
+	pdf.Ln(3)
+
+	// Identitas siswa
+	identitas := [][2]string{
+		{"Nama Siswa", r.Siswa.NamaSiswa},
+		{"NISN", r.Siswa.NISN},
+		{"Kelas", r.Kelas.NamaKelas},
+		{"Tahun Ajaran", r.TahunAjaran},
+		{"Semester", r.Semester.Nama()},
+	}
+	pdf.SetFont("Helvetica", "", 10)
+	for _, row := range identitas {
+		pdf.CellFormat(35, lineHeight, row[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(5, lineHeight, ":", "", 0, "L", false, 0, "")
+		pdf.CellFormat(contentW-40, lineHeight, tr(row[1]), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	writeTabelNilai(pdf, tr, r)
+	pdf.Ln(4)
+	writeAbsensi(pdf, r)
+	pdf.Ln(10)
+	writeTandaTangan(pdf, tr, sekolah, r)
+}
+
+// writeKop - Nama, alamat dan telepon sekolah dengan garis di bawahnya
+func writeKop(pdf *fpdf.Fpdf, tr func(string) string, sekolah config.SekolahConfig) {
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(contentW, 8, tr(strings.ToUpper(sekolah.Nama)), "", 1, "C", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 9)
+	var kontak []string
+	if sekolah.Alamat != "" {
+		kontak = append(kontak, sekolah.Alamat)
+	}
+	if sekolah.Telepon != "" {
+		kontak = append(kontak, "Telp. "+sekolah.Telepon)
+	}
+	if len(kontak) > 0 {
+		pdf.CellFormat(contentW, 5, tr(strings.Join(kontak, " - ")), "", 1, "C", false, 0, "")
+	}
+
+	y := pdf.GetY() + 2
+	pdf.SetLineWidth(0.6)
+	pdf.Line(margin, y, pageWidth-margin, y)
+	pdf.SetLineWidth(0.2)
+	pdf.Line(margin, y+1, pageWidth-margin, y+1)
+	pdf.SetY(y + 5)
+}
+
+func writeTabelNilai(pdf *fpdf.Fpdf, tr func(string) string, r models.Rapor) {
+	header := func() {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.SetFillColor(230, 230, 230)
+		for i, title := range []string{"No", "Mata Pelajaran", "KKM", "Nilai", "Predikat", "Guru Pengajar"} {
+			pdf.CellFormat(colWidths[i], 8, title, "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 10)
+	}
+	header()
+
+	_, pageHeight := pdf.GetPageSize()
+	for i, m := range r.Mapel {
+		if pdf.GetY()+lineHeight > pageHeight-margin {
+			pdf.AddPage()
+			header()
+		}
+		nilai := "-"
+		if m.TotalNilai != nil {
+			nilai = fmt.Sprintf("%.2f", *m.TotalNilai)
+		}
+		cells := []struct {
+			text  string
+			align string
+		}{
+			{fmt.Sprint(i + 1), "C"},
+			{tr(m.NamaMapel), "L"},
+			{fmt.Sprintf("%g", m.KKM), "C"},
+			{nilai, "C"},
+			{m.Predikat, "C"},
+			{tr(m.NamaGuru), "L"},
+		}
+		for j, c := range cells {
+			pdf.CellFormat(colWidths[j], lineHeight, fit(pdf, c.text, colWidths[j]-2), "1", 0, c.align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	if len(r.Mapel) == 0 {
+		pdf.CellFormat(contentW, lineHeight, "Belum ada mata pelajaran", "1", 1, "C", false, 0, "")
+	}
+
+	rata := "-"
+	if r.RataRata != nil {
+		rata = fmt.Sprintf("%.2f", *r.RataRata)
+	}
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(colWidths[0]+colWidths[1]+colWidths[2], lineHeight, "Rata-rata", "1", 0, "R", false, 0, "")
+	pdf.CellFormat(colWidths[3], lineHeight, rata, "1", 0, "C", false, 0, "")
+	pdf.CellFormat(colWidths[4]+colWidths[5], lineHeight, "", "1", 1, "C", false, 0, "")
+
+	if r.Peringkat > 0 {
+		pdf.Ln(2)
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(contentW, lineHeight, fmt.Sprintf("Peringkat ke-%d dari %d siswa", r.Peringkat, r.JumlahSiswa), "", 1, "L", false, 0, "")
+	}
+}
+
+// writeAbsensi - Jumlah hari tidak hadir selama semester menurut absensi harian
+func writeAbsensi(pdf *fpdf.Fpdf, r models.Rapor) {
+	_, pageHeight := pdf.GetPageSize()
+	if pdf.GetY()+4*lineHeight > pageHeight-margin {
+		pdf.AddPage()
+	}
+
+	labelW, jumlahW := colWidths[0]+colWidths[1], colWidths[2]+colWidths[3]
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(230, 230, 230)
+	pdf.CellFormat(labelW+jumlahW, 8, "Ketidakhadiran", "1", 1, "C", true, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	for _, row := range []struct {
+		label  string
+		jumlah int
+	}{
+		{"Sakit", r.Absensi.Sakit},
+		{"Izin", r.Absensi.Izin},
+		{"Tanpa Keterangan", r.Absensi.Alpa},
+	} {
+		pdf.CellFormat(labelW, lineHeight, row.label, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(jumlahW, lineHeight, fmt.Sprintf("%d hari", row.jumlah), "1", 1, "C", false, 0, "")
+	}
+}
+
+// writeTandaTangan - Kepala sekolah di kiri (jika diisi di konfigurasi), wali kelas di kanan
+func writeTandaTangan(pdf *fpdf.Fpdf, tr func(string) string, sekolah config.SekolahConfig, r models.Rapor) {
+	_, pageHeight := pdf.GetPageSize()
+	if pdf.GetY()+45 > pageHeight-margin {
+		pdf.AddPage()
+	}
+
+	half := contentW / 2
+	tempat := Tanggal(r.TanggalCetak)
+	if sekolah.Kota != "" {
+		tempat = sekolah.Kota + ", " + tempat
+	}
+
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(half, lineHeight, "", "", 0, "C", false, 0, "")
+	pdf.CellFormat(half, lineHeight, tr(tempat), "", 1, "C", false, 0, "")
+	kiri := ""
+	if sekolah.KepalaSekolah != "" {
+		kiri = "Kepala Sekolah"
+	}
+	pdf.CellFormat(half, lineHeight, kiri, "", 0, "C", false, 0, "")
+	pdf.CellFormat(half, lineHeight, "Wali Kelas", "", 1, "C", false, 0, "")
+	pdf.Ln(20)
+
+	pdf.SetFont("Helvetica", "BU", 10)
+	pdf.CellFormat(half, lineHeight, tr(sekolah.KepalaSekolah), "", 0, "C", false, 0, "")
+	pdf.CellFormat(half, lineHeight, tr(r.WaliKelas.NamaGuru), "", 1, "C", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	nipKepala := ""
+	if sekolah.KepalaSekolah != "" && sekolah.NIPKepala != "" {
+		nipKepala = "NIP. " + sekolah.NIPKepala
+	}
+	nipWali := ""
+	if r.WaliKelas.NIP != "" {
+		nipWali = "NIP. " + r.WaliKelas.NIP
+	}
+	pdf.CellFormat(half, lineHeight, nipKepala, "", 0, "C", false, 0, "")
+	pdf.CellFormat(half, lineHeight, nipWali, "", 1, "C", false, 0, "")
+}
+
+// fit - Memotong teks dengan "..." supaya muat di lebar kolom
+func fit(pdf *fpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	for len(text) > 0 && pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+	return text + "..."
+}
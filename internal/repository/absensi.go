@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+
+	"myapp/internal/models"
+)
+
+// AbsensiRepository - Akses data tabel absensi. Filter idMapel nil berarti absensi harian.
+type AbsensiRepository interface {
+	// ListByKelas - Absensi satu kelas pada satu tanggal
+	ListByKelas(ctx context.Context, idKelas int, idMapel *int, tanggal string) ([]models.Absensi, error)
+	// ListBySiswa - Semua absensi (harian dan per mapel) seorang siswa dalam rentang tanggal
+	ListBySiswa(ctx context.Context, idSiswa int, dari, sampai string) ([]models.Absensi, error)
+	GetByID(ctx context.Context, idAbsensi int) (models.Absensi, error)
+	// Upsert - Mencatat absensi, menimpa catatan siswa yang sama pada tanggal (dan mapel) yang sama
+	Upsert(ctx context.Context, a models.Absensi, idUser int) (int, error)
+	Delete(ctx context.Context, idAbsensi int) error
+	// Rekap - Rekap per siswa satu kelas dalam rentang tanggal: siswa aktif kelas tersebut ditambah
+	// siswa lain yang punya catatan absensi di kelas itu
+	Rekap(ctx context.Context, idKelas int, idMapel *int, dari, sampai string) ([]models.RekapAbsensiSiswa, error)
+	// RekapSiswa - Rekap absensi harian seorang siswa di satu kelas dalam rentang tanggal
+	RekapSiswa(ctx context.Context, idSiswa, idKelas int, dari, sampai string) (models.RekapAbsensi, error)
+}
+
+const absensiColumns = `a.id_absensi, a.id_siswa, s.nama_siswa, a.id_kelas, a.id_mapel, COALESCE(mp.nama_mata_pelajaran, ''),
+	TO_CHAR(a.tanggal, 'YYYY-MM-DD'), a.status, a.keterangan`
+
+const absensiFrom = `
+	FROM absensi a
+	JOIN siswa s ON s.id_siswa = a.id_siswa
+	LEFT JOIN mata_pelajaran mp ON mp.id_mapel = a.id_mapel`
+
+// rekapColumns - Jumlah per status dari alias a; baris LEFT JOIN tanpa absensi tidak terhitung
+const rekapColumns = `
+	COUNT(*) FILTER (WHERE a.status = 'hadir'),
+	COUNT(*) FILTER (WHERE a.status = 'sakit'),
+	COUNT(*) FILTER (WHERE a.status = 'izin'),
+	COUNT(*) FILTER (WHERE a.status = 'alpa')`
+
+type absensiPostgres struct {
+	q DBTX
+}
+
+func scanAbsensi(row rowScanner) (models.Absensi, error) {
+	var a models.Absensi
+	err := row.Scan(&a.IDAbsensi, &a.IDSiswa, &a.NamaSiswa, &a.IDKelas, &a.IDMapel, &a.NamaMapel,
+		&a.Tanggal, &a.Status, &a.Keterangan)
+	return a, err
+}
+
+func scanRekap(row rowScanner, lead ...any) (models.RekapAbsensi, error) {
+	var r models.RekapAbsensi
+	err := row.Scan(append(lead, &r.Hadir, &r.Sakit, &r.Izin, &r.Alpa)...)
+	r.Hitung()
+	return r, err
+}
+
+func (r *absensiPostgres) ListByKelas(ctx context.Context, idKelas int, idMapel *int, tanggal string) ([]models.Absensi, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+absensiColumns+absensiFrom+`
+		WHERE a.id_kelas = $1 AND a.tanggal = $3
+		  AND (($2::int IS NULL AND a.id_mapel IS NULL) OR a.id_mapel = $2)
+		ORDER BY s.nama_siswa, a.id_siswa
+	`, idKelas, idMapel, tanggal)
+	return collect(rows, err, scanAbsensi)
+}
+
+func (r *absensiPostgres) ListBySiswa(ctx context.Context, idSiswa int, dari, sampai string) ([]models.Absensi, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+absensiColumns+absensiFrom+`
+		WHERE a.id_siswa = $1 AND a.tanggal BETWEEN $2 AND $3
+		ORDER BY a.tanggal, a.id_mapel NULLS FIRST
+	`, idSiswa, dari, sampai)
+	return collect(rows, err, scanAbsensi)
+}
+
+func (r *absensiPostgres) GetByID(ctx context.Context, idAbsensi int) (models.Absensi, error) {
+	a, err := scanAbsensi(r.q.QueryRowContext(ctx,
+		`SELECT `+absensiColumns+absensiFrom+` WHERE a.id_absensi = $1`, idAbsensi))
+	return a, notFound(err)
+}
+
+func (r *absensiPostgres) Upsert(ctx context.Context, a models.Absensi, idUser int) (int, error) {
+	// Index unik absensi harian dan per mapel berbeda, jadi target ON CONFLICT-nya juga berbeda
+	target := `(id_siswa, tanggal) WHERE id_mapel IS NULL`
+	if a.IDMapel != nil {
+		target = `(id_siswa, id_mapel, tanggal) WHERE id_mapel IS NOT NULL`
+	}
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+		INSERT INTO absensi (id_siswa, id_kelas, id_mapel, tanggal, status, keterangan, dicatat_oleh)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT `+target+` DO UPDATE
+		SET id_kelas = EXCLUDED.id_kelas, status = EXCLUDED.status, keterangan = EXCLUDED.keterangan,
+			dicatat_oleh = EXCLUDED.dicatat_oleh, dicatat_pada = NOW()
+		RETURNING id_absensi
+	`, a.IDSiswa, a.IDKelas, a.IDMapel, a.Tanggal, a.Status, a.Keterangan, idUser).Scan(&id)
+	return id, err
+}
+
+func (r *absensiPostgres) Delete(ctx context.Context, idAbsensi int) error {
+	return expectAffected(r.q.ExecContext(ctx, `DELETE FROM absensi WHERE id_absensi = $1`, idAbsensi))
+}
+
+func (r *absensiPostgres) Rekap(ctx context.Context, idKelas int, idMapel *int, dari, sampai string) ([]models.RekapAbsensiSiswa, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT s.id_siswa, s.nisn, s.nama_siswa, `+rekapColumns+`
+		FROM siswa s
+		LEFT JOIN absensi a ON a.id_siswa = s.id_siswa AND a.id_kelas = $1
+			AND a.tanggal BETWEEN $3 AND $4
+			AND (($2::int IS NULL AND a.id_mapel IS NULL) OR a.id_mapel = $2)
+		WHERE (s.id_kelas = $1 AND s.status = 'aktif') OR a.id_absensi IS NOT NULL
+		GROUP BY s.id_siswa, s.nisn, s.nama_siswa
+		ORDER BY s.nama_siswa, s.id_siswa
+	`, idKelas, idMapel, dari, sampai)
+	return collect(rows, err, func(row rowScanner) (models.RekapAbsensiSiswa, error) {
+		var rs models.RekapAbsensiSiswa
+		var err error
+		rs.RekapAbsensi, err = scanRekap(row, &rs.IDSiswa, &rs.NISN, &rs.NamaSiswa)
+		return rs, err
+	})
+}
+
+func (r *absensiPostgres) RekapSiswa(ctx context.Context, idSiswa, idKelas int, dari, sampai string) (models.RekapAbsensi, error) {
+	return scanRekap(r.q.QueryRowContext(ctx, `
+		SELECT `+rekapColumns+`
+		FROM absensi a
+		WHERE a.id_siswa = $1 AND a.id_kelas = $2 AND a.id_mapel IS NULL AND a.tanggal BETWEEN $3 AND $4
+	`, idSiswa, idKelas, dari, sampai))
+}
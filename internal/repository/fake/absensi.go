@@ -0,0 +1,197 @@
+package fake
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"myapp/internal/models"
+	"myapp/internal/repository"
+)
+
+type absensiFake struct {
+	s *Store
+}
+
+// absensiRow - Absensi beserta nama siswa dan nama mapelnya; catatan yang siswanya sudah dihapus tidak ikut
+func (s *Store) absensiRow(a models.Absensi) (models.Absensi, bool) {
+	sw, ok := s.siswa[a.IDSiswa]
+	if !ok {
+		return a, false
+	}
+	a.NamaSiswa, a.NamaMapel = sw.NamaSiswa, ""
+	if a.IDMapel != nil {
+		a.NamaMapel = s.mapel[*a.IDMapel].NamaMataPelajaran
+	}
+	return a, true
+}
+
+// sameMapel - absensi harian (idMapel nil) hanya cocok dengan catatan tanpa mapel
+func sameMapel(a models.Absensi, idMapel *int) bool {
+	if idMapel == nil {
+		return a.IDMapel == nil
+	}
+	return a.IDMapel != nil && *a.IDMapel == *idMapel
+}
+
+func (s *Store) absensiWhere(keep func(models.Absensi) bool) []models.Absensi {
+	var out []models.Absensi
+	for _, a := range rows(s.absensi, keep) {
+		if a, ok := s.absensiRow(a); ok {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+func hitung(r *models.RekapAbsensi, status string) {
+	switch status {
+	case models.AbsensiHadir:
+		r.Hadir++
+	case models.AbsensiSakit:
+		r.Sakit++
+	case models.AbsensiIzin:
+		r.Izin++
+	case models.AbsensiAlpa:
+		r.Alpa++
+	}
+}
+
+func (r *absensiFake) ListByKelas(ctx context.Context, idKelas int, idMapel *int, tanggal string) ([]models.Absensi, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	out := r.s.absensiWhere(func(a models.Absensi) bool {
+		return a.IDKelas == idKelas && a.Tanggal == tanggal && sameMapel(a, idMapel)
+	})
+	slices.SortStableFunc(out, func(a, b models.Absensi) int {
+		return cmp.Or(cmp.Compare(a.NamaSiswa, b.NamaSiswa), cmp.Compare(a.IDSiswa, b.IDSiswa))
+	})
+	return out, nil
+}
+
+func (r *absensiFake) ListBySiswa(ctx context.Context, idSiswa int, dari, sampai string) ([]models.Absensi, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	out := r.s.absensiWhere(func(a models.Absensi) bool {
+		return a.IDSiswa == idSiswa && a.Tanggal >= dari && a.Tanggal <= sampai
+	})
+	// ORDER BY tanggal, id_mapel NULLS FIRST
+	slices.SortStableFunc(out, func(a, b models.Absensi) int {
+		if c := cmp.Compare(a.Tanggal, b.Tanggal); c != 0 {
+			return c
+		}
+		switch {
+		case a.IDMapel == nil && b.IDMapel == nil:
+			return 0
+		case a.IDMapel == nil:
+			return -1
+		case b.IDMapel == nil:
+			return 1
+		}
+		return cmp.Compare(*a.IDMapel, *b.IDMapel)
+	})
+	return out, nil
+}
+
+func (r *absensiFake) GetByID(ctx context.Context, idAbsensi int) (models.Absensi, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return models.Absensi{}, err
+	}
+	a, ok := r.s.absensi[idAbsensi]
+	if ok {
+		a, ok = r.s.absensiRow(a)
+	}
+	if !ok {
+		return models.Absensi{}, repository.ErrNotFound
+	}
+	return a, nil
+}
+
+func (r *absensiFake) Upsert(ctx context.Context, a models.Absensi, idUser int) (int, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	a.NamaSiswa, a.NamaMapel = "", ""
+	if a.IDMapel != nil {
+		idMapel := *a.IDMapel
+		a.IDMapel = &idMapel
+	}
+	for id, old := range r.s.absensi {
+		if old.IDSiswa == a.IDSiswa && old.Tanggal == a.Tanggal && sameMapel(old, a.IDMapel) {
+			a.IDAbsensi = id
+			r.s.absensi[id] = a
+			return id, nil
+		}
+	}
+	a.IDAbsensi = r.s.nextID()
+	r.s.absensi[a.IDAbsensi] = a
+	return a.IDAbsensi, nil
+}
+
+func (r *absensiFake) Delete(ctx context.Context, idAbsensi int) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, ok := r.s.absensi[idAbsensi]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.s.absensi, idAbsensi)
+	return nil
+}
+
+func (r *absensiFake) Rekap(ctx context.Context, idKelas int, idMapel *int, dari, sampai string) ([]models.RekapAbsensiSiswa, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	rekap := map[int]*models.RekapAbsensi{}
+	for _, sw := range r.s.siswaAktif(idKelas) {
+		rekap[sw.IDSiswa] = &models.RekapAbsensi{}
+	}
+	for _, a := range r.s.absensi {
+		if a.IDKelas != idKelas || a.Tanggal < dari || a.Tanggal > sampai || !sameMapel(a, idMapel) {
+			continue
+		}
+		if rekap[a.IDSiswa] == nil {
+			rekap[a.IDSiswa] = &models.RekapAbsensi{}
+		}
+		hitung(rekap[a.IDSiswa], a.Status)
+	}
+
+	var out []models.RekapAbsensiSiswa
+	for _, sw := range r.s.siswaRows(func(sw models.Siswa) bool { return rekap[sw.IDSiswa] != nil }) {
+		rs := models.RekapAbsensiSiswa{IDSiswa: sw.IDSiswa, NISN: sw.NISN, NamaSiswa: sw.NamaSiswa, RekapAbsensi: *rekap[sw.IDSiswa]}
+		rs.Hitung()
+		out = append(out, rs)
+	}
+	return out, nil
+}
+
+func (r *absensiFake) RekapSiswa(ctx context.Context, idSiswa, idKelas int, dari, sampai string) (models.RekapAbsensi, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return models.RekapAbsensi{}, err
+	}
+	var rekap models.RekapAbsensi
+	for _, a := range r.s.absensi {
+		if a.IDSiswa == idSiswa && a.IDKelas == idKelas && a.IDMapel == nil && a.Tanggal >= dari && a.Tanggal <= sampai {
+			hitung(&rekap, a.Status)
+		}
+	}
+	rekap.Hitung()
+	return rekap, nil
+}
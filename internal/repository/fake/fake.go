@@ -0,0 +1,114 @@
+// Package fake - Implementasi repository di memori untuk test handler tanpa PostgreSQL
+package fake
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"myapp/internal/models"
+	"myapp/internal/repository"
+)
+
+// Store - Isi semua tabel. Foreign key dan cascade tidak ditiru; constraint unique yang dipakai
+// handler (username, NISN, nilai per siswa/mapel/semester, dan seterusnya) dicek seperti di PostgreSQL
+// dan menghasilkan repository.ErrConflict.
+type Store struct {
+	mu  sync.Mutex
+	err error
+	id  int
+
+	users       map[int]models.User
+	guru        map[int]models.Guru
+	siswa       map[int]models.Siswa
+	kelas       map[int]models.Kelas
+	mapel       map[int]models.MataPelajaran
+	komponen    map[int]komponenRow
+	nilai       map[int]models.Nilai
+	penilaian   map[int]penilaianRow
+	remedial    map[int]models.Remedial
+	tahunAjaran map[int]models.TahunAjaran
+	semester    map[int]models.Semester
+	riwayat     map[int]models.RiwayatKelas
+	absensi     map[int]models.Absensi
+}
+
+// New - Store kosong
+func New() *Store {
+	return &Store{
+		users:       map[int]models.User{},
+		guru:        map[int]models.Guru{},
+		siswa:       map[int]models.Siswa{},
+		kelas:       map[int]models.Kelas{},
+		mapel:       map[int]models.MataPelajaran{},
+		komponen:    map[int]komponenRow{},
+		nilai:       map[int]models.Nilai{},
+		penilaian:   map[int]penilaianRow{},
+		remedial:    map[int]models.Remedial{},
+		tahunAjaran: map[int]models.TahunAjaran{},
+		semester:    map[int]models.Semester{},
+		riwayat:     map[int]models.RiwayatKelas{},
+		absensi:     map[int]models.Absensi{},
+	}
+}
+
+// Repositories - Semua repository di atas Store yang sama. InTx langsung menjalankan fn
+// (tidak ada rollback).
+func (s *Store) Repositories() repository.Repositories {
+	return repository.Repositories{
+		Guru:          &guruFake{s},
+		Siswa:         &siswaFake{s},
+		Kelas:         &kelasFake{s},
+		MataPelajaran: &mataPelajaranFake{s},
+		Nilai:         &nilaiFake{s},
+		Komponen:      &komponenFake{s},
+		Remedial:      &remedialFake{s},
+		Periode:       &periodeFake{s},
+		RiwayatKelas:  &riwayatKelasFake{s},
+		Absensi:       &absensiFake{s},
+		User:          &userFake{s},
+	}
+}
+
+// SetErr - Semua method mengembalikan err sampai SetErr(nil), misalnya driver.ErrBadConn
+// untuk menguji respons 503 saat database tidak tersedia
+func (s *Store) SetErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// lock - Mengunci Store dan mengembalikan error yang diset lewat SetErr.
+// Pemanggil tetap harus defer s.mu.Unlock().
+func (s *Store) lock() error {
+	s.mu.Lock()
+	return s.err
+}
+
+// nextID - id baru, satu urutan untuk semua tabel
+func (s *Store) nextID() int {
+	s.id++
+	return s.id
+}
+
+// conflict - Error yang sama bentuknya dengan pelanggaran constraint unique di PostgreSQL
+func conflict(constraint string) error {
+	return fmt.Errorf("%w: %s", repository.ErrConflict, constraint)
+}
+
+// rows - Baris tabel yang lolos keep, urut id
+func rows[T any](table map[int]T, keep func(T) bool) []T {
+	var out []T
+	for _, id := range slices.Sorted(maps.Keys(table)) {
+		if keep == nil || keep(table[id]) {
+			out = append(out, table[id])
+		}
+	}
+	return out
+}
+
+// bobotNumeric - Bobot dibulatkan seperti kolom NUMERIC(5,4)
+func bobotNumeric(bobot float64) float64 {
+	return float64(models.BobotPoints(bobot)) / 10000
+}
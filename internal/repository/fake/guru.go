@@ -0,0 +1,101 @@
+package fake
+
+import (
+	"context"
+
+	"myapp/internal/models"
+	"myapp/internal/repository"
+)
+
+type guruFake struct {
+	s *Store
+}
+
+func (r *guruFake) List(ctx context.Context) ([]models.Guru, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return rows(r.s.guru, nil), nil
+}
+
+func (r *guruFake) GetByID(ctx context.Context, idGuru int) (models.Guru, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return models.Guru{}, err
+	}
+	g, ok := r.s.guru[idGuru]
+	if !ok {
+		return models.Guru{}, repository.ErrNotFound
+	}
+	return g, nil
+}
+
+func (r *guruFake) GetByUserID(ctx context.Context, idUser int) (models.Guru, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return models.Guru{}, err
+	}
+	for _, g := range rows(r.s.guru, nil) {
+		if g.IDUser == idUser {
+			return g, nil
+		}
+	}
+	return models.Guru{}, repository.ErrNotFound
+}
+
+func (r *guruFake) Create(ctx context.Context, g models.Guru) (int, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	g.IDGuru, g.Foto = r.s.nextID(), ""
+	r.s.guru[g.IDGuru] = g
+	return g.IDGuru, nil
+}
+
+func (r *guruFake) Update(ctx context.Context, idGuru int, g models.Guru) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, ok := r.s.guru[idGuru]; !ok {
+		return repository.ErrNotFound
+	}
+	g.IDGuru = idGuru
+	r.s.guru[idGuru] = g
+	return nil
+}
+
+func (r *guruFake) UpdateFoto(ctx context.Context, idGuru int, url string) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	g, ok := r.s.guru[idGuru]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	g.Foto = url
+	r.s.guru[idGuru] = g
+	return nil
+}
+
+func (r *guruFake) Delete(ctx context.Context, idGuru int) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, ok := r.s.guru[idGuru]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.s.guru, idGuru)
+	return nil
+}
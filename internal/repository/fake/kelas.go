@@ -0,0 +1,160 @@
+package fake
+
+import (
+	"context"
+
+	"myapp/internal/models"
+	"myapp/internal/repository"
+)
+
+type kelasFake struct {
+	s *Store
+}
+
+// kelasRow - Kelas beserta nama tahun ajaran dan jumlah siswa aktifnya
+func (s *Store) kelasRow(k models.Kelas) models.Kelas {
+	k.TahunAjaran = s.tahunAjaran[k.IDTahunAjaran].Nama
+	k.JumlahSiswa = len(s.siswaAktif(k.IDKelas))
+	return k
+}
+
+// kelasRows - Semua kelas yang lolos keep, urut id
+func (s *Store) kelasRows(keep func(models.Kelas) bool) []models.Kelas {
+	var out []models.Kelas
+	for _, k := range rows(s.kelas, keep) {
+		out = append(out, s.kelasRow(k))
+	}
+	return out
+}
+
+// guruUser - id_user guru, 0 jika guru tidak ada
+func (s *Store) guruUser(idGuru int) int {
+	return s.guru[idGuru].IDUser
+}
+
+func (r *kelasFake) List(ctx context.Context, idTahunAjaran *int) ([]models.Kelas, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return r.s.kelasRows(func(k models.Kelas) bool {
+		return idTahunAjaran == nil || k.IDTahunAjaran == *idTahunAjaran
+	}), nil
+}
+
+func (r *kelasFake) ListByGuru(ctx context.Context, idGuru int, idTahunAjaran *int) ([]models.Kelas, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return r.s.kelasRows(func(k models.Kelas) bool {
+		return k.IDGuru == idGuru && (idTahunAjaran == nil || k.IDTahunAjaran == *idTahunAjaran)
+	}), nil
+}
+
+func (r *kelasFake) GetByID(ctx context.Context, idKelas int) (models.Kelas, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return models.Kelas{}, err
+	}
+	k, ok := r.s.kelas[idKelas]
+	if !ok {
+		return models.Kelas{}, repository.ErrNotFound
+	}
+	return r.s.kelasRow(k), nil
+}
+
+func (r *kelasFake) IsTaughtBy(ctx context.Context, idKelas, idUser int) (bool, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return false, err
+	}
+	k, ok := r.s.kelas[idKelas]
+	return ok && r.s.guruUser(k.IDGuru) == idUser, nil
+}
+
+func (r *kelasFake) FindForSiswa(ctx context.Context, idSiswa, idTahunAjaran int) (models.Kelas, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return models.Kelas{}, err
+	}
+	siswa, hasSiswa := r.s.siswa[idSiswa]
+
+	// Urutan sama dengan PostgreSQL: kelas saat ini, lalu tanggal masuk riwayat terakhir, lalu id_kelas terbesar
+	var found *models.Kelas
+	var foundMasuk string
+	for _, k := range rows(r.s.kelas, func(k models.Kelas) bool { return k.IDTahunAjaran == idTahunAjaran }) {
+		if hasSiswa && siswa.IDKelas != nil && *siswa.IDKelas == k.IDKelas {
+			return r.s.kelasRow(k), nil
+		}
+		masuk, diRiwayat := "", false
+		for _, rk := range r.s.riwayat {
+			if rk.IDSiswa == idSiswa && rk.IDKelas == k.IDKelas {
+				diRiwayat = true
+				masuk = max(masuk, rk.TanggalMasuk)
+			}
+		}
+		if !diRiwayat && !r.s.adaNilaiDiKelas(idSiswa, k.IDKelas) {
+			continue
+		}
+		if found == nil || masuk >= foundMasuk {
+			found, foundMasuk = &k, masuk
+		}
+	}
+	if found == nil {
+		return models.Kelas{}, repository.ErrNotFound
+	}
+	return r.s.kelasRow(*found), nil
+}
+
+// adaNilaiDiKelas - true jika siswa punya nilai di salah satu mapel kelas
+func (s *Store) adaNilaiDiKelas(idSiswa, idKelas int) bool {
+	for _, n := range s.nilai {
+		if n.IDSiswa == idSiswa && s.mapel[n.IDMapel].IDKelas == idKelas {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *kelasFake) Create(ctx context.Context, k models.Kelas) (int, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	id := r.s.nextID()
+	r.s.kelas[id] = models.Kelas{IDKelas: id, IDGuru: k.IDGuru, NamaKelas: k.NamaKelas, IDTahunAjaran: k.IDTahunAjaran}
+	return id, nil
+}
+
+func (r *kelasFake) Update(ctx context.Context, idKelas int, k models.Kelas) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, ok := r.s.kelas[idKelas]; !ok {
+		return repository.ErrNotFound
+	}
+	r.s.kelas[idKelas] = models.Kelas{IDKelas: idKelas, IDGuru: k.IDGuru, NamaKelas: k.NamaKelas, IDTahunAjaran: k.IDTahunAjaran}
+	return nil
+}
+
+func (r *kelasFake) Delete(ctx context.Context, idKelas int) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, ok := r.s.kelas[idKelas]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.s.kelas, idKelas)
+	return nil
+}
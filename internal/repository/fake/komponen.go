@@ -0,0 +1,187 @@
+package fake
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"myapp/internal/models"
+	"myapp/internal/repository"
+)
+
+type komponenFake struct {
+	s *Store
+}
+
+// komponenRow - Komponen beserta bobot desimalnya (Bobot di KomponenPenilaian sudah diformat persen)
+type komponenRow struct {
+	k     models.KomponenPenilaian
+	bobot float64
+}
+
+// byTenggat - ORDER BY tenggat NULLS LAST, id_komponen
+func byTenggat(a, b komponenRow) int {
+	switch {
+	case a.k.Tenggat == nil && b.k.Tenggat != nil:
+		return 1
+	case a.k.Tenggat != nil && b.k.Tenggat == nil:
+		return -1
+	case a.k.Tenggat != nil && *a.k.Tenggat != *b.k.Tenggat:
+		return cmp.Compare(*a.k.Tenggat, *b.k.Tenggat)
+	}
+	return cmp.Compare(a.k.IDKomponen, b.k.IDKomponen)
+}
+
+func (s *Store) komponenByMapel(idMapel int) []komponenRow {
+	out := rows(s.komponen, func(k komponenRow) bool { return k.k.IDMapel == idMapel })
+	slices.SortFunc(out, byTenggat)
+	return out
+}
+
+func (s *Store) komponenNamaTaken(idMapel int, nama string, except int) bool {
+	for id, k := range s.komponen {
+		if id != except && k.k.IDMapel == idMapel && k.k.NamaKomponen == nama {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *komponenFake) ListByMapel(ctx context.Context, idMapel int) ([]models.KomponenPenilaian, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	var out []models.KomponenPenilaian
+	for _, k := range r.s.komponenByMapel(idMapel) {
+		out = append(out, k.k)
+	}
+	return out, nil
+}
+
+func (r *komponenFake) ListNilaiSiswa(ctx context.Context, idMapel, idSiswa, idSemester int) ([]models.KomponenNilai, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	idNilai := 0
+	for _, n := range r.s.nilai {
+		if n.IDSiswa == idSiswa && n.IDMapel == idMapel && n.IDSemester == idSemester {
+			idNilai = n.IDNilai
+		}
+	}
+	var out []models.KomponenNilai
+	for _, k := range r.s.komponenByMapel(idMapel) {
+		kn := models.KomponenNilai{KomponenPenilaian: k.k}
+		for id, p := range r.s.penilaian {
+			if idNilai != 0 && p.idNilai == idNilai && p.idKomponen != nil && *p.idKomponen == k.k.IDKomponen {
+				id, nilai := id, p.nilai
+				kn.IDPenilaian, kn.Nilai = &id, &nilai
+			}
+		}
+		out = append(out, kn)
+	}
+	return out, nil
+}
+
+func (r *komponenFake) GetByID(ctx context.Context, idKomponen int) (models.KomponenPenilaian, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return models.KomponenPenilaian{}, err
+	}
+	k, ok := r.s.komponen[idKomponen]
+	if !ok {
+		return models.KomponenPenilaian{}, repository.ErrNotFound
+	}
+	return k.k, nil
+}
+
+func komponenRowOf(id int, k models.KomponenPenilaian, bobot float64) komponenRow {
+	bobot = bobotNumeric(bobot)
+	k.IDKomponen, k.Bobot = id, models.FormatBobot(bobot)
+	return komponenRow{k: k, bobot: bobot}
+}
+
+func (r *komponenFake) Create(ctx context.Context, k models.KomponenPenilaian, bobot float64) (int, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	if r.s.komponenNamaTaken(k.IDMapel, k.NamaKomponen, 0) {
+		return 0, conflict("komponen_penilaian_id_mapel_nama_key")
+	}
+	id := r.s.nextID()
+	r.s.komponen[id] = komponenRowOf(id, k, bobot)
+	return id, nil
+}
+
+func (r *komponenFake) Update(ctx context.Context, idKomponen int, k models.KomponenPenilaian, bobot float64) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	old, ok := r.s.komponen[idKomponen]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if r.s.komponenNamaTaken(old.k.IDMapel, k.NamaKomponen, idKomponen) {
+		return conflict("komponen_penilaian_id_mapel_nama_key")
+	}
+	k.IDMapel = old.k.IDMapel
+	r.s.komponen[idKomponen] = komponenRowOf(idKomponen, k, bobot)
+	return nil
+}
+
+func (r *komponenFake) Delete(ctx context.Context, idKomponen int) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, ok := r.s.komponen[idKomponen]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.s.komponen, idKomponen)
+	return nil
+}
+
+func (r *komponenFake) SumBobot(ctx context.Context, idMapel, exceptKomponen int) (float64, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	var points int64
+	for id, k := range r.s.komponen {
+		if k.k.IDMapel == idMapel && id != exceptKomponen {
+			points += models.BobotPoints(k.bobot)
+		}
+	}
+	return float64(points) / 10000, nil
+}
+
+func (r *komponenFake) MaxNilai(ctx context.Context, idKomponen int) (int, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	maxNilai := 0
+	for id, p := range r.s.penilaian {
+		if p.idKomponen == nil || *p.idKomponen != idKomponen {
+			continue
+		}
+		maxNilai = max(maxNilai, p.nilai)
+		for _, rm := range r.s.remedial {
+			if rm.IDPenilaian == id {
+				maxNilai = max(maxNilai, rm.Nilai)
+			}
+		}
+	}
+	return maxNilai, nil
+}
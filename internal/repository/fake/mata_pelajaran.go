@@ -0,0 +1,155 @@
+package fake
+
+import (
+	"context"
+
+	"myapp/internal/models"
+	"myapp/internal/repository"
+)
+
+type mataPelajaranFake struct {
+	s *Store
+}
+
+// mapelRow - Sama dengan Value/Scan kolom JSONB: skala kosong disimpan sebagai skala bawaan
+func mapelRow(id int, mp models.MataPelajaran) models.MataPelajaran {
+	mp.IDMapel = id
+	if mp.SkalaPredikat == nil {
+		mp.SkalaPredikat = append(models.SkalaPredikat(nil), models.DefaultSkalaPredikat...)
+	}
+	return mp
+}
+
+func (r *mataPelajaranFake) List(ctx context.Context, idTahunAjaran *int) ([]models.MataPelajaran, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return rows(r.s.mapel, func(mp models.MataPelajaran) bool {
+		k, ok := r.s.kelas[mp.IDKelas]
+		return ok && (idTahunAjaran == nil || k.IDTahunAjaran == *idTahunAjaran)
+	}), nil
+}
+
+func (r *mataPelajaranFake) ListByKelas(ctx context.Context, idKelas int) ([]models.MataPelajaran, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return rows(r.s.mapel, func(mp models.MataPelajaran) bool { return mp.IDKelas == idKelas }), nil
+}
+
+func (r *mataPelajaranFake) ListBySiswa(ctx context.Context, idSiswa int) ([]models.MataPelajaran, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	sw, ok := r.s.siswa[idSiswa]
+	if !ok || sw.IDKelas == nil {
+		return nil, nil
+	}
+	return rows(r.s.mapel, func(mp models.MataPelajaran) bool { return mp.IDKelas == *sw.IDKelas }), nil
+}
+
+func (r *mataPelajaranFake) GetByID(ctx context.Context, idMapel int) (models.MataPelajaran, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return models.MataPelajaran{}, err
+	}
+	mp, ok := r.s.mapel[idMapel]
+	if !ok {
+		return models.MataPelajaran{}, repository.ErrNotFound
+	}
+	return mp, nil
+}
+
+func (r *mataPelajaranFake) GetDetail(ctx context.Context, idMapel int) (models.MataPelajaranDetail, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return models.MataPelajaranDetail{}, err
+	}
+	mp, ok := r.s.mapel[idMapel]
+	if !ok {
+		return models.MataPelajaranDetail{}, repository.ErrNotFound
+	}
+	k, ok := r.s.kelas[mp.IDKelas]
+	if !ok {
+		return models.MataPelajaranDetail{}, repository.ErrNotFound
+	}
+	return models.MataPelajaranDetail{
+		IDMapel:           mp.IDMapel,
+		IDKelas:           k.IDKelas,
+		NamaMataPelajaran: mp.NamaMataPelajaran,
+		NamaGuru:          r.s.guru[k.IDGuru].NamaGuru,
+		TahunAjaran:       r.s.tahunAjaran[k.IDTahunAjaran].Nama,
+		JumlahSiswa:       len(r.s.siswaAktif(k.IDKelas)),
+	}, nil
+}
+
+func (r *mataPelajaranFake) Create(ctx context.Context, mp models.MataPelajaran) (int, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	id := r.s.nextID()
+	r.s.mapel[id] = mapelRow(id, mp)
+	return id, nil
+}
+
+func (r *mataPelajaranFake) Update(ctx context.Context, idMapel int, mp models.MataPelajaran) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, ok := r.s.mapel[idMapel]; !ok {
+		return repository.ErrNotFound
+	}
+	r.s.mapel[idMapel] = mapelRow(idMapel, mp)
+	return nil
+}
+
+func (r *mataPelajaranFake) Delete(ctx context.Context, idMapel int) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, ok := r.s.mapel[idMapel]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.s.mapel, idMapel)
+	return nil
+}
+
+func (r *mataPelajaranFake) IsTaughtBy(ctx context.Context, idMapel, idUser int) (bool, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return false, err
+	}
+	mp, ok := r.s.mapel[idMapel]
+	if !ok {
+		return false, nil
+	}
+	k, ok := r.s.kelas[mp.IDKelas]
+	return ok && r.s.guruUser(k.IDGuru) == idUser, nil
+}
+
+func (r *mataPelajaranFake) Lock(ctx context.Context, idMapel int) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, ok := r.s.mapel[idMapel]; !ok {
+		return repository.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,472 @@
+package fake
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+
+	"myapp/internal/models"
+	"myapp/internal/repository"
+)
+
+type nilaiFake struct {
+	s *Store
+}
+
+// penilaianRow - Baris tabel penilaian, bobot disimpan desimal seperti NUMERIC(5,4)
+type penilaianRow struct {
+	idNilai    int
+	nama       string
+	nilai      int
+	bobot      float64
+	idKomponen *int
+}
+
+// penilaianOf - Penilaian beserta kolom dari nilai induknya
+func (s *Store) penilaianOf(id int, p penilaianRow) models.Penilaian {
+	n := s.nilai[p.idNilai]
+	return models.Penilaian{
+		IDPenilaian: id,
+		IDNilai:     p.idNilai,
+		IDMapel:     n.IDMapel,
+		IDSiswa:     n.IDSiswa,
+		IDSemester:  n.IDSemester,
+		NamaNilai:   p.nama,
+		Nilai:       p.nilai,
+		Bobot:       models.FormatBobot(p.bobot),
+		IDKomponen:  p.idKomponen,
+	}
+}
+
+// penilaianWhere - Penilaian yang lolos keep, urut id_penilaian
+func (s *Store) penilaianWhere(keep func(id int, p penilaianRow) bool) []models.Penilaian {
+	var out []models.Penilaian
+	for id, p := range s.penilaian {
+		if keep(id, p) {
+			out = append(out, s.penilaianOf(id, p))
+		}
+	}
+	slices.SortFunc(out, func(a, b models.Penilaian) int { return cmp.Compare(a.IDPenilaian, b.IDPenilaian) })
+	return out
+}
+
+// total - total_nilai dihitung models.TotalNilai, padanan weightedTotal di PostgreSQL
+func (s *Store) total(idNilai int) float64 {
+	n := s.nilai[idNilai]
+	mp := s.mapel[n.IDMapel]
+	var tertimbang []models.PenilaianTertimbang
+	for id, p := range s.penilaian {
+		if p.idNilai != idNilai {
+			continue
+		}
+		pt := models.PenilaianTertimbang{Nilai: p.nilai, Bobot: p.bobot}
+		if p.idKomponen != nil {
+			pt.NilaiMaks = s.komponen[*p.idKomponen].k.NilaiMaks
+		}
+		for _, rm := range s.remedial {
+			if rm.IDPenilaian == id {
+				pt.Remedial = append(pt.Remedial, rm)
+			}
+		}
+		tertimbang = append(tertimbang, pt)
+	}
+	return models.TotalNilai(mp.AturanRemedial, mp.KKM, tertimbang)
+}
+
+// recompute - Menghitung ulang total_nilai, true jika nilainya berubah
+func (s *Store) recompute(idNilai int) bool {
+	n := s.nilai[idNilai]
+	total := s.total(idNilai)
+	if total == n.TotalNilai {
+		return false
+	}
+	n.TotalNilai = total
+	s.nilai[idNilai] = n
+	return true
+}
+
+func (r *nilaiFake) GetBySiswaAndMapel(ctx context.Context, idSiswa, idMapel, idSemester int) (models.Nilai, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return models.Nilai{}, err
+	}
+	for _, n := range r.s.nilai {
+		if n.IDSiswa == idSiswa && n.IDMapel == idMapel && n.IDSemester == idSemester {
+			return n, nil
+		}
+	}
+	return models.Nilai{}, repository.ErrNotFound
+}
+
+func (r *nilaiFake) FindOrCreate(ctx context.Context, idSiswa, idMapel, idSemester int) (int, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	for _, n := range r.s.nilai {
+		if n.IDSiswa == idSiswa && n.IDMapel == idMapel && n.IDSemester == idSemester {
+			return n.IDNilai, nil
+		}
+	}
+	id := r.s.nextID()
+	r.s.nilai[id] = models.Nilai{IDNilai: id, IDSiswa: idSiswa, IDMapel: idMapel, IDSemester: idSemester}
+	return id, nil
+}
+
+func (r *nilaiFake) ListBySiswa(ctx context.Context, idSiswa, idSemester int) ([]models.NilaiMapel, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	var out []models.NilaiMapel
+	for _, n := range rows(r.s.nilai, nil) {
+		mp, ok := r.s.mapel[n.IDMapel]
+		if !ok || n.IDSiswa != idSiswa || n.IDSemester != idSemester {
+			continue
+		}
+		out = append(out, models.NilaiMapel{ID: n.IDNilai, Nilai: n.TotalNilai, Mapel: mp.NamaMataPelajaran, KKM: mp.KKM, Skala: mp.SkalaPredikat})
+	}
+	slices.SortStableFunc(out, func(a, b models.NilaiMapel) int { return cmp.Compare(a.Mapel, b.Mapel) })
+	return out, nil
+}
+
+func (r *nilaiFake) ListByKelas(ctx context.Context, idKelas, idSemester int) ([]models.Nilai, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return rows(r.s.nilai, func(n models.Nilai) bool {
+		mp, ok := r.s.mapel[n.IDMapel]
+		return ok && mp.IDKelas == idKelas && n.IDSemester == idSemester
+	}), nil
+}
+
+func (r *nilaiFake) ListBelumTuntas(ctx context.Context, idMapel, idSemester int) ([]models.SiswaRemedial, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	mp, ok := r.s.mapel[idMapel]
+	if !ok {
+		return nil, nil
+	}
+	var out []models.SiswaRemedial
+	for _, sw := range r.s.siswaRows(func(sw models.Siswa) bool {
+		return inKelas(sw, mp.IDKelas) && sw.Status == models.SiswaAktif
+	}) {
+		for _, n := range r.s.nilai {
+			if n.IDSiswa == sw.IDSiswa && n.IDMapel == idMapel && n.IDSemester == idSemester && n.TotalNilai < mp.KKM {
+				out = append(out, models.SiswaRemedial{
+					IDSiswa: sw.IDSiswa, NISN: sw.NISN, NamaSiswa: sw.NamaSiswa, TotalNilai: n.TotalNilai, KKM: mp.KKM,
+				})
+			}
+		}
+	}
+	return out, nil
+}
+
+func (r *nilaiFake) ListPeringkat(ctx context.Context, idKelas int, idMapel *int, idSemester int) ([]models.PeringkatSiswa, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	jumlah, count := map[int]float64{}, map[int]int{}
+	for _, n := range r.s.nilai {
+		mp, ok := r.s.mapel[n.IDMapel]
+		if !ok || mp.IDKelas != idKelas || (idMapel != nil && mp.IDMapel != *idMapel) || n.IDSemester != idSemester {
+			continue
+		}
+		jumlah[n.IDSiswa] += n.TotalNilai
+		count[n.IDSiswa]++
+	}
+
+	var out []models.PeringkatSiswa
+	for _, sw := range r.s.siswaRows(func(sw models.Siswa) bool { return count[sw.IDSiswa] > 0 }) {
+		out = append(out, models.PeringkatSiswa{
+			IDSiswa:     sw.IDSiswa,
+			NISN:        sw.NISN,
+			NamaSiswa:   sw.NamaSiswa,
+			RataRata:    math.Round(jumlah[sw.IDSiswa]/float64(count[sw.IDSiswa])*100) / 100,
+			JumlahMapel: count[sw.IDSiswa],
+		})
+	}
+	// DENSE_RANK dari rata-rata tertinggi; PERCENT_RANK = siswa dengan rata-rata lebih rendah / (n - 1)
+	for i := range out {
+		lebihTinggi, lebihRendah := map[float64]bool{}, 0
+		for _, other := range out {
+			if other.RataRata > out[i].RataRata {
+				lebihTinggi[other.RataRata] = true
+			} else if other.RataRata < out[i].RataRata {
+				lebihRendah++
+			}
+		}
+		out[i].Peringkat = len(lebihTinggi) + 1
+		out[i].Persentil = 100
+		if len(out) > 1 {
+			out[i].Persentil = math.Round(float64(lebihRendah)/float64(len(out)-1)*10000) / 100
+		}
+	}
+	slices.SortStableFunc(out, func(a, b models.PeringkatSiswa) int { return cmp.Compare(a.Peringkat, b.Peringkat) })
+	return out, nil
+}
+
+func (r *nilaiFake) ListRapor(ctx context.Context, idSiswa, idKelas, idSemester int) ([]models.RaporMapel, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	k, ok := r.s.kelas[idKelas]
+	if !ok {
+		return nil, nil
+	}
+	g, ok := r.s.guru[k.IDGuru]
+	if !ok {
+		return nil, nil
+	}
+	var out []models.RaporMapel
+	for _, mp := range rows(r.s.mapel, func(mp models.MataPelajaran) bool { return mp.IDKelas == idKelas }) {
+		m := models.RaporMapel{IDMapel: mp.IDMapel, NamaMapel: mp.NamaMataPelajaran, KKM: mp.KKM, NamaGuru: g.NamaGuru, Skala: mp.SkalaPredikat}
+		for _, n := range r.s.nilai {
+			if n.IDSiswa == idSiswa && n.IDMapel == mp.IDMapel && n.IDSemester == idSemester {
+				total := n.TotalNilai
+				m.TotalNilai = &total
+			}
+		}
+		out = append(out, m)
+	}
+	slices.SortStableFunc(out, func(a, b models.RaporMapel) int { return cmp.Compare(a.NamaMapel, b.NamaMapel) })
+	return out, nil
+}
+
+func (r *nilaiFake) Lock(ctx context.Context, idNilai int) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, ok := r.s.nilai[idNilai]; !ok {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *nilaiFake) RecomputeTotal(ctx context.Context, idNilai int) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, ok := r.s.nilai[idNilai]; !ok {
+		return repository.ErrNotFound
+	}
+	r.s.recompute(idNilai)
+	return nil
+}
+
+func (r *nilaiFake) RecomputeAll(ctx context.Context) (int64, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	var changed int64
+	for id := range r.s.nilai {
+		if r.s.recompute(id) {
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+func (r *nilaiFake) RecomputeByMapel(ctx context.Context, idMapel int) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	for id, n := range r.s.nilai {
+		if n.IDMapel == idMapel {
+			r.s.recompute(id)
+		}
+	}
+	return nil
+}
+
+func (r *nilaiFake) ListPenilaian(ctx context.Context, idSemester *int) ([]models.Penilaian, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return r.s.penilaianWhere(func(_ int, p penilaianRow) bool {
+		return idSemester == nil || r.s.nilai[p.idNilai].IDSemester == *idSemester
+	}), nil
+}
+
+func (r *nilaiFake) ListPenilaianByNilai(ctx context.Context, idNilai int) ([]models.Penilaian, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return r.s.penilaianWhere(func(_ int, p penilaianRow) bool { return p.idNilai == idNilai }), nil
+}
+
+func (r *nilaiFake) ListPenilaianByMapel(ctx context.Context, idMapel, idSemester int) ([]models.Penilaian, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	out := r.s.penilaianWhere(func(_ int, p penilaianRow) bool {
+		n := r.s.nilai[p.idNilai]
+		return n.IDMapel == idMapel && n.IDSemester == idSemester
+	})
+	slices.SortStableFunc(out, func(a, b models.Penilaian) int { return cmp.Compare(a.IDSiswa, b.IDSiswa) })
+	return out, nil
+}
+
+func (r *nilaiFake) GetPenilaian(ctx context.Context, idPenilaian int) (models.Penilaian, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return models.Penilaian{}, err
+	}
+	p, ok := r.s.penilaian[idPenilaian]
+	if !ok {
+		return models.Penilaian{}, repository.ErrNotFound
+	}
+	return r.s.penilaianOf(idPenilaian, p), nil
+}
+
+func (r *nilaiFake) SumBobot(ctx context.Context, idNilai, exceptPenilaian int) (float64, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	var points int64
+	for id, p := range r.s.penilaian {
+		if p.idNilai == idNilai && id != exceptPenilaian {
+			points += models.BobotPoints(p.bobot)
+		}
+	}
+	return float64(points) / 10000, nil
+}
+
+func (r *nilaiFake) MapelOfPenilaian(ctx context.Context, idPenilaian int) (int, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	p, ok := r.s.penilaian[idPenilaian]
+	if !ok {
+		return 0, repository.ErrNotFound
+	}
+	return r.s.nilai[p.idNilai].IDMapel, nil
+}
+
+func (r *nilaiFake) CreatePenilaian(ctx context.Context, idNilai int, namaNilai string, nilai int, bobot float64, idKomponen *int) (int, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	if idKomponen != nil {
+		for _, p := range r.s.penilaian {
+			if p.idNilai == idNilai && p.idKomponen != nil && *p.idKomponen == *idKomponen {
+				return 0, conflict("penilaian_id_nilai_id_komponen_key")
+			}
+		}
+		id := *idKomponen
+		idKomponen = &id
+	}
+	id := r.s.nextID()
+	r.s.penilaian[id] = penilaianRow{idNilai: idNilai, nama: namaNilai, nilai: nilai, bobot: bobotNumeric(bobot), idKomponen: idKomponen}
+	return id, nil
+}
+
+func (r *nilaiFake) FindPenilaianKomponen(ctx context.Context, idNilai, idKomponen int) (int, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	for id, p := range r.s.penilaian {
+		if p.idNilai == idNilai && p.idKomponen != nil && *p.idKomponen == idKomponen {
+			return id, nil
+		}
+	}
+	return 0, repository.ErrNotFound
+}
+
+func (r *nilaiFake) UpdatePenilaian(ctx context.Context, idPenilaian int, namaNilai string, nilai int, bobot float64) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	p, ok := r.s.penilaian[idPenilaian]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	p.nama, p.nilai, p.bobot = namaNilai, nilai, bobotNumeric(bobot)
+	r.s.penilaian[idPenilaian] = p
+	return nil
+}
+
+func (r *nilaiFake) DeletePenilaian(ctx context.Context, idPenilaian int) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, ok := r.s.penilaian[idPenilaian]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.s.penilaian, idPenilaian)
+	return nil
+}
+
+func (r *nilaiFake) SyncKomponen(ctx context.Context, idKomponen int, nama string, bobot float64) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	for id, p := range r.s.penilaian {
+		if p.idKomponen != nil && *p.idKomponen == idKomponen {
+			p.nama, p.bobot = nama, bobotNumeric(bobot)
+			r.s.penilaian[id] = p
+		}
+	}
+	return nil
+}
+
+func (r *nilaiFake) MaxSumBobotByMapel(ctx context.Context, idMapel int) (float64, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	points := map[int]int64{}
+	for _, p := range r.s.penilaian {
+		if r.s.nilai[p.idNilai].IDMapel == idMapel {
+			points[p.idNilai] += models.BobotPoints(p.bobot)
+		}
+	}
+	var maxPoints int64
+	for _, pt := range points {
+		maxPoints = max(maxPoints, pt)
+	}
+	return float64(maxPoints) / 10000, nil
+}
@@ -0,0 +1,186 @@
+package fake
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"myapp/internal/models"
+	"myapp/internal/repository"
+)
+
+type periodeFake struct {
+	s *Store
+}
+
+// semesterRow - Semester beserta nama tahun ajarannya
+func (s *Store) semesterRow(sm models.Semester) models.Semester {
+	sm.TahunAjaran = s.tahunAjaran[sm.IDTahunAjaran].Nama
+	return sm
+}
+
+func (r *periodeFake) ListTahunAjaran(ctx context.Context) ([]models.TahunAjaran, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	out := rows(r.s.tahunAjaran, nil)
+	slices.SortStableFunc(out, func(a, b models.TahunAjaran) int { return cmp.Compare(b.Nama, a.Nama) })
+	return out, nil
+}
+
+func (r *periodeFake) GetTahunAjaran(ctx context.Context, idTahunAjaran int) (models.TahunAjaran, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return models.TahunAjaran{}, err
+	}
+	t, ok := r.s.tahunAjaran[idTahunAjaran]
+	if !ok {
+		return models.TahunAjaran{}, repository.ErrNotFound
+	}
+	return t, nil
+}
+
+func (r *periodeFake) FindTahunAjaran(ctx context.Context, nama string) (models.TahunAjaran, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return models.TahunAjaran{}, err
+	}
+	for _, t := range r.s.tahunAjaran {
+		if t.Nama == nama {
+			return t, nil
+		}
+	}
+	return models.TahunAjaran{}, repository.ErrNotFound
+}
+
+func (r *periodeFake) CreateTahunAjaran(ctx context.Context, t models.TahunAjaran) (int, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	for _, other := range r.s.tahunAjaran {
+		if other.Nama == t.Nama {
+			return 0, conflict("tahun_ajaran_nama_key")
+		}
+	}
+	id := r.s.nextID()
+	r.s.tahunAjaran[id] = models.TahunAjaran{IDTahunAjaran: id, Nama: t.Nama}
+	return id, nil
+}
+
+func (r *periodeFake) ListSemester(ctx context.Context, idTahunAjaran *int) ([]models.Semester, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	var out []models.Semester
+	for _, sm := range rows(r.s.semester, nil) {
+		if idTahunAjaran == nil || sm.IDTahunAjaran == *idTahunAjaran {
+			out = append(out, r.s.semesterRow(sm))
+		}
+	}
+	slices.SortStableFunc(out, func(a, b models.Semester) int { return cmp.Compare(b.TanggalMulai, a.TanggalMulai) })
+	return out, nil
+}
+
+func (r *periodeFake) GetSemester(ctx context.Context, idSemester int) (models.Semester, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return models.Semester{}, err
+	}
+	sm, ok := r.s.semester[idSemester]
+	if !ok {
+		return models.Semester{}, repository.ErrNotFound
+	}
+	return r.s.semesterRow(sm), nil
+}
+
+func (r *periodeFake) ActiveSemester(ctx context.Context) (models.Semester, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return models.Semester{}, err
+	}
+	for _, sm := range r.s.semester {
+		if sm.Aktif {
+			return r.s.semesterRow(sm), nil
+		}
+	}
+	return models.Semester{}, repository.ErrNotFound
+}
+
+func (r *periodeFake) DefaultSemester(ctx context.Context, idTahunAjaran int) (models.Semester, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return models.Semester{}, err
+	}
+	var found *models.Semester
+	for _, sm := range rows(r.s.semester, nil) {
+		if sm.IDTahunAjaran != idTahunAjaran {
+			continue
+		}
+		// ORDER BY aktif DESC, semester DESC
+		if found == nil || (sm.Aktif && !found.Aktif) || (sm.Aktif == found.Aktif && sm.Semester > found.Semester) {
+			found = &sm
+		}
+	}
+	if found == nil {
+		return models.Semester{}, repository.ErrNotFound
+	}
+	return r.s.semesterRow(*found), nil
+}
+
+func (r *periodeFake) CreateSemester(ctx context.Context, sm models.Semester) (int, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	for _, other := range r.s.semester {
+		if other.IDTahunAjaran == sm.IDTahunAjaran && other.Semester == sm.Semester {
+			return 0, conflict("semester_id_tahun_ajaran_semester_key")
+		}
+	}
+	sm.IDSemester, sm.TahunAjaran, sm.Aktif = r.s.nextID(), "", false
+	r.s.semester[sm.IDSemester] = sm
+	return sm.IDSemester, nil
+}
+
+func (r *periodeFake) UpdateTanggalSemester(ctx context.Context, idSemester int, sm models.Semester) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	old, ok := r.s.semester[idSemester]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	old.TanggalMulai, old.TanggalSelesai = sm.TanggalMulai, sm.TanggalSelesai
+	r.s.semester[idSemester] = old
+	return nil
+}
+
+func (r *periodeFake) Activate(ctx context.Context, idSemester int) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, ok := r.s.semester[idSemester]; !ok {
+		return repository.ErrNotFound
+	}
+	for id, sm := range r.s.semester {
+		sm.Aktif = id == idSemester
+		r.s.semester[id] = sm
+	}
+	return nil
+}
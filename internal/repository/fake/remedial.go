@@ -0,0 +1,82 @@
+package fake
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"myapp/internal/models"
+	"myapp/internal/repository"
+)
+
+type remedialFake struct {
+	s *Store
+}
+
+// byTanggal - ORDER BY tanggal, id_remedial
+func byTanggal(a, b models.Remedial) int {
+	return cmp.Or(cmp.Compare(a.Tanggal, b.Tanggal), cmp.Compare(a.IDRemedial, b.IDRemedial))
+}
+
+func (r *remedialFake) ListByPenilaian(ctx context.Context, idPenilaian int) ([]models.Remedial, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	out := rows(r.s.remedial, func(rm models.Remedial) bool { return rm.IDPenilaian == idPenilaian })
+	slices.SortFunc(out, byTanggal)
+	return out, nil
+}
+
+func (r *remedialFake) ListByMapel(ctx context.Context, idMapel, idSemester int) ([]models.Remedial, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	out := rows(r.s.remedial, func(rm models.Remedial) bool {
+		p, ok := r.s.penilaian[rm.IDPenilaian]
+		n := r.s.nilai[p.idNilai]
+		return ok && n.IDMapel == idMapel && n.IDSemester == idSemester
+	})
+	slices.SortFunc(out, byTanggal)
+	return out, nil
+}
+
+func (r *remedialFake) GetByID(ctx context.Context, idRemedial int) (models.Remedial, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return models.Remedial{}, err
+	}
+	rm, ok := r.s.remedial[idRemedial]
+	if !ok {
+		return models.Remedial{}, repository.ErrNotFound
+	}
+	return rm, nil
+}
+
+func (r *remedialFake) Create(ctx context.Context, rm models.Remedial) (int, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	rm.IDRemedial = r.s.nextID()
+	r.s.remedial[rm.IDRemedial] = rm
+	return rm.IDRemedial, nil
+}
+
+func (r *remedialFake) Delete(ctx context.Context, idRemedial int) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, ok := r.s.remedial[idRemedial]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.s.remedial, idRemedial)
+	return nil
+}
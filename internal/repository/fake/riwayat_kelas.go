@@ -0,0 +1,64 @@
+package fake
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"myapp/internal/models"
+)
+
+type riwayatKelasFake struct {
+	s *Store
+}
+
+func (r *riwayatKelasFake) ListBySiswa(ctx context.Context, idSiswa int) ([]models.RiwayatKelas, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	var out []models.RiwayatKelas
+	for _, rk := range rows(r.s.riwayat, func(rk models.RiwayatKelas) bool { return rk.IDSiswa == idSiswa }) {
+		k, ok := r.s.kelas[rk.IDKelas]
+		if !ok {
+			continue
+		}
+		rk.NamaKelas, rk.IDTahunAjaran, rk.TahunAjaran = k.NamaKelas, k.IDTahunAjaran, r.s.tahunAjaran[k.IDTahunAjaran].Nama
+		out = append(out, rk)
+	}
+	slices.SortStableFunc(out, func(a, b models.RiwayatKelas) int { return cmp.Compare(a.TanggalMasuk, b.TanggalMasuk) })
+	return out, nil
+}
+
+func (r *riwayatKelasFake) Masuk(ctx context.Context, idSiswa, idKelas int, tanggal string) (int, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	for _, rk := range r.s.riwayat {
+		if rk.IDSiswa == idSiswa && rk.TanggalKeluar == nil {
+			return 0, conflict("riwayat_kelas_aktif_key")
+		}
+	}
+	id := r.s.nextID()
+	r.s.riwayat[id] = models.RiwayatKelas{IDRiwayat: id, IDSiswa: idSiswa, IDKelas: idKelas, TanggalMasuk: tanggal, Status: models.RiwayatAktif}
+	return id, nil
+}
+
+func (r *riwayatKelasFake) Keluar(ctx context.Context, idSiswa int, tanggal, status string) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	for id, rk := range r.s.riwayat {
+		if rk.IDSiswa == idSiswa && rk.TanggalKeluar == nil {
+			keluar := max(tanggal, rk.TanggalMasuk)
+			rk.TanggalKeluar, rk.Status = &keluar, status
+			r.s.riwayat[id] = rk
+		}
+	}
+	return nil
+}
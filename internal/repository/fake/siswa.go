@@ -0,0 +1,335 @@
+package fake
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	"myapp/internal/models"
+	"myapp/internal/repository"
+)
+
+type siswaFake struct {
+	s *Store
+}
+
+// byNama - Urutan nama siswa lalu id_siswa
+func byNama(a, b models.Siswa) int {
+	return cmp.Or(cmp.Compare(a.NamaSiswa, b.NamaSiswa), cmp.Compare(a.IDSiswa, b.IDSiswa))
+}
+
+// siswaAktif - Siswa aktif yang kelasnya saat ini idKelas
+func (s *Store) siswaAktif(idKelas int) []models.Siswa {
+	return rows(s.siswa, func(sw models.Siswa) bool {
+		return sw.IDKelas != nil && *sw.IDKelas == idKelas && sw.Status == models.SiswaAktif
+	})
+}
+
+// siswaRows - Siswa yang lolos keep, urut nama
+func (s *Store) siswaRows(keep func(models.Siswa) bool) []models.Siswa {
+	out := rows(s.siswa, keep)
+	slices.SortStableFunc(out, byNama)
+	return out
+}
+
+func statusIs(status *string, sw models.Siswa) bool {
+	return status == nil || sw.Status == *status
+}
+
+func inKelas(sw models.Siswa, idKelas int) bool {
+	return sw.IDKelas != nil && *sw.IDKelas == idKelas
+}
+
+func (r *siswaFake) List(ctx context.Context, status *string) ([]models.Siswa, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return rows(r.s.siswa, func(sw models.Siswa) bool { return statusIs(status, sw) }), nil
+}
+
+func (r *siswaFake) ListByKelas(ctx context.Context, idKelas int, status *string) ([]models.Siswa, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return r.s.siswaRows(func(sw models.Siswa) bool { return inKelas(sw, idKelas) && statusIs(status, sw) }), nil
+}
+
+func (r *siswaFake) ListByMapel(ctx context.Context, idMapel int, status *string) ([]models.Siswa, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	mp, ok := r.s.mapel[idMapel]
+	if !ok {
+		return nil, nil
+	}
+	return r.s.siswaRows(func(sw models.Siswa) bool { return inKelas(sw, mp.IDKelas) && statusIs(status, sw) }), nil
+}
+
+func (r *siswaFake) ListAnggotaKelas(ctx context.Context, idKelas, idSemester int) ([]models.Siswa, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	sm, ok := r.s.semester[idSemester]
+	if !ok {
+		return nil, nil
+	}
+	anggota := map[int]bool{}
+	for _, rk := range r.s.riwayat {
+		if rk.IDKelas == idKelas && rk.TanggalMasuk <= sm.TanggalSelesai &&
+			(rk.TanggalKeluar == nil || *rk.TanggalKeluar >= sm.TanggalMulai) {
+			anggota[rk.IDSiswa] = true
+		}
+	}
+	for _, n := range r.s.nilai {
+		if n.IDSemester == idSemester && r.s.mapel[n.IDMapel].IDKelas == idKelas {
+			anggota[n.IDSiswa] = true
+		}
+	}
+	return r.s.siswaRows(func(sw models.Siswa) bool { return anggota[sw.IDSiswa] }), nil
+}
+
+func (r *siswaFake) ListAnggotaKelasTanggal(ctx context.Context, idKelas int, tanggal string) ([]models.Siswa, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	anggota := map[int]bool{}
+	for _, rk := range r.s.riwayat {
+		if rk.IDKelas == idKelas && rk.TanggalMasuk <= tanggal && (rk.TanggalKeluar == nil || *rk.TanggalKeluar > tanggal) {
+			anggota[rk.IDSiswa] = true
+		}
+	}
+	return r.s.siswaRows(func(sw models.Siswa) bool { return anggota[sw.IDSiswa] }), nil
+}
+
+func (r *siswaFake) ListAlumni(ctx context.Context, tahunLulus *int) ([]models.Alumni, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	var out []models.Alumni
+	for _, sw := range r.s.siswaRows(func(sw models.Siswa) bool { return sw.Status == models.SiswaLulus }) {
+		a := models.Alumni{Siswa: sw}
+		if sw.TanggalStatus != nil && len(*sw.TanggalStatus) >= 4 {
+			a.TahunLulus, _ = strconv.Atoi((*sw.TanggalStatus)[:4])
+		}
+		if tahunLulus != nil && a.TahunLulus != *tahunLulus {
+			continue
+		}
+		if sw.IDKelas != nil {
+			k := r.s.kelas[*sw.IDKelas]
+			a.NamaKelas, a.TahunAjaran = k.NamaKelas, r.s.tahunAjaran[k.IDTahunAjaran].Nama
+		}
+		out = append(out, a)
+	}
+	// ORDER BY tanggal_status DESC, nama_siswa
+	slices.SortStableFunc(out, func(a, b models.Alumni) int {
+		return cmp.Compare(*b.TanggalStatus, *a.TanggalStatus)
+	})
+	return out, nil
+}
+
+func (r *siswaFake) GetByID(ctx context.Context, idSiswa int) (models.Siswa, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return models.Siswa{}, err
+	}
+	sw, ok := r.s.siswa[idSiswa]
+	if !ok {
+		return models.Siswa{}, repository.ErrNotFound
+	}
+	return sw, nil
+}
+
+func (r *siswaFake) GetByUserID(ctx context.Context, idUser int) (models.Siswa, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return models.Siswa{}, err
+	}
+	for _, sw := range rows(r.s.siswa, nil) {
+		if sw.IDUser == idUser {
+			return sw, nil
+		}
+	}
+	return models.Siswa{}, repository.ErrNotFound
+}
+
+// nisnTaken - Sama dengan index unik siswa_nisn_key: dibandingkan tanpa nol di depan, NISN kosong boleh sama
+func (s *Store) nisnTaken(nisn string, except int) bool {
+	if nisn == "" {
+		return false
+	}
+	for id, sw := range s.siswa {
+		if id != except && sw.NISN != "" && strings.TrimLeft(sw.NISN, "0") == strings.TrimLeft(nisn, "0") {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *siswaFake) Create(ctx context.Context, sw models.Siswa) (int, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	if r.s.nisnTaken(sw.NISN, 0) {
+		return 0, conflict("siswa_nisn_key")
+	}
+	var idKelas *int
+	if sw.IDKelas != nil && *sw.IDKelas != 0 {
+		id := *sw.IDKelas
+		idKelas = &id
+	}
+	sw.IDSiswa, sw.IDKelas, sw.Foto = r.s.nextID(), idKelas, ""
+	sw.Status, sw.TanggalStatus, sw.Keterangan = models.SiswaAktif, nil, ""
+	r.s.siswa[sw.IDSiswa] = sw
+	return sw.IDSiswa, nil
+}
+
+func (r *siswaFake) Update(ctx context.Context, idSiswa int, sw models.Siswa) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	old, ok := r.s.siswa[idSiswa]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if r.s.nisnTaken(sw.NISN, idSiswa) {
+		return conflict("siswa_nisn_key")
+	}
+	sw.IDSiswa, sw.Status, sw.TanggalStatus, sw.Keterangan = idSiswa, old.Status, old.TanggalStatus, old.Keterangan
+	r.s.siswa[idSiswa] = sw
+	return nil
+}
+
+func (r *siswaFake) UpdateKelas(ctx context.Context, idSiswa, idKelas int) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	sw, ok := r.s.siswa[idSiswa]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	sw.IDKelas = &idKelas
+	r.s.siswa[idSiswa] = sw
+	return nil
+}
+
+func (r *siswaFake) UpdateStatus(ctx context.Context, idSiswa int, st models.StatusSiswa) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	sw, ok := r.s.siswa[idSiswa]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	sw.Status, sw.Keterangan, sw.TanggalStatus = st.Status, st.Keterangan, nil
+	if st.Status != models.SiswaAktif {
+		tanggal := st.Tanggal
+		sw.TanggalStatus = &tanggal
+	}
+	r.s.siswa[idSiswa] = sw
+	return nil
+}
+
+func (r *siswaFake) UpdateFoto(ctx context.Context, idSiswa int, url string) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	sw, ok := r.s.siswa[idSiswa]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	sw.Foto = url
+	r.s.siswa[idSiswa] = sw
+	return nil
+}
+
+func (r *siswaFake) Delete(ctx context.Context, idSiswa int) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, ok := r.s.siswa[idSiswa]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.s.siswa, idSiswa)
+	return nil
+}
+
+func (r *siswaFake) HasHistory(ctx context.Context, idSiswa int) (bool, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return false, err
+	}
+	for _, n := range r.s.nilai {
+		if n.IDSiswa == idSiswa {
+			return true, nil
+		}
+	}
+	for _, a := range r.s.absensi {
+		if a.IDSiswa == idSiswa {
+			return true, nil
+		}
+	}
+	for _, rk := range r.s.riwayat {
+		if rk.IDSiswa == idSiswa && rk.TanggalKeluar != nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *siswaFake) ExistingNISN(ctx context.Context, nisn []string) ([]string, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	cari := map[string]bool{}
+	for _, n := range nisn {
+		cari[strings.TrimLeft(n, "0")] = true
+	}
+	var out []string
+	for _, sw := range rows(r.s.siswa, nil) {
+		if sw.NISN != "" && cari[strings.TrimLeft(sw.NISN, "0")] {
+			out = append(out, sw.NISN)
+		}
+	}
+	return out, nil
+}
+
+func (r *siswaFake) IsOwnedBy(ctx context.Context, idSiswa, idUser int) (bool, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return false, err
+	}
+	sw, ok := r.s.siswa[idSiswa]
+	return ok && sw.IDUser == idUser, nil
+}
@@ -0,0 +1,136 @@
+package fake
+
+import (
+	"context"
+
+	"myapp/internal/models"
+	"myapp/internal/repository"
+)
+
+type userFake struct {
+	s *Store
+}
+
+// withoutPassword - Hanya GetByUsername yang mengembalikan hash password
+func withoutPassword(u models.User) models.User {
+	u.Password = ""
+	return u
+}
+
+func (r *userFake) List(ctx context.Context) ([]models.User, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	var out []models.User
+	for _, u := range rows(r.s.users, nil) {
+		out = append(out, withoutPassword(u))
+	}
+	return out, nil
+}
+
+func (r *userFake) GetByID(ctx context.Context, idUser int) (models.User, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return models.User{}, err
+	}
+	u, ok := r.s.users[idUser]
+	if !ok {
+		return models.User{}, repository.ErrNotFound
+	}
+	return withoutPassword(u), nil
+}
+
+func (r *userFake) GetByUsername(ctx context.Context, username string) (models.User, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return models.User{}, err
+	}
+	for _, u := range r.s.users {
+		if u.Username == username {
+			return u, nil
+		}
+	}
+	return models.User{}, repository.ErrNotFound
+}
+
+func (r *userFake) usernameTaken(username string, except int) bool {
+	for id, u := range r.s.users {
+		if id != except && u.Username == username {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *userFake) Create(ctx context.Context, u models.User) (int, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	if r.usernameTaken(u.Username, 0) {
+		return 0, conflict("user_username_key")
+	}
+	u.IDUser = r.s.nextID()
+	r.s.users[u.IDUser] = u
+	return u.IDUser, nil
+}
+
+func (r *userFake) Update(ctx context.Context, idUser int, u models.User) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	old, ok := r.s.users[idUser]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if r.usernameTaken(u.Username, idUser) {
+		return conflict("user_username_key")
+	}
+	u.IDUser, u.Password = idUser, old.Password
+	r.s.users[idUser] = u
+	return nil
+}
+
+func (r *userFake) UpdatePassword(ctx context.Context, idUser int, passwordHash string) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	u, ok := r.s.users[idUser]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	u.Password = passwordHash
+	r.s.users[idUser] = u
+	return nil
+}
+
+func (r *userFake) UsernameExists(ctx context.Context, username string) (bool, error) {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return false, err
+	}
+	return r.usernameTaken(username, 0), nil
+}
+
+func (r *userFake) Delete(ctx context.Context, idUser int) error {
+	err := r.s.lock()
+	defer r.s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, ok := r.s.users[idUser]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.s.users, idUser)
+	return nil
+}
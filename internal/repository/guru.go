@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+
+	"myapp/internal/models"
+)
+
+// GuruRepository - Akses data tabel guru
+type GuruRepository interface {
+	List(ctx context.Context) ([]models.Guru, error)
+	GetByID(ctx context.Context, idGuru int) (models.Guru, error)
+	GetByUserID(ctx context.Context, idUser int) (models.Guru, error)
+	Create(ctx context.Context, guru models.Guru) (int, error)
+	Update(ctx context.Context, idGuru int, guru models.Guru) error
+	UpdateFoto(ctx context.Context, idGuru int, url string) error
+	Delete(ctx context.Context, idGuru int) error
+}
+
+const guruColumns = `id_guru, id_user, id_mapel, nama_guru, mata_pelajaran, nip, alamat, email, no_telp, COALESCE(foto, '')`
+
+type guruPostgres struct {
+	q DBTX
+}
+
+func scanGuru(row rowScanner) (models.Guru, error) {
+	var g models.Guru
+	err := row.Scan(&g.IDGuru, &g.IDUser, &g.IDMapel, &g.NamaGuru, &g.MataPelajaran, &g.NIP, &g.Alamat, &g.Email, &g.NoTelp, &g.Foto)
+	return g, err
+}
+
+func (r *guruPostgres) List(ctx context.Context) ([]models.Guru, error) {
+	rows, err := r.q.QueryContext(ctx, `SELECT `+guruColumns+` FROM guru ORDER BY id_guru`)
+	return collect(rows, err, scanGuru)
+}
+
+func (r *guruPostgres) GetByID(ctx context.Context, idGuru int) (models.Guru, error) {
+	g, err := scanGuru(r.q.QueryRowContext(ctx, `SELECT `+guruColumns+` FROM guru WHERE id_guru = $1`, idGuru))
+	return g, notFound(err)
+}
+
+func (r *guruPostgres) GetByUserID(ctx context.Context, idUser int) (models.Guru, error) {
+	g, err := scanGuru(r.q.QueryRowContext(ctx, `SELECT `+guruColumns+` FROM guru WHERE id_user = $1`, idUser))
+	return g, notFound(err)
+}
+
+func (r *guruPostgres) Create(ctx context.Context, g models.Guru) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+		INSERT INTO guru (id_user, id_mapel, nama_guru, mata_pelajaran, nip, alamat, email, no_telp)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id_guru
+	`, g.IDUser, g.IDMapel, g.NamaGuru, g.MataPelajaran, g.NIP, g.Alamat, g.Email, g.NoTelp).Scan(&id)
+	return id, err
+}
+
+func (r *guruPostgres) Update(ctx context.Context, idGuru int, g models.Guru) error {
+	return expectAffected(r.q.ExecContext(ctx, `
+		UPDATE guru
+		SET id_user=$1, id_mapel=$2, nama_guru=$3, mata_pelajaran=$4, nip=$5, alamat=$6, email=$7, no_telp=$8, foto=$9
+		WHERE id_guru=$10
+	`, g.IDUser, g.IDMapel, g.NamaGuru, g.MataPelajaran, g.NIP, g.Alamat, g.Email, g.NoTelp, g.Foto, idGuru))
+}
+
+func (r *guruPostgres) UpdateFoto(ctx context.Context, idGuru int, url string) error {
+	return expectAffected(r.q.ExecContext(ctx, `UPDATE guru SET foto = $1 WHERE id_guru = $2`, url, idGuru))
+}
+
+func (r *guruPostgres) Delete(ctx context.Context, idGuru int) error {
+	return expectAffected(r.q.ExecContext(ctx, `DELETE FROM guru WHERE id_guru = $1`, idGuru))
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+
+	"myapp/internal/models"
+)
+
+// KelasRepository - Akses data tabel kelas
+type KelasRepository interface {
+	// List - Semua kelas, atau kelas satu tahun ajaran jika idTahunAjaran tidak nil
+	List(ctx context.Context, idTahunAjaran *int) ([]models.Kelas, error)
+	ListByGuru(ctx context.Context, idGuru int, idTahunAjaran *int) ([]models.Kelas, error)
+	GetByID(ctx context.Context, idKelas int) (models.Kelas, error)
+	// IsTaughtBy - true jika kelas diampu (wali kelas) guru dengan id_user tersebut
+	IsTaughtBy(ctx context.Context, idKelas, idUser int) (bool, error)
+	// FindForSiswa - Kelas siswa pada tahun ajaran tertentu: kelas saat ini jika tahunnya sama,
+	// selain itu kelas terakhir di riwayat kelas, atau kelas yang mapel-nya pernah memberi nilai
+	// ke siswa tersebut
+	FindForSiswa(ctx context.Context, idSiswa, idTahunAjaran int) (models.Kelas, error)
+	Create(ctx context.Context, kelas models.Kelas) (int, error)
+	Update(ctx context.Context, idKelas int, kelas models.Kelas) error
+	Delete(ctx context.Context, idKelas int) error
+}
+
+// kelasColumns - jumlah_siswa (siswa aktif) dihitung langsung dari tabel siswa
+const kelasColumns = `
+	k.id_kelas, k.id_guru, k.nama_kelas, k.id_tahun_ajaran,
+	(SELECT t.nama FROM tahun_ajaran t WHERE t.id_tahun_ajaran = k.id_tahun_ajaran) AS tahun_ajaran,
+	(SELECT COUNT(*) FROM siswa s WHERE s.id_kelas = k.id_kelas AND s.status = 'aktif') AS jumlah_siswa`
+
+type kelasPostgres struct {
+	q DBTX
+}
+
+func scanKelas(row rowScanner) (models.Kelas, error) {
+	var k models.Kelas
+	err := row.Scan(&k.IDKelas, &k.IDGuru, &k.NamaKelas, &k.IDTahunAjaran, &k.TahunAjaran, &k.JumlahSiswa)
+	return k, err
+}
+
+func (r *kelasPostgres) List(ctx context.Context, idTahunAjaran *int) ([]models.Kelas, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+kelasColumns+`
+		FROM kelas k
+		WHERE $1::int IS NULL OR k.id_tahun_ajaran = $1
+		ORDER BY k.id_kelas
+	`, idTahunAjaran)
+	return collect(rows, err, scanKelas)
+}
+
+func (r *kelasPostgres) ListByGuru(ctx context.Context, idGuru int, idTahunAjaran *int) ([]models.Kelas, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+kelasColumns+`
+		FROM kelas k
+		WHERE k.id_guru = $1 AND ($2::int IS NULL OR k.id_tahun_ajaran = $2)
+		ORDER BY k.id_kelas
+	`, idGuru, idTahunAjaran)
+	return collect(rows, err, scanKelas)
+}
+
+func (r *kelasPostgres) GetByID(ctx context.Context, idKelas int) (models.Kelas, error) {
+	k, err := scanKelas(r.q.QueryRowContext(ctx, `SELECT `+kelasColumns+` FROM kelas k WHERE k.id_kelas = $1`, idKelas))
+	return k, notFound(err)
+}
+
+func (r *kelasPostgres) Create(ctx context.Context, k models.Kelas) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx,
+		`INSERT INTO kelas (id_guru, nama_kelas, id_tahun_ajaran) VALUES ($1, $2, $3) RETURNING id_kelas`,
+		k.IDGuru, k.NamaKelas, k.IDTahunAjaran,
+	).Scan(&id)
+	return id, err
+}
+
+func (r *kelasPostgres) Update(ctx context.Context, idKelas int, k models.Kelas) error {
+	return expectAffected(r.q.ExecContext(ctx,
+		`UPDATE kelas SET id_guru=$1, nama_kelas=$2, id_tahun_ajaran=$3 WHERE id_kelas=$4`,
+		k.IDGuru, k.NamaKelas, k.IDTahunAjaran, idKelas,
+	))
+}
+
+func (r *kelasPostgres) Delete(ctx context.Context, idKelas int) error {
+	return expectAffected(r.q.ExecContext(ctx, `DELETE FROM kelas WHERE id_kelas = $1`, idKelas))
+}
+
+func (r *kelasPostgres) FindForSiswa(ctx context.Context, idSiswa, idTahunAjaran int) (models.Kelas, error) {
+	k, err := scanKelas(r.q.QueryRowContext(ctx, `
+		SELECT `+kelasColumns+`
+		FROM kelas k
+		LEFT JOIN siswa s ON s.id_siswa = $1 AND s.id_kelas = k.id_kelas
+		WHERE k.id_tahun_ajaran = $2
+		  AND (s.id_siswa IS NOT NULL OR EXISTS (
+			SELECT 1 FROM riwayat_kelas rk WHERE rk.id_siswa = $1 AND rk.id_kelas = k.id_kelas
+		  ) OR EXISTS (
+			SELECT 1 FROM nilai n
+			JOIN mata_pelajaran m ON m.id_mapel = n.id_mapel
+			WHERE n.id_siswa = $1 AND m.id_kelas = k.id_kelas
+		  ))
+		ORDER BY s.id_siswa IS NOT NULL DESC,
+			(SELECT MAX(rk.tanggal_masuk) FROM riwayat_kelas rk WHERE rk.id_siswa = $1 AND rk.id_kelas = k.id_kelas) DESC NULLS LAST,
+			k.id_kelas DESC
+		LIMIT 1
+	`, idSiswa, idTahunAjaran))
+	return k, notFound(err)
+}
+
+func (r *kelasPostgres) IsTaughtBy(ctx context.Context, idKelas, idUser int) (bool, error) {
+	var teaches bool
+	err := r.q.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM kelas k
+			JOIN guru g ON k.id_guru = g.id_guru
+			WHERE k.id_kelas = $1 AND g.id_user = $2
+		)
+	`, idKelas, idUser).Scan(&teaches)
+	return teaches, err
+}
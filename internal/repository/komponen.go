@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+
+	"myapp/internal/models"
+)
+
+// KomponenRepository - Akses data tabel komponen_penilaian
+type KomponenRepository interface {
+	ListByMapel(ctx context.Context, idMapel int) ([]models.KomponenPenilaian, error)
+	// ListNilaiSiswa - Semua komponen mapel beserta nilai siswa pada satu semester, komponen yang belum
+	// dinilai ikut tampil
+	ListNilaiSiswa(ctx context.Context, idMapel, idSiswa, idSemester int) ([]models.KomponenNilai, error)
+	GetByID(ctx context.Context, idKomponen int) (models.KomponenPenilaian, error)
+	Create(ctx context.Context, k models.KomponenPenilaian, bobot float64) (int, error)
+	Update(ctx context.Context, idKomponen int, k models.KomponenPenilaian, bobot float64) error
+	Delete(ctx context.Context, idKomponen int) error
+	// SumBobot - Jumlah bobot komponen satu mapel, tanpa menghitung komponen exceptKomponen
+	SumBobot(ctx context.Context, idMapel, exceptKomponen int) (float64, error)
+	// MaxNilai - Nilai tertinggi yang sudah diberikan untuk komponen, termasuk remedial (0 jika belum ada)
+	MaxNilai(ctx context.Context, idKomponen int) (int, error)
+}
+
+const komponenColumns = `k.id_komponen, k.id_mapel, k.nama_komponen, k.bobot, k.nilai_maks, TO_CHAR(k.tenggat, 'YYYY-MM-DD')`
+
+type komponenPostgres struct {
+	q DBTX
+}
+
+func scanKomponen(row rowScanner, extra ...any) (models.KomponenPenilaian, error) {
+	var k models.KomponenPenilaian
+	var bobot float64
+	err := row.Scan(append([]any{&k.IDKomponen, &k.IDMapel, &k.NamaKomponen, &bobot, &k.NilaiMaks, &k.Tenggat}, extra...)...)
+	k.Bobot = models.FormatBobot(bobot)
+	return k, err
+}
+
+func (r *komponenPostgres) ListByMapel(ctx context.Context, idMapel int) ([]models.KomponenPenilaian, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+komponenColumns+`
+		FROM komponen_penilaian k
+		WHERE k.id_mapel = $1
+		ORDER BY k.tenggat NULLS LAST, k.id_komponen
+	`, idMapel)
+	return collect(rows, err, func(row rowScanner) (models.KomponenPenilaian, error) {
+		return scanKomponen(row)
+	})
+}
+
+func (r *komponenPostgres) ListNilaiSiswa(ctx context.Context, idMapel, idSiswa, idSemester int) ([]models.KomponenNilai, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+komponenColumns+`, p.id_penilaian, p.nilai
+		FROM komponen_penilaian k
+		LEFT JOIN nilai n ON n.id_mapel = k.id_mapel AND n.id_siswa = $2 AND n.id_semester = $3
+		LEFT JOIN penilaian p ON p.id_nilai = n.id_nilai AND p.id_komponen = k.id_komponen
+		WHERE k.id_mapel = $1
+		ORDER BY k.tenggat NULLS LAST, k.id_komponen
+	`, idMapel, idSiswa, idSemester)
+	return collect(rows, err, func(row rowScanner) (models.KomponenNilai, error) {
+		var kn models.KomponenNilai
+		var err error
+		kn.KomponenPenilaian, err = scanKomponen(row, &kn.IDPenilaian, &kn.Nilai)
+		return kn, err
+	})
+}
+
+func (r *komponenPostgres) GetByID(ctx context.Context, idKomponen int) (models.KomponenPenilaian, error) {
+	k, err := scanKomponen(r.q.QueryRowContext(ctx,
+		`SELECT `+komponenColumns+` FROM komponen_penilaian k WHERE k.id_komponen = $1`, idKomponen))
+	return k, notFound(err)
+}
+
+func (r *komponenPostgres) Create(ctx context.Context, k models.KomponenPenilaian, bobot float64) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+		INSERT INTO komponen_penilaian (id_mapel, nama_komponen, bobot, nilai_maks, tenggat)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id_komponen
+	`, k.IDMapel, k.NamaKomponen, bobot, k.NilaiMaks, k.Tenggat).Scan(&id)
+	return id, conflict(err)
+}
+
+func (r *komponenPostgres) Update(ctx context.Context, idKomponen int, k models.KomponenPenilaian, bobot float64) error {
+	return conflict(expectAffected(r.q.ExecContext(ctx, `
+		UPDATE komponen_penilaian
+		SET nama_komponen=$1, bobot=$2, nilai_maks=$3, tenggat=$4
+		WHERE id_komponen=$5
+	`, k.NamaKomponen, bobot, k.NilaiMaks, k.Tenggat, idKomponen)))
+}
+
+func (r *komponenPostgres) Delete(ctx context.Context, idKomponen int) error {
+	return expectAffected(r.q.ExecContext(ctx, `DELETE FROM komponen_penilaian WHERE id_komponen = $1`, idKomponen))
+}
+
+func (r *komponenPostgres) SumBobot(ctx context.Context, idMapel, exceptKomponen int) (float64, error) {
+	var sum float64
+	err := r.q.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(bobot), 0)
+		FROM komponen_penilaian
+		WHERE id_mapel = $1 AND id_komponen <> $2
+	`, idMapel, exceptKomponen).Scan(&sum)
+	return sum, err
+}
+
+func (r *komponenPostgres) MaxNilai(ctx context.Context, idKomponen int) (int, error) {
+	var maxNilai int
+	err := r.q.QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(GREATEST(p.nilai, rm.nilai)), 0)
+		FROM penilaian p
+		LEFT JOIN remedial rm ON rm.id_penilaian = p.id_penilaian
+		WHERE p.id_komponen = $1
+	`, idKomponen).Scan(&maxNilai)
+	return maxNilai, err
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+
+	"myapp/internal/models"
+)
+
+// MataPelajaranRepository - Akses data tabel mata_pelajaran
+type MataPelajaranRepository interface {
+	// List - Semua mapel, atau mapel kelas-kelas satu tahun ajaran jika idTahunAjaran tidak nil
+	List(ctx context.Context, idTahunAjaran *int) ([]models.MataPelajaran, error)
+	ListByKelas(ctx context.Context, idKelas int) ([]models.MataPelajaran, error)
+	ListBySiswa(ctx context.Context, idSiswa int) ([]models.MataPelajaran, error)
+	GetByID(ctx context.Context, idMapel int) (models.MataPelajaran, error)
+	GetDetail(ctx context.Context, idMapel int) (models.MataPelajaranDetail, error)
+	Create(ctx context.Context, mapel models.MataPelajaran) (int, error)
+	Update(ctx context.Context, idMapel int, mapel models.MataPelajaran) error
+	Delete(ctx context.Context, idMapel int) error
+	// IsTaughtBy - true jika mapel ada di kelas yang diajar guru dengan id_user tersebut
+	IsTaughtBy(ctx context.Context, idMapel, idUser int) (bool, error)
+	// Lock - Mengunci baris mapel sampai transaksi selesai, dipakai saat mengubah komponen penilaiannya
+	Lock(ctx context.Context, idMapel int) error
+}
+
+const mataPelajaranColumns = `mp.id_mapel, mp.id_kelas, mp.nama_mata_pelajaran, mp.kkm, mp.skala_predikat, mp.aturan_remedial`
+
+type mataPelajaranPostgres struct {
+	q DBTX
+}
+
+func scanMataPelajaran(row rowScanner) (models.MataPelajaran, error) {
+	var mp models.MataPelajaran
+	err := row.Scan(&mp.IDMapel, &mp.IDKelas, &mp.NamaMataPelajaran, &mp.KKM, &mp.SkalaPredikat, &mp.AturanRemedial)
+	return mp, err
+}
+
+func (r *mataPelajaranPostgres) List(ctx context.Context, idTahunAjaran *int) ([]models.MataPelajaran, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+mataPelajaranColumns+`
+		FROM mata_pelajaran mp
+		JOIN kelas k ON k.id_kelas = mp.id_kelas
+		WHERE $1::int IS NULL OR k.id_tahun_ajaran = $1
+		ORDER BY mp.id_mapel
+	`, idTahunAjaran)
+	return collect(rows, err, scanMataPelajaran)
+}
+
+func (r *mataPelajaranPostgres) ListByKelas(ctx context.Context, idKelas int) ([]models.MataPelajaran, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+mataPelajaranColumns+`
+		FROM mata_pelajaran mp
+		WHERE mp.id_kelas = $1
+		ORDER BY mp.id_mapel
+	`, idKelas)
+	return collect(rows, err, scanMataPelajaran)
+}
+
+func (r *mataPelajaranPostgres) ListBySiswa(ctx context.Context, idSiswa int) ([]models.MataPelajaran, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+mataPelajaranColumns+`
+		FROM siswa s
+		JOIN mata_pelajaran mp ON s.id_kelas = mp.id_kelas
+		WHERE s.id_siswa = $1
+		ORDER BY mp.id_mapel
+	`, idSiswa)
+	return collect(rows, err, scanMataPelajaran)
+}
+
+func (r *mataPelajaranPostgres) GetByID(ctx context.Context, idMapel int) (models.MataPelajaran, error) {
+	mp, err := scanMataPelajaran(r.q.QueryRowContext(ctx,
+		`SELECT `+mataPelajaranColumns+` FROM mata_pelajaran mp WHERE mp.id_mapel = $1`, idMapel))
+	return mp, notFound(err)
+}
+
+func (r *mataPelajaranPostgres) GetDetail(ctx context.Context, idMapel int) (models.MataPelajaranDetail, error) {
+	var d models.MataPelajaranDetail
+	err := r.q.QueryRowContext(ctx, `
+		SELECT mp.id_mapel, k.id_kelas, mp.nama_mata_pelajaran, g.nama_guru, t.nama,
+			(SELECT COUNT(*) FROM siswa s WHERE s.id_kelas = k.id_kelas AND s.status = 'aktif')
+		FROM mata_pelajaran mp
+		JOIN kelas k ON mp.id_kelas = k.id_kelas
+		JOIN guru g ON k.id_guru = g.id_guru
+		JOIN tahun_ajaran t ON t.id_tahun_ajaran = k.id_tahun_ajaran
+		WHERE mp.id_mapel = $1
+	`, idMapel).Scan(&d.IDMapel, &d.IDKelas, &d.NamaMataPelajaran, &d.NamaGuru, &d.TahunAjaran, &d.JumlahSiswa)
+	return d, notFound(err)
+}
+
+func (r *mataPelajaranPostgres) Create(ctx context.Context, mp models.MataPelajaran) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx,
+		`INSERT INTO mata_pelajaran (id_kelas, nama_mata_pelajaran, kkm, skala_predikat, aturan_remedial)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id_mapel`,
+		mp.IDKelas, mp.NamaMataPelajaran, mp.KKM, mp.SkalaPredikat, mp.AturanRemedial,
+	).Scan(&id)
+	return id, err
+}
+
+func (r *mataPelajaranPostgres) Update(ctx context.Context, idMapel int, mp models.MataPelajaran) error {
+	return expectAffected(r.q.ExecContext(ctx,
+		`UPDATE mata_pelajaran SET id_kelas=$1, nama_mata_pelajaran=$2, kkm=$3, skala_predikat=$4, aturan_remedial=$5 WHERE id_mapel=$6`,
+		mp.IDKelas, mp.NamaMataPelajaran, mp.KKM, mp.SkalaPredikat, mp.AturanRemedial, idMapel,
+	))
+}
+
+func (r *mataPelajaranPostgres) Delete(ctx context.Context, idMapel int) error {
+	return expectAffected(r.q.ExecContext(ctx, `DELETE FROM mata_pelajaran WHERE id_mapel = $1`, idMapel))
+}
+
+func (r *mataPelajaranPostgres) IsTaughtBy(ctx context.Context, idMapel, idUser int) (bool, error) {
+	var teaches bool
+	err := r.q.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM mata_pelajaran mp
+			JOIN kelas k ON mp.id_kelas = k.id_kelas
+			JOIN guru g ON k.id_guru = g.id_guru
+			WHERE mp.id_mapel = $1 AND g.id_user = $2
+		)
+	`, idMapel, idUser).Scan(&teaches)
+	return teaches, err
+}
+
+func (r *mataPelajaranPostgres) Lock(ctx context.Context, idMapel int) error {
+	var id int
+	err := r.q.QueryRowContext(ctx, `SELECT id_mapel FROM mata_pelajaran WHERE id_mapel = $1 FOR UPDATE`, idMapel).Scan(&id)
+	return notFound(err)
+}
@@ -0,0 +1,346 @@
+package repository
+
+import (
+	"context"
+
+	"myapp/internal/models"
+)
+
+// NilaiRepository - Akses data tabel nilai dan penilaian (komponen nilai)
+type NilaiRepository interface {
+	GetBySiswaAndMapel(ctx context.Context, idSiswa, idMapel, idSemester int) (models.Nilai, error)
+	// FindOrCreate - id_nilai untuk siswa dan mapel pada satu semester, dibuat dengan total 0 jika
+	// belum ada. Baris nilai ikut terkunci sampai transaksi selesai.
+	FindOrCreate(ctx context.Context, idSiswa, idMapel, idSemester int) (int, error)
+	ListBySiswa(ctx context.Context, idSiswa, idSemester int) ([]models.NilaiMapel, error)
+	// ListByKelas - Semua baris nilai untuk mapel-mapel satu kelas, bahan leger nilai
+	ListByKelas(ctx context.Context, idKelas, idSemester int) ([]models.Nilai, error)
+	// ListBelumTuntas - Siswa kelas mapel yang total_nilai-nya di bawah KKM mapel, urut nama
+	ListBelumTuntas(ctx context.Context, idMapel, idSemester int) ([]models.SiswaRemedial, error)
+	// ListPeringkat - Peringkat siswa yang sudah punya nilai di mapel-mapel kelas (atau satu mapel jika
+	// idMapel tidak nil), urut dari peringkat teratas
+	ListPeringkat(ctx context.Context, idKelas int, idMapel *int, idSemester int) ([]models.PeringkatSiswa, error)
+	// ListRapor - Semua mapel satu kelas beserta total_nilai siswa (null jika belum dinilai) dan guru pengajarnya
+	ListRapor(ctx context.Context, idSiswa, idKelas, idSemester int) ([]models.RaporMapel, error)
+	// Lock - Mengunci baris nilai sampai transaksi selesai. Dipanggil sebelum mengubah
+	// penilaian-nya supaya perubahan bersamaan tidak menghasilkan total_nilai yang basi.
+	Lock(ctx context.Context, idNilai int) error
+	// RecomputeTotal - total_nilai = jumlah nilai × bobot semua penilaian di bawah id_nilai
+	RecomputeTotal(ctx context.Context, idNilai int) error
+	// RecomputeAll - Menghitung ulang semua total_nilai, mengembalikan jumlah baris yang berubah
+	RecomputeAll(ctx context.Context) (int64, error)
+	// RecomputeByMapel - Menghitung ulang total_nilai semua siswa satu mapel
+	RecomputeByMapel(ctx context.Context, idMapel int) error
+
+	// ListPenilaian - Semua penilaian, atau penilaian satu semester jika idSemester tidak nil
+	ListPenilaian(ctx context.Context, idSemester *int) ([]models.Penilaian, error)
+	ListPenilaianByNilai(ctx context.Context, idNilai int) ([]models.Penilaian, error)
+	// ListPenilaianByMapel - Semua penilaian seluruh siswa untuk satu mapel pada satu semester
+	ListPenilaianByMapel(ctx context.Context, idMapel, idSemester int) ([]models.Penilaian, error)
+	GetPenilaian(ctx context.Context, idPenilaian int) (models.Penilaian, error)
+	// SumBobot - Jumlah bobot penilaian di bawah id_nilai, tanpa menghitung penilaian exceptPenilaian
+	SumBobot(ctx context.Context, idNilai, exceptPenilaian int) (float64, error)
+	// MapelOfPenilaian - id_mapel dari nilai induk sebuah penilaian
+	MapelOfPenilaian(ctx context.Context, idPenilaian int) (int, error)
+	// CreatePenilaian - idKomponen nil untuk penilaian lepas yang tidak terikat komponen mapel
+	CreatePenilaian(ctx context.Context, idNilai int, namaNilai string, nilai int, bobot float64, idKomponen *int) (int, error)
+	// FindPenilaianKomponen - id_penilaian milik nilai untuk komponen tertentu, ErrNotFound jika belum dinilai
+	FindPenilaianKomponen(ctx context.Context, idNilai, idKomponen int) (int, error)
+	UpdatePenilaian(ctx context.Context, idPenilaian int, namaNilai string, nilai int, bobot float64) error
+	DeletePenilaian(ctx context.Context, idPenilaian int) error
+	// SyncKomponen - Menyalin nama dan bobot komponen ke semua penilaian yang menunjuk ke komponen tersebut
+	SyncKomponen(ctx context.Context, idKomponen int, nama string, bobot float64) error
+	// MaxSumBobotByMapel - Jumlah bobot penilaian terbesar di antara semua nilai satu mapel
+	MaxSumBobotByMapel(ctx context.Context, idMapel int) (float64, error)
+}
+
+const penilaianColumns = `p.id_penilaian, p.id_nilai, n.id_mapel, n.id_siswa, n.id_semester, p.nama_nilai, p.nilai, p.bobot, p.id_komponen`
+
+// skalaNilai - Pengali nilai penilaian p ke skala 0 - 100 memakai nilai_maks komponennya (k)
+const skalaNilai = `(100.0 / COALESCE(k.nilai_maks, 100))`
+
+// weightedTotal - Total tertimbang penilaian milik nilai n, dibulatkan sesuai kolom total_nilai.
+// Nilai komponen diskalakan dulu ke 0 - 100 memakai nilai_maks komponennya. Penilaian yang punya
+// remedial memakai nilai efektif menurut aturan_remedial mapel (lihat migrasi 0007_remedial).
+const weightedTotal = `COALESCE((
+	SELECT ROUND(SUM(p.bobot * CASE
+		WHEN r.terakhir IS NULL THEN p.nilai * ` + skalaNilai + `
+		WHEN m.aturan_remedial = 'ganti' THEN r.terakhir * ` + skalaNilai + `
+		WHEN m.aturan_remedial = 'maks' THEN GREATEST(p.nilai, r.tertinggi) * ` + skalaNilai + `
+		ELSE GREATEST(p.nilai * ` + skalaNilai + `, LEAST(r.tertinggi * ` + skalaNilai + `, m.kkm))
+	END), 2)
+	FROM penilaian p
+	JOIN mata_pelajaran m ON m.id_mapel = n.id_mapel
+	LEFT JOIN komponen_penilaian k ON k.id_komponen = p.id_komponen
+	LEFT JOIN LATERAL (
+		SELECT MAX(rm.nilai) AS tertinggi, (ARRAY_AGG(rm.nilai ORDER BY rm.tanggal DESC, rm.id_remedial DESC))[1] AS terakhir
+		FROM remedial rm
+		WHERE rm.id_penilaian = p.id_penilaian
+	) r ON TRUE
+	WHERE p.id_nilai = n.id_nilai
+), 0)`
+
+type nilaiPostgres struct {
+	q DBTX
+}
+
+func scanPenilaian(row rowScanner) (models.Penilaian, error) {
+	var p models.Penilaian
+	var bobot float64
+	err := row.Scan(&p.IDPenilaian, &p.IDNilai, &p.IDMapel, &p.IDSiswa, &p.IDSemester, &p.NamaNilai, &p.Nilai, &bobot, &p.IDKomponen)
+	p.Bobot = models.FormatBobot(bobot)
+	return p, err
+}
+
+func (r *nilaiPostgres) GetBySiswaAndMapel(ctx context.Context, idSiswa, idMapel, idSemester int) (models.Nilai, error) {
+	var n models.Nilai
+	err := r.q.QueryRowContext(ctx, `
+		SELECT id_nilai, id_siswa, id_mapel, id_semester, total_nilai
+		FROM nilai
+		WHERE id_siswa = $1 AND id_mapel = $2 AND id_semester = $3
+	`, idSiswa, idMapel, idSemester).Scan(&n.IDNilai, &n.IDSiswa, &n.IDMapel, &n.IDSemester, &n.TotalNilai)
+	return n, notFound(err)
+}
+
+func (r *nilaiPostgres) FindOrCreate(ctx context.Context, idSiswa, idMapel, idSemester int) (int, error) {
+	// DO UPDATE (bukan DO NOTHING) supaya RETURNING tetap mengembalikan baris yang
+	// sudah ada dan baris tersebut terkunci, meskipun dua request masuk bersamaan
+	var idNilai int
+	err := r.q.QueryRowContext(ctx, `
+		INSERT INTO nilai (id_siswa, id_mapel, id_semester, total_nilai)
+		VALUES ($1, $2, $3, 0)
+		ON CONFLICT (id_siswa, id_mapel, id_semester) DO UPDATE SET id_siswa = EXCLUDED.id_siswa
+		RETURNING id_nilai
+	`, idSiswa, idMapel, idSemester).Scan(&idNilai)
+	return idNilai, conflict(err)
+}
+
+func (r *nilaiPostgres) ListBySiswa(ctx context.Context, idSiswa, idSemester int) ([]models.NilaiMapel, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT n.id_nilai, n.total_nilai, m.nama_mata_pelajaran, m.kkm, m.skala_predikat
+		FROM nilai n
+		JOIN mata_pelajaran m ON n.id_mapel = m.id_mapel
+		WHERE n.id_siswa = $1 AND n.id_semester = $2
+		ORDER BY m.nama_mata_pelajaran
+	`, idSiswa, idSemester)
+	return collect(rows, err, func(row rowScanner) (models.NilaiMapel, error) {
+		var nm models.NilaiMapel
+		err := row.Scan(&nm.ID, &nm.Nilai, &nm.Mapel, &nm.KKM, &nm.Skala)
+		return nm, err
+	})
+}
+
+func (r *nilaiPostgres) ListByKelas(ctx context.Context, idKelas, idSemester int) ([]models.Nilai, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT n.id_nilai, n.id_siswa, n.id_mapel, n.id_semester, n.total_nilai
+		FROM nilai n
+		JOIN mata_pelajaran m ON m.id_mapel = n.id_mapel
+		WHERE m.id_kelas = $1 AND n.id_semester = $2
+	`, idKelas, idSemester)
+	return collect(rows, err, func(row rowScanner) (models.Nilai, error) {
+		var n models.Nilai
+		err := row.Scan(&n.IDNilai, &n.IDSiswa, &n.IDMapel, &n.IDSemester, &n.TotalNilai)
+		return n, err
+	})
+}
+
+func (r *nilaiPostgres) ListBelumTuntas(ctx context.Context, idMapel, idSemester int) ([]models.SiswaRemedial, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT s.id_siswa, s.nisn, s.nama_siswa, n.total_nilai, m.kkm
+		FROM nilai n
+		JOIN mata_pelajaran m ON m.id_mapel = n.id_mapel
+		JOIN siswa s ON s.id_siswa = n.id_siswa AND s.id_kelas = m.id_kelas AND s.status = 'aktif'
+		WHERE n.id_mapel = $1 AND n.id_semester = $2 AND n.total_nilai < m.kkm
+		ORDER BY s.nama_siswa, s.id_siswa
+	`, idMapel, idSemester)
+	return collect(rows, err, func(row rowScanner) (models.SiswaRemedial, error) {
+		var sr models.SiswaRemedial
+		err := row.Scan(&sr.IDSiswa, &sr.NISN, &sr.NamaSiswa, &sr.TotalNilai, &sr.KKM)
+		return sr, err
+	})
+}
+
+// Anggota kelas diambil dari nilai, bukan siswa.id_kelas, supaya peringkat kelas tahun lalu
+// tetap bisa dihitung setelah siswanya naik kelas.
+func (r *nilaiPostgres) ListPeringkat(ctx context.Context, idKelas int, idMapel *int, idSemester int) ([]models.PeringkatSiswa, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT s.id_siswa, s.nisn, s.nama_siswa, x.rata_rata, x.jumlah_mapel,
+			DENSE_RANK() OVER (ORDER BY x.rata_rata DESC),
+			CASE WHEN COUNT(*) OVER () = 1 THEN 100
+				ELSE ROUND((PERCENT_RANK() OVER (ORDER BY x.rata_rata) * 100)::numeric, 2)
+			END
+		FROM (
+			SELECT n.id_siswa, ROUND(AVG(n.total_nilai), 2) AS rata_rata, COUNT(*) AS jumlah_mapel
+			FROM nilai n
+			JOIN mata_pelajaran m ON m.id_mapel = n.id_mapel
+			WHERE m.id_kelas = $1 AND ($2::int IS NULL OR m.id_mapel = $2) AND n.id_semester = $3
+			GROUP BY n.id_siswa
+		) x
+		JOIN siswa s ON s.id_siswa = x.id_siswa
+		ORDER BY 6, s.nama_siswa, s.id_siswa
+	`, idKelas, idMapel, idSemester)
+	return collect(rows, err, func(row rowScanner) (models.PeringkatSiswa, error) {
+		var p models.PeringkatSiswa
+		err := row.Scan(&p.IDSiswa, &p.NISN, &p.NamaSiswa, &p.RataRata, &p.JumlahMapel, &p.Peringkat, &p.Persentil)
+		return p, err
+	})
+}
+
+func (r *nilaiPostgres) ListRapor(ctx context.Context, idSiswa, idKelas, idSemester int) ([]models.RaporMapel, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT m.id_mapel, m.nama_mata_pelajaran, n.total_nilai, g.nama_guru, m.kkm, m.skala_predikat
+		FROM mata_pelajaran m
+		JOIN kelas k ON k.id_kelas = m.id_kelas
+		JOIN guru g ON g.id_guru = k.id_guru
+		LEFT JOIN nilai n ON n.id_mapel = m.id_mapel AND n.id_siswa = $1 AND n.id_semester = $3
+		WHERE m.id_kelas = $2
+		ORDER BY m.nama_mata_pelajaran, m.id_mapel
+	`, idSiswa, idKelas, idSemester)
+	return collect(rows, err, func(row rowScanner) (models.RaporMapel, error) {
+		var m models.RaporMapel
+		err := row.Scan(&m.IDMapel, &m.NamaMapel, &m.TotalNilai, &m.NamaGuru, &m.KKM, &m.Skala)
+		return m, err
+	})
+}
+
+func (r *nilaiPostgres) Lock(ctx context.Context, idNilai int) error {
+	var id int
+	err := r.q.QueryRowContext(ctx, `SELECT id_nilai FROM nilai WHERE id_nilai = $1 FOR UPDATE`, idNilai).Scan(&id)
+	return notFound(err)
+}
+
+func (r *nilaiPostgres) RecomputeTotal(ctx context.Context, idNilai int) error {
+	return expectAffected(r.q.ExecContext(ctx,
+		`UPDATE nilai n SET total_nilai = `+weightedTotal+` WHERE n.id_nilai = $1`, idNilai))
+}
+
+func (r *nilaiPostgres) RecomputeAll(ctx context.Context) (int64, error) {
+	res, err := r.q.ExecContext(ctx, `
+		UPDATE nilai n
+		SET total_nilai = `+weightedTotal+`
+		WHERE n.total_nilai <> `+weightedTotal)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *nilaiPostgres) RecomputeByMapel(ctx context.Context, idMapel int) error {
+	_, err := r.q.ExecContext(ctx, `UPDATE nilai n SET total_nilai = `+weightedTotal+` WHERE n.id_mapel = $1`, idMapel)
+	return err
+}
+
+func (r *nilaiPostgres) ListPenilaian(ctx context.Context, idSemester *int) ([]models.Penilaian, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+penilaianColumns+`
+		FROM penilaian p
+		JOIN nilai n ON p.id_nilai = n.id_nilai
+		WHERE $1::int IS NULL OR n.id_semester = $1
+		ORDER BY p.id_penilaian
+	`, idSemester)
+	return collect(rows, err, scanPenilaian)
+}
+
+func (r *nilaiPostgres) ListPenilaianByMapel(ctx context.Context, idMapel, idSemester int) ([]models.Penilaian, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+penilaianColumns+`
+		FROM penilaian p
+		JOIN nilai n ON p.id_nilai = n.id_nilai
+		WHERE n.id_mapel = $1 AND n.id_semester = $2
+		ORDER BY n.id_siswa, p.id_penilaian
+	`, idMapel, idSemester)
+	return collect(rows, err, scanPenilaian)
+}
+
+func (r *nilaiPostgres) ListPenilaianByNilai(ctx context.Context, idNilai int) ([]models.Penilaian, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+penilaianColumns+`
+		FROM penilaian p
+		JOIN nilai n ON p.id_nilai = n.id_nilai
+		WHERE p.id_nilai = $1
+		ORDER BY p.id_penilaian
+	`, idNilai)
+	return collect(rows, err, scanPenilaian)
+}
+
+func (r *nilaiPostgres) GetPenilaian(ctx context.Context, idPenilaian int) (models.Penilaian, error) {
+	p, err := scanPenilaian(r.q.QueryRowContext(ctx, `
+		SELECT `+penilaianColumns+`
+		FROM penilaian p
+		JOIN nilai n ON p.id_nilai = n.id_nilai
+		WHERE p.id_penilaian = $1
+	`, idPenilaian))
+	return p, notFound(err)
+}
+
+func (r *nilaiPostgres) SumBobot(ctx context.Context, idNilai, exceptPenilaian int) (float64, error) {
+	var sum float64
+	err := r.q.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(bobot), 0)
+		FROM penilaian
+		WHERE id_nilai = $1 AND id_penilaian <> $2
+	`, idNilai, exceptPenilaian).Scan(&sum)
+	return sum, err
+}
+
+func (r *nilaiPostgres) MapelOfPenilaian(ctx context.Context, idPenilaian int) (int, error) {
+	var idMapel int
+	err := r.q.QueryRowContext(ctx, `
+		SELECT n.id_mapel
+		FROM penilaian p
+		JOIN nilai n ON p.id_nilai = n.id_nilai
+		WHERE p.id_penilaian = $1
+	`, idPenilaian).Scan(&idMapel)
+	return idMapel, notFound(err)
+}
+
+func (r *nilaiPostgres) CreatePenilaian(ctx context.Context, idNilai int, namaNilai string, nilai int, bobot float64, idKomponen *int) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+		INSERT INTO penilaian (id_nilai, nama_nilai, nilai, bobot, id_komponen)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id_penilaian
+	`, idNilai, namaNilai, nilai, bobot, idKomponen).Scan(&id)
+	return id, conflict(err)
+}
+
+func (r *nilaiPostgres) FindPenilaianKomponen(ctx context.Context, idNilai, idKomponen int) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx,
+		`SELECT id_penilaian FROM penilaian WHERE id_nilai = $1 AND id_komponen = $2`, idNilai, idKomponen,
+	).Scan(&id)
+	return id, notFound(err)
+}
+
+func (r *nilaiPostgres) UpdatePenilaian(ctx context.Context, idPenilaian int, namaNilai string, nilai int, bobot float64) error {
+	return expectAffected(r.q.ExecContext(ctx, `
+		UPDATE penilaian
+		SET nama_nilai=$1, nilai=$2, bobot=$3
+		WHERE id_penilaian=$4
+	`, namaNilai, nilai, bobot, idPenilaian))
+}
+
+func (r *nilaiPostgres) DeletePenilaian(ctx context.Context, idPenilaian int) error {
+	return expectAffected(r.q.ExecContext(ctx, `DELETE FROM penilaian WHERE id_penilaian = $1`, idPenilaian))
+}
+
+func (r *nilaiPostgres) SyncKomponen(ctx context.Context, idKomponen int, nama string, bobot float64) error {
+	_, err := r.q.ExecContext(ctx,
+		`UPDATE penilaian SET nama_nilai = $1, bobot = $2 WHERE id_komponen = $3`, nama, bobot, idKomponen)
+	return err
+}
+
+func (r *nilaiPostgres) MaxSumBobotByMapel(ctx context.Context, idMapel int) (float64, error) {
+	var sum float64
+	err := r.q.QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(total), 0)
+		FROM (
+			SELECT SUM(p.bobot) AS total
+			FROM penilaian p
+			JOIN nilai n ON p.id_nilai = n.id_nilai
+			WHERE n.id_mapel = $1
+			GROUP BY p.id_nilai
+		) t
+	`, idMapel).Scan(&sum)
+	return sum, err
+}
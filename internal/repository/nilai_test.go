@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"myapp/internal/models"
+)
+
+// TestWeightedTotal - weightedTotal (SQL) harus sama dengan models.TotalNilai untuk kasus yang sama
+func TestWeightedTotal(t *testing.T) {
+	remedial := func(id, nilai int, tanggal string) models.Remedial {
+		return models.Remedial{IDRemedial: id, Nilai: nilai, Tanggal: tanggal}
+	}
+
+	tests := []struct {
+		name      string
+		aturan    string
+		kkm       float64
+		penilaian []models.PenilaianTertimbang
+		want      float64
+	}{
+		{"belum ada penilaian", models.AturanRemedialBatasKKM, 75, nil, 0},
+		{"bobot 30% dan 70%", models.AturanRemedialBatasKKM, 75, []models.PenilaianTertimbang{
+			{Nilai: 85, Bobot: 0.3},
+			{Nilai: 90, Bobot: 0.7},
+		}, 88.5},
+		{"nilai_maks 50", models.AturanRemedialBatasKKM, 75, []models.PenilaianTertimbang{
+			{Nilai: 40, NilaiMaks: 50, Bobot: 1},
+		}, 80},
+		{"sepertiga", models.AturanRemedialBatasKKM, 75, []models.PenilaianTertimbang{
+			{Nilai: 1, NilaiMaks: 3, Bobot: 0.5},
+			{Nilai: 2, NilaiMaks: 3, Bobot: 0.5},
+		}, 50},
+		{"tepat setengah dibulatkan ke atas", models.AturanRemedialBatasKKM, 75, []models.PenilaianTertimbang{
+			{Nilai: 10, Bobot: 0.1235},
+		}, 1.24},
+		{"ganti memakai remedial terakhir", models.AturanRemedialGanti, 75, []models.PenilaianTertimbang{
+			{Nilai: 60, Bobot: 1, Remedial: []models.Remedial{remedial(0, 70, "2024-01-10"), remedial(0, 65, "2024-01-12")}},
+		}, 65},
+		{"maks memakai remedial tertinggi", models.AturanRemedialMaks, 75, []models.PenilaianTertimbang{
+			{Nilai: 60, Bobot: 1, Remedial: []models.Remedial{remedial(0, 70, "2024-01-10"), remedial(0, 65, "2024-01-12")}},
+		}, 70},
+		{"batas_kkm dibatasi KKM", models.AturanRemedialBatasKKM, 72.5, []models.PenilaianTertimbang{
+			{Nilai: 30, NilaiMaks: 50, Bobot: 1, Remedial: []models.Remedial{remedial(0, 45, "2024-01-10")}},
+		}, 72.5},
+		{"batas_kkm nilai asli di atas KKM", models.AturanRemedialBatasKKM, 75, []models.PenilaianTertimbang{
+			{Nilai: 80, Bobot: 1, Remedial: []models.Remedial{remedial(0, 90, "2024-01-10")}},
+		}, 80},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			tx := testTx(t)
+			f := newFixtureKelas(t, tx)
+			idMapel := mustID(t, tx, `
+				INSERT INTO mata_pelajaran (id_kelas, nama_mata_pelajaran, kkm, aturan_remedial)
+				VALUES ($1, 'Mapel Test', $2, $3) RETURNING id_mapel`, f.idKelas, tt.kkm, tt.aturan)
+
+			repo := &nilaiPostgres{q: tx}
+			idNilai, err := repo.FindOrCreate(ctx, f.idSiswa, idMapel, f.idSemester)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, p := range tt.penilaian {
+				var idKomponen *int
+				if p.NilaiMaks > 0 {
+					id := mustID(t, tx, `
+						INSERT INTO komponen_penilaian (id_mapel, nama_komponen, bobot, nilai_maks)
+						VALUES ($1, $2, $3, $4) RETURNING id_komponen`, idMapel, "Komponen "+string(rune('A'+i)), p.Bobot, p.NilaiMaks)
+					idKomponen = &id
+				}
+				idPenilaian, err := repo.CreatePenilaian(ctx, idNilai, "Penilaian", p.Nilai, p.Bobot, idKomponen)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, r := range p.Remedial {
+					mustID(t, tx, `INSERT INTO remedial (id_penilaian, nilai, tanggal) VALUES ($1, $2, $3) RETURNING id_remedial`,
+						idPenilaian, r.Nilai, r.Tanggal)
+				}
+			}
+
+			if err := repo.RecomputeTotal(ctx, idNilai); err != nil {
+				t.Fatal(err)
+			}
+			n, err := repo.GetBySiswaAndMapel(ctx, f.idSiswa, idMapel, f.idSemester)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n.TotalNilai != tt.want {
+				t.Errorf("total_nilai = %v, want %v", n.TotalNilai, tt.want)
+			}
+			if got := models.TotalNilai(tt.aturan, tt.kkm, tt.penilaian); got != n.TotalNilai {
+				t.Errorf("models.TotalNilai = %v, weightedTotal = %v", got, n.TotalNilai)
+			}
+		})
+	}
+}
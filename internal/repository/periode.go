@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+
+	"myapp/internal/models"
+)
+
+// PeriodeRepository - Akses data tabel tahun_ajaran dan semester
+type PeriodeRepository interface {
+	ListTahunAjaran(ctx context.Context) ([]models.TahunAjaran, error)
+	GetTahunAjaran(ctx context.Context, idTahunAjaran int) (models.TahunAjaran, error)
+	// FindTahunAjaran - Tahun ajaran berdasarkan nama, contoh "2025/2026"
+	FindTahunAjaran(ctx context.Context, nama string) (models.TahunAjaran, error)
+	CreateTahunAjaran(ctx context.Context, t models.TahunAjaran) (int, error)
+
+	// ListSemester - Semua semester, atau semester satu tahun ajaran jika idTahunAjaran tidak nil
+	ListSemester(ctx context.Context, idTahunAjaran *int) ([]models.Semester, error)
+	GetSemester(ctx context.Context, idSemester int) (models.Semester, error)
+	// ActiveSemester - Semester yang sedang aktif, ErrNotFound jika belum ada yang diaktifkan
+	ActiveSemester(ctx context.Context) (models.Semester, error)
+	// DefaultSemester - Semester aktif jika ada di tahun ajaran tersebut, selain itu semester terakhirnya
+	DefaultSemester(ctx context.Context, idTahunAjaran int) (models.Semester, error)
+	CreateSemester(ctx context.Context, s models.Semester) (int, error)
+	// UpdateTanggalSemester - Mengubah tanggal mulai dan selesai semester
+	UpdateTanggalSemester(ctx context.Context, idSemester int, s models.Semester) error
+	// Activate - Menjadikan idSemester satu-satunya semester aktif. Harus di dalam transaksi.
+	Activate(ctx context.Context, idSemester int) error
+}
+
+const semesterColumns = `s.id_semester, s.id_tahun_ajaran, t.nama, s.semester,
+	TO_CHAR(s.tanggal_mulai, 'YYYY-MM-DD'), TO_CHAR(s.tanggal_selesai, 'YYYY-MM-DD'), s.aktif`
+
+type periodePostgres struct {
+	q DBTX
+}
+
+func scanSemester(row rowScanner) (models.Semester, error) {
+	var s models.Semester
+	err := row.Scan(&s.IDSemester, &s.IDTahunAjaran, &s.TahunAjaran, &s.Semester, &s.TanggalMulai, &s.TanggalSelesai, &s.Aktif)
+	return s, err
+}
+
+func scanTahunAjaran(row rowScanner) (models.TahunAjaran, error) {
+	var t models.TahunAjaran
+	err := row.Scan(&t.IDTahunAjaran, &t.Nama)
+	return t, err
+}
+
+func (r *periodePostgres) ListTahunAjaran(ctx context.Context) ([]models.TahunAjaran, error) {
+	rows, err := r.q.QueryContext(ctx, `SELECT id_tahun_ajaran, nama FROM tahun_ajaran ORDER BY nama DESC`)
+	return collect(rows, err, scanTahunAjaran)
+}
+
+func (r *periodePostgres) GetTahunAjaran(ctx context.Context, idTahunAjaran int) (models.TahunAjaran, error) {
+	t, err := scanTahunAjaran(r.q.QueryRowContext(ctx,
+		`SELECT id_tahun_ajaran, nama FROM tahun_ajaran WHERE id_tahun_ajaran = $1`, idTahunAjaran))
+	return t, notFound(err)
+}
+
+func (r *periodePostgres) FindTahunAjaran(ctx context.Context, nama string) (models.TahunAjaran, error) {
+	t, err := scanTahunAjaran(r.q.QueryRowContext(ctx,
+		`SELECT id_tahun_ajaran, nama FROM tahun_ajaran WHERE nama = $1`, nama))
+	return t, notFound(err)
+}
+
+func (r *periodePostgres) CreateTahunAjaran(ctx context.Context, t models.TahunAjaran) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx,
+		`INSERT INTO tahun_ajaran (nama) VALUES ($1) RETURNING id_tahun_ajaran`, t.Nama,
+	).Scan(&id)
+	return id, conflict(err)
+}
+
+func (r *periodePostgres) ListSemester(ctx context.Context, idTahunAjaran *int) ([]models.Semester, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+semesterColumns+`
+		FROM semester s
+		JOIN tahun_ajaran t ON t.id_tahun_ajaran = s.id_tahun_ajaran
+		WHERE $1::int IS NULL OR s.id_tahun_ajaran = $1
+		ORDER BY s.tanggal_mulai DESC
+	`, idTahunAjaran)
+	return collect(rows, err, scanSemester)
+}
+
+func (r *periodePostgres) GetSemester(ctx context.Context, idSemester int) (models.Semester, error) {
+	s, err := scanSemester(r.q.QueryRowContext(ctx, `
+		SELECT `+semesterColumns+`
+		FROM semester s
+		JOIN tahun_ajaran t ON t.id_tahun_ajaran = s.id_tahun_ajaran
+		WHERE s.id_semester = $1
+	`, idSemester))
+	return s, notFound(err)
+}
+
+func (r *periodePostgres) ActiveSemester(ctx context.Context) (models.Semester, error) {
+	s, err := scanSemester(r.q.QueryRowContext(ctx, `
+		SELECT `+semesterColumns+`
+		FROM semester s
+		JOIN tahun_ajaran t ON t.id_tahun_ajaran = s.id_tahun_ajaran
+		WHERE s.aktif
+	`))
+	return s, notFound(err)
+}
+
+func (r *periodePostgres) DefaultSemester(ctx context.Context, idTahunAjaran int) (models.Semester, error) {
+	s, err := scanSemester(r.q.QueryRowContext(ctx, `
+		SELECT `+semesterColumns+`
+		FROM semester s
+		JOIN tahun_ajaran t ON t.id_tahun_ajaran = s.id_tahun_ajaran
+		WHERE s.id_tahun_ajaran = $1
+		ORDER BY s.aktif DESC, s.semester DESC
+		LIMIT 1
+	`, idTahunAjaran))
+	return s, notFound(err)
+}
+
+func (r *periodePostgres) CreateSemester(ctx context.Context, s models.Semester) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+		INSERT INTO semester (id_tahun_ajaran, semester, tanggal_mulai, tanggal_selesai)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id_semester
+	`, s.IDTahunAjaran, s.Semester, s.TanggalMulai, s.TanggalSelesai).Scan(&id)
+	return id, conflict(err)
+}
+
+func (r *periodePostgres) UpdateTanggalSemester(ctx context.Context, idSemester int, s models.Semester) error {
+	return expectAffected(r.q.ExecContext(ctx,
+		`UPDATE semester SET tanggal_mulai = $1, tanggal_selesai = $2 WHERE id_semester = $3`,
+		s.TanggalMulai, s.TanggalSelesai, idSemester,
+	))
+}
+
+// Dua UPDATE terpisah: index unik semester_aktif_key dicek per baris, jadi semester lama
+// harus dinonaktifkan dulu sebelum yang baru diaktifkan
+func (r *periodePostgres) Activate(ctx context.Context, idSemester int) error {
+	if _, err := r.q.ExecContext(ctx,
+		`UPDATE semester SET aktif = FALSE WHERE aktif AND id_semester <> $1`, idSemester); err != nil {
+		return err
+	}
+	return expectAffected(r.q.ExecContext(ctx, `UPDATE semester SET aktif = TRUE WHERE id_semester = $1`, idSemester))
+}
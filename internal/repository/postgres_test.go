@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"sync"
+	"testing"
+
+	_ "github.com/lib/pq"
+
+	"myapp/internal/db"
+)
+
+// Test repository Postgres butuh database sungguhan: TEST_DATABASE_URL harus menunjuk ke database
+// khusus test (semua migrasi dijalankan di sana). Tanpa TEST_DATABASE_URL test ini dilewati.
+var (
+	testDBOnce sync.Once
+	testDB     *sql.DB
+	testDBErr  error
+)
+
+// testTx - Transaksi di database test yang di-rollback setelah test selesai
+func testTx(t *testing.T) *sql.Tx {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL tidak diisi, test Postgres dilewati")
+	}
+
+	testDBOnce.Do(func() {
+		testDB, testDBErr = sql.Open("postgres", dsn)
+		if testDBErr == nil {
+			_, testDBErr = db.MigrateUp(context.Background(), testDB, 0)
+		}
+	})
+	if testDBErr != nil {
+		t.Fatalf("database test: %v", testDBErr)
+	}
+
+	tx, err := testDB.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	t.Cleanup(func() { tx.Rollback() })
+	return tx
+}
+
+// mustID - Menjalankan INSERT ... RETURNING id dan menghentikan test jika gagal
+func mustID(t *testing.T, q DBTX, query string, args ...any) int {
+	t.Helper()
+	var id int
+	if err := q.QueryRowContext(context.Background(), query, args...).Scan(&id); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return id
+}
+
+// fixtureKelas - Satu kelas dengan wali kelas, semester 1 tahun ajaran 2024/2025 dan satu siswa
+type fixtureKelas struct {
+	idKelas, idSemester, idSiswa int
+}
+
+func newFixtureKelas(t *testing.T, q DBTX) fixtureKelas {
+	t.Helper()
+	idUserGuru := mustID(t, q, `INSERT INTO "user" (username, password, id_role) VALUES ('guru-test', 'x', 2) RETURNING id_user`)
+	idGuru := mustID(t, q, `INSERT INTO guru (id_user, nama_guru) VALUES ($1, 'Guru Test') RETURNING id_guru`, idUserGuru)
+	idTahun := mustID(t, q, `INSERT INTO tahun_ajaran (nama) VALUES ('2099/2100') RETURNING id_tahun_ajaran`)
+	idSemester := mustID(t, q, `
+		INSERT INTO semester (id_tahun_ajaran, semester, tanggal_mulai, tanggal_selesai)
+		VALUES ($1, 1, '2099-07-01', '2099-12-31') RETURNING id_semester`, idTahun)
+	idKelas := mustID(t, q, `INSERT INTO kelas (id_guru, nama_kelas, id_tahun_ajaran) VALUES ($1, 'X TEST', $2) RETURNING id_kelas`, idGuru, idTahun)
+	idUserSiswa := mustID(t, q, `INSERT INTO "user" (username, password, id_role) VALUES ('siswa-test', 'x', 3) RETURNING id_user`)
+	idSiswa := mustID(t, q, `
+		INSERT INTO siswa (id_user, id_kelas, nama_siswa, tanggal_lahir, nisn)
+		VALUES ($1, $2, 'Siswa Test', '2084-01-01', '9999999999') RETURNING id_siswa`, idUserSiswa, idKelas)
+	return fixtureKelas{idKelas: idKelas, idSemester: idSemester, idSiswa: idSiswa}
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+
+	"myapp/internal/models"
+)
+
+// RemedialRepository - Akses data tabel remedial. Setelah menambah atau menghapus remedial,
+// total_nilai induknya harus dihitung ulang lewat NilaiRepository.RecomputeTotal.
+type RemedialRepository interface {
+	ListByPenilaian(ctx context.Context, idPenilaian int) ([]models.Remedial, error)
+	// ListByMapel - Semua remedial untuk penilaian-penilaian satu mapel pada satu semester
+	ListByMapel(ctx context.Context, idMapel, idSemester int) ([]models.Remedial, error)
+	GetByID(ctx context.Context, idRemedial int) (models.Remedial, error)
+	Create(ctx context.Context, r models.Remedial) (int, error)
+	Delete(ctx context.Context, idRemedial int) error
+}
+
+const remedialColumns = `r.id_remedial, r.id_penilaian, r.nilai, TO_CHAR(r.tanggal, 'YYYY-MM-DD'), r.catatan`
+
+type remedialPostgres struct {
+	q DBTX
+}
+
+func scanRemedial(row rowScanner) (models.Remedial, error) {
+	var r models.Remedial
+	err := row.Scan(&r.IDRemedial, &r.IDPenilaian, &r.Nilai, &r.Tanggal, &r.Catatan)
+	return r, err
+}
+
+func (r *remedialPostgres) ListByPenilaian(ctx context.Context, idPenilaian int) ([]models.Remedial, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+remedialColumns+`
+		FROM remedial r
+		WHERE r.id_penilaian = $1
+		ORDER BY r.tanggal, r.id_remedial
+	`, idPenilaian)
+	return collect(rows, err, scanRemedial)
+}
+
+func (r *remedialPostgres) ListByMapel(ctx context.Context, idMapel, idSemester int) ([]models.Remedial, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+remedialColumns+`
+		FROM remedial r
+		JOIN penilaian p ON p.id_penilaian = r.id_penilaian
+		JOIN nilai n ON n.id_nilai = p.id_nilai
+		WHERE n.id_mapel = $1 AND n.id_semester = $2
+		ORDER BY r.tanggal, r.id_remedial
+	`, idMapel, idSemester)
+	return collect(rows, err, scanRemedial)
+}
+
+func (r *remedialPostgres) GetByID(ctx context.Context, idRemedial int) (models.Remedial, error) {
+	rm, err := scanRemedial(r.q.QueryRowContext(ctx,
+		`SELECT `+remedialColumns+` FROM remedial r WHERE r.id_remedial = $1`, idRemedial))
+	return rm, notFound(err)
+}
+
+func (r *remedialPostgres) Create(ctx context.Context, rm models.Remedial) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+		INSERT INTO remedial (id_penilaian, nilai, tanggal, catatan)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id_remedial
+	`, rm.IDPenilaian, rm.Nilai, rm.Tanggal, rm.Catatan).Scan(&id)
+	return id, err
+}
+
+func (r *remedialPostgres) Delete(ctx context.Context, idRemedial int) error {
+	return expectAffected(r.q.ExecContext(ctx, `DELETE FROM remedial WHERE id_remedial = $1`, idRemedial))
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrNotFound - Dikembalikan semua repository jika baris yang dicari tidak ada
+	ErrNotFound = errors.New("data tidak ditemukan")
+	// ErrConflict - Dikembalikan jika perubahan melanggar constraint unique
+	ErrConflict = errors.New("data sudah ada")
+)
+
+// DBTX - Dipenuhi oleh *sql.DB dan *sql.Tx sehingga repository yang sama bisa
+// dipakai di dalam maupun di luar transaksi
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Repositories - Semua repository yang dibutuhkan handler. Handler hanya bergantung
+// pada interface di sini, sehingga implementasi Postgres bisa diganti fake di test.
+type Repositories struct {
+	Guru          GuruRepository
+	Siswa         SiswaRepository
+	Kelas         KelasRepository
+	MataPelajaran MataPelajaranRepository
+	Nilai         NilaiRepository
+	Komponen      KomponenRepository
+	Remedial      RemedialRepository
+	Periode       PeriodeRepository
+	RiwayatKelas  RiwayatKelasRepository
+	Absensi       AbsensiRepository
+	User          UserRepository
+
+	runInTx func(ctx context.Context, fn func(Repositories) error) error
+}
+
+// InTx - Menjalankan fn dengan repository yang memakai satu transaksi yang sama.
+// Transaksi di-commit jika fn mengembalikan nil dan di-rollback jika tidak.
+// Repositories tanpa dukungan transaksi (misalnya fake) langsung menjalankan fn.
+func (r Repositories) InTx(ctx context.Context, fn func(Repositories) error) error {
+	if r.runInTx == nil {
+		return fn(r)
+	}
+	return r.runInTx(ctx, fn)
+}
+
+// NewPostgres - Membuat semua repository yang didukung oleh PostgreSQL
+func NewPostgres(db *sql.DB) Repositories {
+	repos := newPostgresRepositories(db)
+	repos.runInTx = func(ctx context.Context, fn func(Repositories) error) error {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if err := fn(newPostgresRepositories(tx)); err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				return fmt.Errorf("%w (rollback: %v)", err, rbErr)
+			}
+			return err
+		}
+		return tx.Commit()
+	}
+	return repos
+}
+
+func newPostgresRepositories(q DBTX) Repositories {
+	return Repositories{
+		Guru:          &guruPostgres{q: q},
+		Siswa:         &siswaPostgres{q: q},
+		Kelas:         &kelasPostgres{q: q},
+		MataPelajaran: &mataPelajaranPostgres{q: q},
+		Nilai:         &nilaiPostgres{q: q},
+		Komponen:      &komponenPostgres{q: q},
+		Remedial:      &remedialPostgres{q: q},
+		Periode:       &periodePostgres{q: q},
+		RiwayatKelas:  &riwayatKelasPostgres{q: q},
+		Absensi:       &absensiPostgres{q: q},
+		User:          &userPostgres{q: q},
+	}
+}
+
+// rowScanner - *sql.Row dan *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// notFound - Mengubah sql.ErrNoRows menjadi ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// conflict - Membungkus pelanggaran constraint unique (23505) dengan ErrConflict
+func conflict(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return fmt.Errorf("%w: %s", ErrConflict, pqErr.Constraint)
+	}
+	return err
+}
+
+// expectAffected - ErrNotFound jika UPDATE/DELETE tidak mengenai baris apa pun
+func expectAffected(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// collect - Membaca semua baris dengan fungsi scan yang sama
+func collect[T any](rows *sql.Rows, err error, scan func(rowScanner) (T, error)) ([]T, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []T
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, item)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+
+	"myapp/internal/models"
+)
+
+// RiwayatKelasRepository - Akses data tabel riwayat_kelas
+type RiwayatKelasRepository interface {
+	// ListBySiswa - Riwayat kelas seorang siswa, terlama lebih dulu
+	ListBySiswa(ctx context.Context, idSiswa int) ([]models.RiwayatKelas, error)
+	// Masuk - Membuka riwayat aktif siswa di kelas tersebut mulai tanggal
+	Masuk(ctx context.Context, idSiswa, idKelas int, tanggal string) (int, error)
+	// Keluar - Menutup riwayat aktif siswa pada tanggal dengan status akhirnya.
+	// Tidak error jika siswa tidak punya riwayat aktif.
+	Keluar(ctx context.Context, idSiswa int, tanggal, status string) error
+}
+
+type riwayatKelasPostgres struct {
+	q DBTX
+}
+
+func (r *riwayatKelasPostgres) ListBySiswa(ctx context.Context, idSiswa int) ([]models.RiwayatKelas, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT rk.id_riwayat, rk.id_siswa, rk.id_kelas, k.nama_kelas, k.id_tahun_ajaran, t.nama,
+			TO_CHAR(rk.tanggal_masuk, 'YYYY-MM-DD'), TO_CHAR(rk.tanggal_keluar, 'YYYY-MM-DD'), rk.status
+		FROM riwayat_kelas rk
+		JOIN kelas k ON k.id_kelas = rk.id_kelas
+		JOIN tahun_ajaran t ON t.id_tahun_ajaran = k.id_tahun_ajaran
+		WHERE rk.id_siswa = $1
+		ORDER BY rk.tanggal_masuk, rk.id_riwayat
+	`, idSiswa)
+	return collect(rows, err, func(row rowScanner) (models.RiwayatKelas, error) {
+		var rk models.RiwayatKelas
+		err := row.Scan(&rk.IDRiwayat, &rk.IDSiswa, &rk.IDKelas, &rk.NamaKelas, &rk.IDTahunAjaran, &rk.TahunAjaran,
+			&rk.TanggalMasuk, &rk.TanggalKeluar, &rk.Status)
+		return rk, err
+	})
+}
+
+func (r *riwayatKelasPostgres) Masuk(ctx context.Context, idSiswa, idKelas int, tanggal string) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+		INSERT INTO riwayat_kelas (id_siswa, id_kelas, tanggal_masuk)
+		VALUES ($1, $2, $3)
+		RETURNING id_riwayat
+	`, idSiswa, idKelas, tanggal).Scan(&id)
+	return id, conflict(err)
+}
+
+func (r *riwayatKelasPostgres) Keluar(ctx context.Context, idSiswa int, tanggal, status string) error {
+	_, err := r.q.ExecContext(ctx, `
+		UPDATE riwayat_kelas
+		SET tanggal_keluar = GREATEST($2::date, tanggal_masuk), status = $3
+		WHERE id_siswa = $1 AND tanggal_keluar IS NULL
+	`, idSiswa, tanggal, status)
+	return err
+}
@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+
+	"myapp/internal/models"
+)
+
+// SiswaRepository - Akses data tabel siswa
+type SiswaRepository interface {
+	// List, ListByKelas, ListByMapel - status nil berarti semua status
+	List(ctx context.Context, status *string) ([]models.Siswa, error)
+	ListByKelas(ctx context.Context, idKelas int, status *string) ([]models.Siswa, error)
+	ListByMapel(ctx context.Context, idMapel int, status *string) ([]models.Siswa, error)
+	// ListAnggotaKelas - Siswa yang tercatat di kelas selama satu semester menurut riwayat_kelas, ditambah
+	// siswa yang punya nilai di mapel kelas pada semester itu; tidak bergantung pada kelas siswa saat ini
+	ListAnggotaKelas(ctx context.Context, idKelas, idSemester int) ([]models.Siswa, error)
+	// ListAnggotaKelasTanggal - Siswa yang tercatat di kelas pada tanggal menurut riwayat_kelas. Hari keluar
+	// tidak dihitung, sehingga siswa yang pindah hari itu hanya tercatat di kelas barunya.
+	ListAnggotaKelasTanggal(ctx context.Context, idKelas int, tanggal string) ([]models.Siswa, error)
+	// ListAlumni - Siswa berstatus lulus, bisa difilter tahun lulus (tahun dari tanggal_status)
+	ListAlumni(ctx context.Context, tahunLulus *int) ([]models.Alumni, error)
+	GetByID(ctx context.Context, idSiswa int) (models.Siswa, error)
+	GetByUserID(ctx context.Context, idUser int) (models.Siswa, error)
+	Create(ctx context.Context, siswa models.Siswa) (int, error)
+	Update(ctx context.Context, idSiswa int, siswa models.Siswa) error
+	UpdateKelas(ctx context.Context, idSiswa, idKelas int) error
+	// UpdateStatus - Mengubah status siswa; tanggal_status dikosongkan jika status kembali aktif
+	UpdateStatus(ctx context.Context, idSiswa int, s models.StatusSiswa) error
+	UpdateFoto(ctx context.Context, idSiswa int, url string) error
+	Delete(ctx context.Context, idSiswa int) error
+	// HasHistory - true jika siswa sudah punya nilai, absensi atau riwayat kelas yang sudah ditutup
+	// (baris riwayat aktif hanya mencatat kelas saat ini)
+	HasHistory(ctx context.Context, idSiswa int) (bool, error)
+	// ExistingNISN - NISN dari daftar yang sudah dipakai siswa lain, dibandingkan tanpa nol di depan
+	ExistingNISN(ctx context.Context, nisn []string) ([]string, error)
+	// IsOwnedBy - true jika baris siswa tersebut milik id_user
+	IsOwnedBy(ctx context.Context, idSiswa, idUser int) (bool, error)
+}
+
+// siswaColumns - Urutan kolom yang sama untuk semua query siswa
+const siswaColumns = `s.id_siswa, s.id_user, s.id_kelas, s.nama_siswa, s.alamat, s.tanggal_lahir, s.nisn, COALESCE(s.foto, ''),
+	s.status, TO_CHAR(s.tanggal_status, 'YYYY-MM-DD'), s.keterangan`
+
+type siswaPostgres struct {
+	q DBTX
+}
+
+func scanSiswa(row rowScanner, extra ...any) (models.Siswa, error) {
+	var s models.Siswa
+	err := row.Scan(append([]any{&s.IDSiswa, &s.IDUser, &s.IDKelas, &s.NamaSiswa, &s.Alamat, &s.TanggalLahir, &s.NISN, &s.Foto,
+		&s.Status, &s.TanggalStatus, &s.Keterangan}, extra...)...)
+	return s, err
+}
+
+func scanSiswaRow(row rowScanner) (models.Siswa, error) {
+	return scanSiswa(row)
+}
+
+func (r *siswaPostgres) List(ctx context.Context, status *string) ([]models.Siswa, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+siswaColumns+`
+		FROM siswa s
+		WHERE $1::text IS NULL OR s.status = $1
+		ORDER BY s.id_siswa
+	`, status)
+	return collect(rows, err, scanSiswaRow)
+}
+
+func (r *siswaPostgres) ListByKelas(ctx context.Context, idKelas int, status *string) ([]models.Siswa, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+siswaColumns+`
+		FROM siswa s
+		WHERE s.id_kelas = $1 AND ($2::text IS NULL OR s.status = $2)
+		ORDER BY s.nama_siswa
+	`, idKelas, status)
+	return collect(rows, err, scanSiswaRow)
+}
+
+func (r *siswaPostgres) ListByMapel(ctx context.Context, idMapel int, status *string) ([]models.Siswa, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+siswaColumns+`
+		FROM siswa s
+		JOIN mata_pelajaran mp ON mp.id_kelas = s.id_kelas
+		WHERE mp.id_mapel = $1 AND ($2::text IS NULL OR s.status = $2)
+		ORDER BY s.nama_siswa
+	`, idMapel, status)
+	return collect(rows, err, scanSiswaRow)
+}
+
+func (r *siswaPostgres) ListAnggotaKelas(ctx context.Context, idKelas, idSemester int) ([]models.Siswa, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+siswaColumns+`
+		FROM siswa s
+		WHERE s.id_siswa IN (
+			SELECT rk.id_siswa
+			FROM riwayat_kelas rk
+			JOIN semester sm ON sm.id_semester = $2
+			WHERE rk.id_kelas = $1 AND rk.tanggal_masuk <= sm.tanggal_selesai
+				AND (rk.tanggal_keluar IS NULL OR rk.tanggal_keluar >= sm.tanggal_mulai)
+			UNION
+			SELECT n.id_siswa
+			FROM nilai n
+			JOIN mata_pelajaran mp ON mp.id_mapel = n.id_mapel
+			WHERE mp.id_kelas = $1 AND n.id_semester = $2
+		)
+		ORDER BY s.nama_siswa
+	`, idKelas, idSemester)
+	return collect(rows, err, scanSiswaRow)
+}
+
+func (r *siswaPostgres) ListAnggotaKelasTanggal(ctx context.Context, idKelas int, tanggal string) ([]models.Siswa, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+siswaColumns+`
+		FROM siswa s
+		WHERE EXISTS (
+			SELECT 1
+			FROM riwayat_kelas rk
+			WHERE rk.id_siswa = s.id_siswa AND rk.id_kelas = $1 AND rk.tanggal_masuk <= $2::date
+				AND (rk.tanggal_keluar IS NULL OR rk.tanggal_keluar > $2::date)
+		)
+		ORDER BY s.nama_siswa
+	`, idKelas, tanggal)
+	return collect(rows, err, scanSiswaRow)
+}
+
+func (r *siswaPostgres) ListAlumni(ctx context.Context, tahunLulus *int) ([]models.Alumni, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT `+siswaColumns+`, COALESCE(k.nama_kelas, ''), COALESCE(t.nama, ''),
+			EXTRACT(YEAR FROM s.tanggal_status)::int
+		FROM siswa s
+		LEFT JOIN kelas k ON k.id_kelas = s.id_kelas
+		LEFT JOIN tahun_ajaran t ON t.id_tahun_ajaran = k.id_tahun_ajaran
+		WHERE s.status = 'lulus' AND ($1::int IS NULL OR EXTRACT(YEAR FROM s.tanggal_status) = $1)
+		ORDER BY s.tanggal_status DESC, s.nama_siswa
+	`, tahunLulus)
+	return collect(rows, err, func(row rowScanner) (models.Alumni, error) {
+		var a models.Alumni
+		var err error
+		a.Siswa, err = scanSiswa(row, &a.NamaKelas, &a.TahunAjaran, &a.TahunLulus)
+		return a, err
+	})
+}
+
+func (r *siswaPostgres) GetByID(ctx context.Context, idSiswa int) (models.Siswa, error) {
+	s, err := scanSiswa(r.q.QueryRowContext(ctx, `SELECT `+siswaColumns+` FROM siswa s WHERE s.id_siswa = $1`, idSiswa))
+	return s, notFound(err)
+}
+
+func (r *siswaPostgres) GetByUserID(ctx context.Context, idUser int) (models.Siswa, error) {
+	s, err := scanSiswa(r.q.QueryRowContext(ctx, `SELECT `+siswaColumns+` FROM siswa s WHERE s.id_user = $1`, idUser))
+	return s, notFound(err)
+}
+
+func (r *siswaPostgres) Create(ctx context.Context, s models.Siswa) (int, error) {
+	// id_kelas boleh kosong, siswa baru bisa dimasukkan ke kelas belakangan
+	var idKelas *int
+	if s.IDKelas != nil && *s.IDKelas != 0 {
+		idKelas = s.IDKelas
+	}
+
+	var id int
+	err := r.q.QueryRowContext(ctx, `
+		INSERT INTO siswa (id_user, id_kelas, nama_siswa, alamat, tanggal_lahir, nisn)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id_siswa
+	`, s.IDUser, idKelas, s.NamaSiswa, s.Alamat, s.TanggalLahir, s.NISN).Scan(&id)
+	return id, conflict(err)
+}
+
+func (r *siswaPostgres) Update(ctx context.Context, idSiswa int, s models.Siswa) error {
+	return conflict(expectAffected(r.q.ExecContext(ctx, `
+		UPDATE siswa
+		SET id_user=$1, id_kelas=$2, nama_siswa=$3, alamat=$4, tanggal_lahir=$5, nisn=$6, foto=$7
+		WHERE id_siswa=$8
+	`, s.IDUser, s.IDKelas, s.NamaSiswa, s.Alamat, s.TanggalLahir, s.NISN, s.Foto, idSiswa)))
+}
+
+func (r *siswaPostgres) UpdateKelas(ctx context.Context, idSiswa, idKelas int) error {
+	return expectAffected(r.q.ExecContext(ctx, `UPDATE siswa SET id_kelas = $1 WHERE id_siswa = $2`, idKelas, idSiswa))
+}
+
+func (r *siswaPostgres) UpdateStatus(ctx context.Context, idSiswa int, s models.StatusSiswa) error {
+	return expectAffected(r.q.ExecContext(ctx, `
+		UPDATE siswa
+		SET status = $1, tanggal_status = CASE WHEN $1 = 'aktif' THEN NULL ELSE $2::date END, keterangan = $3
+		WHERE id_siswa = $4
+	`, s.Status, s.Tanggal, s.Keterangan, idSiswa))
+}
+
+func (r *siswaPostgres) UpdateFoto(ctx context.Context, idSiswa int, url string) error {
+	return expectAffected(r.q.ExecContext(ctx, `UPDATE siswa SET foto = $1 WHERE id_siswa = $2`, url, idSiswa))
+}
+
+func (r *siswaPostgres) Delete(ctx context.Context, idSiswa int) error {
+	return expectAffected(r.q.ExecContext(ctx, `DELETE FROM siswa WHERE id_siswa = $1`, idSiswa))
+}
+
+func (r *siswaPostgres) HasHistory(ctx context.Context, idSiswa int) (bool, error) {
+	var has bool
+	err := r.q.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM nilai WHERE id_siswa = $1)
+			OR EXISTS (SELECT 1 FROM absensi WHERE id_siswa = $1)
+			OR EXISTS (SELECT 1 FROM riwayat_kelas WHERE id_siswa = $1 AND tanggal_keluar IS NOT NULL)
+	`, idSiswa).Scan(&has)
+	return has, err
+}
+
+func (r *siswaPostgres) IsOwnedBy(ctx context.Context, idSiswa, idUser int) (bool, error) {
+	var owned bool
+	err := r.q.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM siswa WHERE id_siswa = $1 AND id_user = $2)`,
+		idSiswa, idUser,
+	).Scan(&owned)
+	return owned, err
+}
+
+func (r *siswaPostgres) ExistingNISN(ctx context.Context, nisn []string) ([]string, error) {
+	rows, err := r.q.QueryContext(ctx, `
+		SELECT s.nisn
+		FROM siswa s
+		WHERE s.nisn <> '' AND LTRIM(s.nisn, '0') IN (SELECT LTRIM(x, '0') FROM unnest($1::text[]) AS x)
+	`, pq.Array(nisn))
+	return collect(rows, err, func(row rowScanner) (string, error) {
+		var n string
+		return n, row.Scan(&n)
+	})
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+
+	"myapp/internal/models"
+)
+
+// UserRepository - Akses data tabel "user". Kolom password selalu berisi hash.
+type UserRepository interface {
+	List(ctx context.Context) ([]models.User, error)
+	GetByID(ctx context.Context, idUser int) (models.User, error)
+	// GetByUsername - Satu-satunya method yang ikut mengembalikan hash password
+	GetByUsername(ctx context.Context, username string) (models.User, error)
+	// Create - user.Password harus sudah berupa hash
+	Create(ctx context.Context, user models.User) (int, error)
+	// Update - Mengubah semua kolom kecuali password
+	Update(ctx context.Context, idUser int, user models.User) error
+	UpdatePassword(ctx context.Context, idUser int, passwordHash string) error
+	// UsernameExists - true jika username sudah dipakai (dipakai saat membuat username otomatis)
+	UsernameExists(ctx context.Context, username string) (bool, error)
+	Delete(ctx context.Context, idUser int) error
+}
+
+type userPostgres struct {
+	q DBTX
+}
+
+func scanUser(row rowScanner) (models.User, error) {
+	var u models.User
+	err := row.Scan(&u.IDUser, &u.Username, &u.IDRole, &u.TanggalRegistrasi)
+	return u, err
+}
+
+func (r *userPostgres) List(ctx context.Context) ([]models.User, error) {
+	rows, err := r.q.QueryContext(ctx, `SELECT id_user, username, id_role, tanggal_registrasi FROM "user" ORDER BY id_user`)
+	return collect(rows, err, scanUser)
+}
+
+func (r *userPostgres) GetByID(ctx context.Context, idUser int) (models.User, error) {
+	u, err := scanUser(r.q.QueryRowContext(ctx,
+		`SELECT id_user, username, id_role, tanggal_registrasi FROM "user" WHERE id_user = $1`, idUser))
+	return u, notFound(err)
+}
+
+func (r *userPostgres) GetByUsername(ctx context.Context, username string) (models.User, error) {
+	var u models.User
+	err := r.q.QueryRowContext(ctx,
+		`SELECT id_user, id_role, username, password FROM "user" WHERE username = $1`, username,
+	).Scan(&u.IDUser, &u.IDRole, &u.Username, &u.Password)
+	return u, notFound(err)
+}
+
+func (r *userPostgres) Create(ctx context.Context, u models.User) (int, error) {
+	var id int
+	err := r.q.QueryRowContext(ctx,
+		`INSERT INTO "user" (username, password, id_role, tanggal_registrasi) VALUES ($1, $2, $3, $4) RETURNING id_user`,
+		u.Username, u.Password, u.IDRole, u.TanggalRegistrasi,
+	).Scan(&id)
+	return id, conflict(err)
+}
+
+func (r *userPostgres) Update(ctx context.Context, idUser int, u models.User) error {
+	return conflict(expectAffected(r.q.ExecContext(ctx,
+		`UPDATE "user" SET username=$1, id_role=$2, tanggal_registrasi=$3 WHERE id_user=$4`,
+		u.Username, u.IDRole, u.TanggalRegistrasi, idUser,
+	)))
+}
+
+func (r *userPostgres) UpdatePassword(ctx context.Context, idUser int, passwordHash string) error {
+	return expectAffected(r.q.ExecContext(ctx, `UPDATE "user" SET password=$1 WHERE id_user=$2`, passwordHash, idUser))
+}
+
+func (r *userPostgres) Delete(ctx context.Context, idUser int) error {
+	return expectAffected(r.q.ExecContext(ctx, `DELETE FROM "user" WHERE id_user = $1`, idUser))
+}
+
+func (r *userPostgres) UsernameExists(ctx context.Context, username string) (bool, error) {
+	var exists bool
+	err := r.q.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM "user" WHERE username = $1)`, username).Scan(&exists)
+	return exists, err
+}
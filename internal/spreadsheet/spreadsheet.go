@@ -0,0 +1,221 @@
+// Package spreadsheet - Membaca file CSV dan XLSX yang diunggah guru/admin menjadi baris-baris string
+package spreadsheet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ErrUnsupported - Ekstensi file bukan .csv atau .xlsx
+var ErrUnsupported = errors.New("format file harus .csv atau .xlsx")
+
+// Row - Satu baris file beserta nomor barisnya di file asli (mulai 1)
+type Row struct {
+	Line  int
+	Cells []string
+}
+
+// Read - Membaca seluruh baris file; format ditentukan dari ekstensi nama file.
+// Untuk XLSX hanya sheet pertama yang dibaca. Baris kosong dibuang dan setiap sel di-trim.
+func Read(r io.Reader, filename string) ([]Row, error) {
+	var rows []Row
+	var err error
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		rows, err = readCSV(r)
+	case ".xlsx":
+		rows, err = readXLSX(r)
+	default:
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca %s: %w", filepath.Base(filename), err)
+	}
+
+	out := rows[:0]
+	for _, row := range rows {
+		empty := true
+		for i := range row.Cells {
+			row.Cells[i] = strings.TrimSpace(row.Cells[i])
+			if row.Cells[i] != "" {
+				empty = false
+			}
+		}
+		if !empty {
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+// readCSV - Menerima pemisah koma atau titik koma (default Excel berlocale Indonesia)
+func readCSV(r io.Reader) ([]Row, error) {
+	br := bufio.NewReader(r)
+	first, err := br.Peek(4096)
+	if err != nil && err != io.EOF && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, err
+	}
+	first = bytes.TrimPrefix(first, []byte("\xef\xbb\xbf"))
+	if line, _, _ := bytes.Cut(first, []byte("\n")); bytes.Count(line, []byte(";")) > bytes.Count(line, []byte(",")) {
+		return parseCSV(br, ';')
+	}
+	return parseCSV(br, ',')
+}
+
+func parseCSV(br *bufio.Reader, comma rune) ([]Row, error) {
+	if bom, _ := br.Peek(3); bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+		br.Discard(3)
+	}
+	cr := csv.NewReader(br)
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+
+	var rows []Row
+	for {
+		cells, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		rows = append(rows, Row{Line: line, Cells: cells})
+	}
+}
+
+func readXLSX(r io.Reader) ([]Row, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, errors.New("workbook tidak punya sheet")
+	}
+	// Nilai mentah supaya NISN tidak tampil sebagai 1.23E+09 dan tanggal tetap berupa serial Excel (lihat ParseDate)
+	cells, err := f.GetRows(sheets[0], excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]Row, len(cells))
+	for i := range cells {
+		rows[i] = Row{Line: i + 1, Cells: cells[i]}
+	}
+	return rows, nil
+}
+
+// Header - Posisi kolom berdasarkan judulnya, tidak membedakan huruf besar/kecil
+type Header map[string]int
+
+// NewHeader - Membuat Header dari baris judul
+func NewHeader(row []string) Header {
+	h := make(Header, len(row))
+	for i, name := range row {
+		key := normalize(name)
+		if _, dup := h[key]; !dup && key != "" {
+			h[key] = i
+		}
+	}
+	return h
+}
+
+// Index - Posisi kolom pertama yang judulnya cocok dengan salah satu nama, -1 jika tidak ada
+func (h Header) Index(names ...string) int {
+	for _, name := range names {
+		if i, ok := h[normalize(name)]; ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// Cell - Isi kolom i, "" jika baris lebih pendek atau i < 0
+func (r Row) Cell(i int) string {
+	if i < 0 || i >= len(r.Cells) {
+		return ""
+	}
+	return r.Cells[i]
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// dateLayouts - Format tanggal yang umum dipakai di spreadsheet sekolah
+var dateLayouts = []string{"2006-01-02", "2/1/2006", "2-1-2006", "2.1.2006"}
+
+// ParseDate - Membaca tanggal dari sel: YYYY-MM-DD, DD/MM/YYYY, DD-MM-YYYY atau serial tanggal Excel
+func ParseDate(s string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	if serial, err := strconv.ParseFloat(s, 64); err == nil && serial >= 1 && serial < 2958466 {
+		return excelize.ExcelDateToTime(serial, false)
+	}
+	return time.Time{}, fmt.Errorf("tanggal %q tidak dikenali, gunakan format YYYY-MM-DD atau DD/MM/YYYY", s)
+}
+
+// WriteCSV - Menulis baris ke CSV dengan BOM UTF-8 supaya Excel membaca huruf non-ASCII dengan benar
+func WriteCSV(w io.Writer, rows [][]string) error {
+	if _, err := io.WriteString(w, "\xef\xbb\xbf"); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// WriteXLSX - Menulis baris ke satu sheet XLSX. Sel bertipe angka tetap disimpan sebagai angka
+// supaya bisa dihitung di Excel; baris pertama dianggap header, dicetak tebal dan dibekukan.
+func WriteXLSX(w io.Writer, sheet string, rows [][]any) error {
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := f.SetSheetName(f.GetSheetName(0), sheet); err != nil {
+		return err
+	}
+
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			return err
+		}
+	}
+
+	if len(rows) > 0 && len(rows[0]) > 0 {
+		bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+		if err != nil {
+			return err
+		}
+		last, err := excelize.CoordinatesToCellName(len(rows[0]), 1)
+		if err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(sheet, "A1", last, bold); err != nil {
+			return err
+		}
+		if err := f.SetPanes(sheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+			return err
+		}
+	}
+	return f.Write(w)
+}